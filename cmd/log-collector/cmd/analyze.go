@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/trilioData/tvk-plugins/internal"
+	logcollector "github.com/trilioData/tvk-plugins/tools/log-collector"
+)
+
+const (
+	analyzeCmdName    = "analyze"
+	outputFormatFlag  = "output-format"
+	analyzeShortUsage = "analyze TVK state of cluster or of an existing bundle and print prioritized findings"
+	analyzeLongUsage  = "analyze runs rules over TVK state to find failed Backups/Restores, Targets not Available, pods " +
+		"in CrashLoopBackOff, Warning events, expired License and storage provisioners without VolumeSnapshotClass. " +
+		"Findings are printed in order of severity with remediation hints. State is fetched from cluster in given " +
+		"namespaces or complete cluster if clustered, or read from bundle directory, zip or tar.gz if given."
+	outputFormatUsage = "format of findings, one of text, json or yaml"
+)
+
+var outputFormat string
+
+func analyzeCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   analyzeCmdName + " [bundle]",
+		Short: analyzeShortUsage,
+		Long:  analyzeLongUsage,
+		Example: `  # analyze TVK state of cluster
+  kubectl tvk-log-collector analyze --clustered
+
+  # analyze an existing bundle
+  kubectl tvk-log-collector analyze triliovault-2021-06-08T10-00-00.zip --output-format json
+`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runAnalyze,
+	}
+
+	cmd.Flags().StringVar(&outputFormat, outputFormatFlag, logcollector.FormatText, outputFormatUsage)
+	return cmd
+}
+
+func runAnalyze(_ *cobra.Command, args []string) error {
+	if outputFormat != logcollector.FormatText && outputFormat != internal.FormatJSON &&
+		outputFormat != internal.FormatYAML {
+		log.Fatalf("Invalid value %s for flag %s, it should be one of %s, %s or %s", outputFormat, outputFormatFlag,
+			logcollector.FormatText, internal.FormatJSON, internal.FormatYAML)
+	}
+
+	var (
+		findings []logcollector.Finding
+		err      error
+	)
+	if len(args) == 1 {
+		findings, err = logcollector.AnalyzeBundle(args[0])
+	} else {
+		if !clustered && len(namespaces) == 0 {
+			log.Fatalf("Either bundle, clustered option or at least one namespace in namespace option should be " +
+				"specified for analysis")
+		}
+		logCollector := logcollector.LogCollector{
+			KubeConfig: kubeConfig,
+			Clustered:  clustered,
+			Namespaces: namespaces,
+			Loglevel:   logLevel,
+		}
+		findings, err = logCollector.AnalyzeCluster()
+	}
+	if err != nil {
+		log.Fatalf("Analysis failed - %s", err.Error())
+	}
+
+	return logcollector.PrintFindings(os.Stdout, findings, outputFormat)
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"os"
+	"regexp"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/trilioData/tvk-plugins/internal"
+	logcollector "github.com/trilioData/tvk-plugins/tools/log-collector"
+)
+
+const (
+	bundleCmdName    = "bundle"
+	diffCmdName      = "diff"
+	timelineCmdName  = "timeline"
+	startTimeFlag    = "start-time"
+	endTimeFlag      = "end-time"
+	sourcesFlag      = "sources"
+	matchFlag        = "match"
+	bundleShortUsage = "work with collected bundles"
+	diffShortUsage   = "show objects added, removed or modified between two bundles"
+	diffLongUsage    = "diff compares objects of two bundles, directories, zip or tar.gz, by resource version and " +
+		"content. Changed fields of modified objects are listed if both bundles have their content, objects " +
+		"recorded as unchanged in a bundle collected with --since-bundle are compared by resource version only."
+	timelineShortUsage = "show events, status conditions, container starts and log lines of a bundle in order of time"
+	timelineLongUsage  = "timeline merges events, status condition transitions of Backup, ClusterBackup, Restore, " +
+		"ClusterRestore and Target resources, pod and container start and termination times and pod log lines having " +
+		"a timestamp of a bundle, directory, zip or tar.gz, into a single view sorted by time."
+	startTimeUsage       = "only show entries at or after a specific date (RFC3339)"
+	endTimeUsage         = "only show entries at or before a specific date (RFC3339)"
+	sourcesUsage         = "sources of entries to show separated by commas, any of event, condition, container or log"
+	matchUsage           = "only show entries whose object or message match a regular expression"
+	timelineOutputFormat = "output format, one of text, json, yaml or html"
+)
+
+var (
+	startTime       string
+	endTime         string
+	timelineSources []string
+	match           string
+)
+
+func bundleCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   bundleCmdName,
+		Short: bundleShortUsage,
+	}
+	cmd.AddCommand(diffCommand(), timelineCommand())
+	return cmd
+}
+
+func diffCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   diffCmdName + " <old-bundle> <new-bundle>",
+		Short: diffShortUsage,
+		Long:  diffLongUsage,
+		Example: `  # show changes between bundles taken before and after reproducing a failure
+  kubectl tvk-log-collector bundle diff triliovault-2021-06-08T10-00-00.zip triliovault-2021-06-08T11-00-00.zip
+`,
+		Args: cobra.ExactArgs(2),
+		RunE: runDiff,
+	}
+
+	cmd.Flags().StringVar(&outputFormat, outputFormatFlag, logcollector.FormatText, outputFormatUsage)
+	return cmd
+}
+
+func runDiff(_ *cobra.Command, args []string) error {
+	if outputFormat != logcollector.FormatText && outputFormat != internal.FormatJSON &&
+		outputFormat != internal.FormatYAML {
+		log.Fatalf("Invalid value %s for flag %s, it should be one of %s, %s or %s", outputFormat, outputFormatFlag,
+			logcollector.FormatText, internal.FormatJSON, internal.FormatYAML)
+	}
+
+	changes, err := logcollector.DiffBundles(args[0], args[1])
+	if err != nil {
+		log.Fatalf("Unable to diff bundles - %s", err.Error())
+	}
+	return logcollector.PrintObjectChanges(os.Stdout, changes, outputFormat)
+}
+
+func timelineCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   timelineCmdName + " <bundle>",
+		Short: timelineShortUsage,
+		Long:  timelineLongUsage,
+		Example: `  # show what happened in the hour of a backup failure
+  kubectl tvk-log-collector bundle timeline bundle.zip --start-time 2021-06-08T10:00:00Z --end-time 2021-06-08T11:00:00Z
+
+  # write warnings and log lines of a backup as HTML page which can be filtered in browser
+  kubectl tvk-log-collector bundle timeline bundle.zip --match sample-backup --output-format html > timeline.html
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: runTimeline,
+	}
+
+	cmd.Flags().StringVar(&startTime, startTimeFlag, "", startTimeUsage)
+	cmd.Flags().StringVar(&endTime, endTimeFlag, "", endTimeUsage)
+	cmd.Flags().StringSliceVar(&timelineSources, sourcesFlag, []string{}, sourcesUsage)
+	cmd.Flags().StringVar(&match, matchFlag, "", matchUsage)
+	cmd.Flags().StringVar(&outputFormat, outputFormatFlag, logcollector.FormatText, timelineOutputFormat)
+	return cmd
+}
+
+func runTimeline(_ *cobra.Command, args []string) error {
+	if outputFormat != logcollector.FormatText && outputFormat != internal.FormatJSON &&
+		outputFormat != internal.FormatYAML && outputFormat != logcollector.FormatHTML {
+		log.Fatalf("Invalid value %s for flag %s, it should be one of %s, %s, %s or %s", outputFormat, outputFormatFlag,
+			logcollector.FormatText, internal.FormatJSON, internal.FormatYAML, logcollector.FormatHTML)
+	}
+
+	filter := &logcollector.TimelineFilter{Start: parseTimeFlag(startTimeFlag, startTime),
+		End: parseTimeFlag(endTimeFlag, endTime)}
+	if !filter.Start.IsZero() && !filter.End.IsZero() && filter.End.Before(filter.Start) {
+		log.Fatalf("Value of flag %s should not be before value of flag %s", endTimeFlag, startTimeFlag)
+	}
+	for _, source := range timelineSources {
+		valid := false
+		for _, timelineSource := range logcollector.TimelineSources {
+			valid = valid || source == string(timelineSource)
+		}
+		if !valid {
+			log.Fatalf("Invalid value %s for flag %s, it should be any of %v", source, sourcesFlag,
+				logcollector.TimelineSources)
+		}
+		filter.Sources = append(filter.Sources, logcollector.TimelineSource(source))
+	}
+	if match != "" {
+		expr, err := regexp.Compile(match)
+		if err != nil {
+			log.Fatalf("Unable to parse %s value %s : %s", matchFlag, match, err.Error())
+		}
+		filter.Match = expr
+	}
+
+	entries, err := logcollector.BuildTimeline(args[0], filter)
+	if err != nil {
+		log.Fatalf("Unable to build timeline - %s", err.Error())
+	}
+	return logcollector.PrintTimeline(os.Stdout, entries, outputFormat)
+}
+
+// parseTimeFlag parses RFC3339 'value' of 'flag', zero time is returned for empty value
+func parseTimeFlag(flag, value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Fatalf("Unable to parse %s value %s, it should be in RFC3339 format : %s", flag, value, err.Error())
+	}
+	return parsed
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	logcollector "github.com/trilioData/tvk-plugins/tools/log-collector"
+)
+
+const (
+	deployCmdName    = "deploy"
+	nameFlag         = "name"
+	jobNamespaceFlag = "job-namespace"
+	imageFlag        = "image"
+	scheduleFlag     = "schedule"
+	storageClassFlag = "storage-class"
+	storageSizeFlag  = "storage-size"
+	s3SecretFlag     = "s3-secret"
+	printFlag        = "print"
+
+	jobNamespaceDefault = "default"
+
+	deployShortUsage = "run log-collector in cluster as a Job or CronJob"
+	deployLongUsage  = "deploy applies a ServiceAccount bound to a read only ClusterRole and a Job, or a CronJob if " +
+		"schedule is given, running log-collector in cluster with its service account. Arguments after -- are " +
+		"passed to log-collector, --clustered is used if none are given. ClusterRole grants only resources " +
+		"log-collector would read with these arguments, discovered from cluster like --dry-run. Bundles are stored " +
+		"on a PVC and copied with fetch, or uploaded to an S3 compatible object store with --s3-secret and " +
+		"--output s3://bucket/key."
+	nameUsage         = "name of Job or CronJob and objects created for it"
+	jobNamespaceUsage = "namespace Job or CronJob runs in"
+	imageUsage        = "image of log-collector run by Job"
+	scheduleUsage     = "cron schedule of CronJob, a Job running once is created if not given"
+	storageClassUsage = "storage class of PVC storing bundles, default storage class is used if not given"
+	storageSizeUsage  = "size of PVC storing bundles"
+	s3SecretUsage     = "secret with " + logcollector.AccessKeyIDEnv + " and " + logcollector.SecretAccessKeyEnv +
+		" keys used to upload bundles instead of storing them on PVC"
+	printUsage = "print objects as YAML instead of applying them, cluster is still read to plan ClusterRole rules"
+)
+
+var (
+	deploymentName string
+	jobNamespace   string
+	image          string
+	schedule       string
+	storageClass   string
+	storageSize    string
+	s3Secret       string
+	printObjects   bool
+)
+
+func deployCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   deployCmdName + " [-- log-collector args]",
+		Short: deployShortUsage,
+		Long:  deployLongUsage,
+		Example: `  # collect complete cluster once in cluster, bundle is stored on PVC
+  kubectl tvk-log-collector deploy --image <image> -- --clustered --continue-on-error
+
+  # collect namespaces every night and upload bundles to MinIO
+  kubectl tvk-log-collector deploy --image <image> --schedule "0 2 * * *" --s3-secret minio-creds -- \
+    --namespaces tvk --output s3://support/nightly/ --s3-endpoint http://minio.minio:9000 --s3-path-style
+`,
+		RunE: runDeploy,
+	}
+
+	cmd.Flags().StringVar(&deploymentName, nameFlag, logcollector.DefaultDeploymentName, nameUsage)
+	cmd.Flags().StringVar(&jobNamespace, jobNamespaceFlag, jobNamespaceDefault, jobNamespaceUsage)
+	cmd.Flags().StringVar(&image, imageFlag, "", imageUsage)
+	cmd.Flags().StringVar(&schedule, scheduleFlag, "", scheduleUsage)
+	cmd.Flags().StringVar(&storageClass, storageClassFlag, "", storageClassUsage)
+	cmd.Flags().StringVar(&storageSize, storageSizeFlag, logcollector.DefaultStorageSize, storageSizeUsage)
+	cmd.Flags().StringVar(&s3Secret, s3SecretFlag, "", s3SecretUsage)
+	cmd.Flags().BoolVar(&printObjects, printFlag, false, printUsage)
+	return cmd
+}
+
+func runDeploy(_ *cobra.Command, args []string) error {
+	if image == "" {
+		log.Fatalf("Flag %s should be specified to run log-collector in cluster", imageFlag)
+	}
+	if len(args) == 0 {
+		args = []string{"--" + clusteredFlag}
+	}
+
+	rules, err := jobCollector(args).ClusterRoleRules()
+	if err != nil {
+		log.Fatalf("Unable to plan ClusterRole rules - %s", err.Error())
+	}
+
+	deployment := logcollector.InClusterDeployment{
+		Name:         deploymentName,
+		Namespace:    jobNamespace,
+		Image:        image,
+		Args:         args,
+		Rules:        rules,
+		Schedule:     schedule,
+		StorageClass: storageClass,
+		StorageSize:  storageSize,
+		S3Secret:     s3Secret,
+	}
+
+	if printObjects {
+		objects, oErr := deployment.Objects(true)
+		if oErr != nil {
+			log.Fatalf("Unable to generate objects - %s", oErr.Error())
+		}
+		for _, obj := range objects {
+			buf, mErr := yaml.Marshal(obj)
+			if mErr != nil {
+				return mErr
+			}
+			fmt.Fprintf(os.Stdout, "---\n%s", buf)
+		}
+		return nil
+	}
+
+	if err = deployment.Apply(kubeConfig); err != nil {
+		log.Fatalf("Unable to deploy log-collector - %s", err.Error())
+	}
+	log.Infof("log-collector deployed as %s in namespace %s", deploymentName, jobNamespace)
+	return nil
+}
+
+// jobCollector returns log-collector configured by flags of 'args' of Job which select resources it reads, other
+// flags are ignored. Config file is loaded only if it is also present locally.
+func jobCollector(args []string) *logcollector.LogCollector {
+	var (
+		jobNamespaces     []string
+		jobClustered      bool
+		jobConfigFile     string
+		jobInclude        []string
+		jobExcludeKinds   []string
+		jobLabelSelectors string
+		jobSkipLogs       bool
+		jobBackup         string
+		jobRestore        string
+	)
+	jobCmd := &cobra.Command{FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true}}
+	jobCmd.Flags().StringSliceVarP(&jobNamespaces, namespacesFlag, namespacesShort, nil, namespacesUsage)
+	jobCmd.Flags().BoolVarP(&jobClustered, clusteredFlag, clusteredShort, clusteredDefault, clusteredUsage)
+	jobCmd.Flags().StringVar(&jobConfigFile, configFlag, "", configUsage)
+	jobCmd.Flags().StringSliceVar(&jobInclude, includeFlag, []string{}, includeUsage)
+	jobCmd.Flags().StringSliceVar(&jobExcludeKinds, excludeKindsFlag, []string{}, excludeKindsUsage)
+	jobCmd.Flags().StringVar(&jobLabelSelectors, labelSelectorFlag, "", labelSelectorUsage)
+	jobCmd.Flags().BoolVar(&jobSkipLogs, skipLogsFlag, false, skipLogsUsage)
+	jobCmd.Flags().StringVar(&jobBackup, backupFlag, "", backupUsage)
+	jobCmd.Flags().StringVar(&jobRestore, restoreFlag, "", restoreUsage)
+	if err := jobCmd.ParseFlags(args); err != nil {
+		log.Fatalf("Unable to parse log-collector arguments - %s", err.Error())
+	}
+
+	logCollector := &logcollector.LogCollector{
+		KubeConfig:  kubeConfig,
+		Clustered:   jobClustered,
+		Namespaces:  jobNamespaces,
+		Parallelism: parallelismDefault,
+		Backup:      jobBackup,
+		Restore:     jobRestore,
+	}
+	if jobConfigFile != "" {
+		config, err := logcollector.LoadConfig(jobConfigFile)
+		if err != nil {
+			log.Warnf("Unable to load config %s locally, ClusterRole is planned without it : %s", jobConfigFile,
+				err.Error())
+		} else {
+			logCollector.Config = *config
+		}
+	}
+	mergeResourcesFlags(&logCollector.Config.Resources, jobInclude, jobExcludeKinds, jobLabelSelectors, jobSkipLogs)
+	return logCollector
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	logcollector "github.com/trilioData/tvk-plugins/tools/log-collector"
+)
+
+const (
+	fetchCmdName    = "fetch"
+	serveDirFlag    = "dir"
+	servePortFlag   = "port"
+	outputDirFlag   = "output-dir"
+	outputDirShort  = "d"
+	fetchShortUsage = "copy bundle of log-collector run in cluster to local machine"
+	fetchLongUsage  = "fetch copies a bundle stored on PVC by log-collector deployed in cluster. A pod serving " +
+		"bundles of PVC is started and bundle is copied through API server, the latest bundle is copied if none " +
+		"is given. Job should have completed."
+	outputDirUsage = "directory bundle is copied to"
+	serveDirUsage  = "directory of bundles to serve"
+	servePortUsage = "port to serve bundles on"
+)
+
+var (
+	outputDir string
+	serveDir  string
+	servePort int
+)
+
+func fetchCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   fetchCmdName + " [bundle]",
+		Short: fetchShortUsage,
+		Long:  fetchLongUsage,
+		Example: `  # copy latest bundle to current directory
+  kubectl tvk-log-collector fetch
+
+  # copy a bundle of CronJob to /tmp
+  kubectl tvk-log-collector fetch triliovault-2021-06-08T02-00-00.zip --name nightly --output-dir /tmp
+`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runFetch,
+	}
+
+	cmd.Flags().StringVar(&deploymentName, nameFlag, logcollector.DefaultDeploymentName, nameUsage)
+	cmd.Flags().StringVar(&jobNamespace, jobNamespaceFlag, jobNamespaceDefault, jobNamespaceUsage)
+	cmd.Flags().StringVarP(&outputDir, outputDirFlag, outputDirShort, ".", outputDirUsage)
+	return cmd
+}
+
+func runFetch(_ *cobra.Command, args []string) error {
+	fetcher := logcollector.BundleFetcher{
+		Name:       deploymentName,
+		Namespace:  jobNamespace,
+		KubeConfig: kubeConfig,
+		OutputDir:  outputDir,
+	}
+	if len(args) == 1 {
+		fetcher.Bundle = args[0]
+	}
+
+	bundle, err := fetcher.Fetch()
+	if err != nil {
+		log.Fatalf("Unable to fetch bundle - %s", err.Error())
+	}
+	log.Infof("Fetched bundle %s", bundle)
+	return nil
+}
+
+// serveCommand serves bundles of PVC in pod started by fetch
+func serveCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:    logcollector.ServeCmdName,
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(*cobra.Command, []string) error {
+			return logcollector.ServeBundles(serveDir, servePort)
+		},
+	}
+
+	cmd.Flags().StringVar(&serveDir, serveDirFlag, logcollector.BundlesMountPath, serveDirUsage)
+	cmd.Flags().IntVar(&servePort, servePortFlag, logcollector.BundleServerPort, servePortUsage)
+	return cmd
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -12,12 +14,35 @@ import (
 )
 
 const (
-	binaryName     = "log-collector"
-	clusteredFlag  = "clustered"
-	namespacesFlag = "namespaces"
-	kubeConfigFlag = "kubeconfig"
-	keepSourceFlag = "keep-source-folder"
-	logLevelFlag   = "log-level"
+	binaryName        = "log-collector"
+	clusteredFlag     = "clustered"
+	namespacesFlag    = "namespaces"
+	kubeConfigFlag    = "kubeconfig"
+	keepSourceFlag    = "keep-source-folder"
+	logLevelFlag      = "log-level"
+	parallelismFlag   = "parallelism"
+	sinceFlag         = "since"
+	sinceTimeFlag     = "since-time"
+	tailFlag          = "tail"
+	limitBytesFlag    = "limit-bytes"
+	configFlag        = "config"
+	includeFlag       = "include"
+	excludeKindsFlag  = "exclude-kinds"
+	labelSelectorFlag = "label-selector"
+	skipLogsFlag      = "skip-logs"
+	backupFlag        = "backup"
+	restoreFlag       = "restore"
+	formatFlag        = "format"
+	outputFlag        = "output"
+	continueOnErrFlag = "continue-on-error"
+	strictFlag        = "strict"
+	s3EndpointFlag    = "s3-endpoint"
+	s3RegionFlag      = "s3-region"
+	s3PathStyleFlag   = "s3-path-style"
+	dryRunFlag        = "dry-run"
+	sinceBundleFlag   = "since-bundle"
+	contextFlag       = "context"
+	allContextsFlag   = "all-contexts"
 
 	shortUsage = "log-collector collects the information of resources such as yaml configuration and logs from k8s cluster."
 	longUsage  = "log-collector let you define what you need to log and how to log it by collecting the the logs " +
@@ -39,6 +64,50 @@ const (
 		", error, fatal."
 	loglevelDefault = "INFO"
 	loglevelShort   = "l"
+
+	parallelismUsage   = "maximum number of resources and pod logs to be collected in parallel, shared by all clusters"
+	parallelismDefault = 5
+
+	sinceUsage = "only collect pod logs newer than a relative duration like 5s, 2m, or 3h. Defaults to all logs. " +
+		"Only one of since-time / since may be used"
+	sinceTimeUsage = "only collect pod logs after a specific date (RFC3339). Defaults to all logs. " +
+		"Only one of since-time / since may be used"
+	tailUsage         = "lines of recent pod log of each container to collect. Defaults to -1, collecting all log lines"
+	tailDefault       = -1
+	limitBytesUsage   = "maximum bytes of pod log of each container to collect. Defaults to no limit"
+	limitBytesDefault = 0
+
+	configUsage = "path of log-collector config file containing additional redaction rules and resources to " +
+		"include or exclude"
+	includeUsage = "resources to be collected completely in addition to TVK resources, separated by commas in " +
+		"[group/[version/]]kind format e.g. apps/v1/Deployment,/v1/ConfigMap"
+	excludeKindsUsage  = "kinds of resources not to be collected, separated by commas e.g. ConfigMap,Event"
+	labelSelectorUsage = "label selectors to collect objects in addition to objects having TVK label, each " +
+		"selector separated by semicolon e.g. app=mysql;tier in (db,cache)"
+	skipLogsUsage = "skip collecting pod logs"
+	backupUsage   = "namespace/name of Backup whose application objects, events and pod logs are collected " +
+		"along with TVK resources"
+	restoreUsage = "namespace/name of Restore whose application objects, events and pod logs are collected " +
+		"along with TVK resources"
+	formatUsage = "format of output archive, one of zip or tar.gz"
+	outputShort = "o"
+	outputUsage = "path of output archive, use - to write archive to stdout or s3://bucket/key to upload archive to " +
+		"an S3 compatible object store. Defaults to triliovault-<date-time>.<format>"
+	continueOnErrUsage = "continue collecting other resources when a resource can't be collected, failures are " +
+		"recorded in errors.yaml of bundle. Fails only if nothing could be collected"
+	strictUsage     = "fail if any failure is recorded during collection"
+	s3EndpointUsage = "endpoint URL of S3 compatible object store archive is uploaded to when output is an s3 URL, " +
+		"e.g. http://minio.minio:9000. Defaults to AWS S3 endpoint of region. Credentials are read from " +
+		logcollector.AccessKeyIDEnv + ", " + logcollector.SecretAccessKeyEnv + " and " + logcollector.SessionTokenEnv
+	s3RegionUsage    = "region of S3 bucket archive is uploaded to"
+	s3PathStyleUsage = "address S3 bucket in path of URL instead of host name, required by most S3 compatible stores"
+	sinceBundleUsage = "previous bundle directory, zip or tar.gz, only objects changed since it, new events and logs " +
+		"after its start are collected"
+	dryRunUsage = "check access to resources which would be collected without collecting them, prints permission " +
+		"matrix and minimal ClusterRole needed for collection"
+	contextUsage = "kubeconfig contexts to collect from separated by commas, clusters of multiple contexts are " +
+		"collected concurrently into their own directories under clusters of bundle. Defaults to current context"
+	allContextsUsage = "collect from clusters of all contexts of kubeconfig"
 )
 
 var (
@@ -48,11 +117,35 @@ var (
 	kubeConfig        string
 	keepSource        bool
 	logLevel          string
+	parallelism       int
+	since             time.Duration
+	sinceTime         string
+	tail              int64
+	limitBytes        int64
+	configFile        string
+	include           []string
+	excludeKinds      []string
+	labelSelectors    string
+	skipLogs          bool
+	backup            string
+	restore           string
+	format            string
+	output            string
+	continueOnError   bool
+	strict            bool
+	s3Endpoint        string
+	s3Region          string
+	s3PathStyle       bool
+	dryRun            bool
+	sinceBundle       string
+	kubeContexts      []string
+	allContexts       bool
 	namespacesDefault []string
 )
 
 func init() {
 	rootCmd = logCollectorCommand()
+	rootCmd.AddCommand(analyzeCommand(), deployCommand(), fetchCommand(), serveCommand(), bundleCommand())
 }
 
 func logCollectorCommand() *cobra.Command {
@@ -64,11 +157,34 @@ func logCollectorCommand() *cobra.Command {
 		PersistentPreRunE: preRun,
 	}
 
-	cmd.Flags().StringSliceVarP(&namespaces, namespacesFlag, namespacesShort, namespacesDefault, namespacesUsage)
-	cmd.Flags().BoolVarP(&clustered, clusteredFlag, clusteredShort, clusteredDefault, clusteredUsage)
-	cmd.Flags().StringVarP(&kubeConfig, kubeConfigFlag, kubeconfigShort, internal.KubeConfigDefault, kubeconfigUsage)
+	cmd.PersistentFlags().StringSliceVarP(&namespaces, namespacesFlag, namespacesShort, namespacesDefault, namespacesUsage)
+	cmd.PersistentFlags().BoolVarP(&clustered, clusteredFlag, clusteredShort, clusteredDefault, clusteredUsage)
+	cmd.PersistentFlags().StringVarP(&kubeConfig, kubeConfigFlag, kubeconfigShort, internal.KubeConfigDefault, kubeconfigUsage)
 	cmd.Flags().BoolVarP(&keepSource, keepSourceFlag, keepSourceShort, keepSourceDefault, keepSourceUsage)
-	cmd.Flags().StringVarP(&logLevel, logLevelFlag, loglevelShort, loglevelDefault, logLevelUsage)
+	cmd.PersistentFlags().StringVarP(&logLevel, logLevelFlag, loglevelShort, loglevelDefault, logLevelUsage)
+	cmd.Flags().IntVar(&parallelism, parallelismFlag, parallelismDefault, parallelismUsage)
+	cmd.Flags().DurationVar(&since, sinceFlag, 0, sinceUsage)
+	cmd.Flags().StringVar(&sinceTime, sinceTimeFlag, "", sinceTimeUsage)
+	cmd.Flags().Int64Var(&tail, tailFlag, tailDefault, tailUsage)
+	cmd.Flags().Int64Var(&limitBytes, limitBytesFlag, limitBytesDefault, limitBytesUsage)
+	cmd.Flags().StringVar(&configFile, configFlag, "", configUsage)
+	cmd.Flags().StringSliceVar(&include, includeFlag, []string{}, includeUsage)
+	cmd.Flags().StringSliceVar(&excludeKinds, excludeKindsFlag, []string{}, excludeKindsUsage)
+	cmd.Flags().StringVar(&labelSelectors, labelSelectorFlag, "", labelSelectorUsage)
+	cmd.Flags().BoolVar(&skipLogs, skipLogsFlag, false, skipLogsUsage)
+	cmd.Flags().StringVar(&backup, backupFlag, "", backupUsage)
+	cmd.Flags().StringVar(&restore, restoreFlag, "", restoreUsage)
+	cmd.Flags().StringVar(&format, formatFlag, logcollector.FormatZip, formatUsage)
+	cmd.Flags().StringVarP(&output, outputFlag, outputShort, "", outputUsage)
+	cmd.Flags().BoolVar(&continueOnError, continueOnErrFlag, false, continueOnErrUsage)
+	cmd.Flags().BoolVar(&strict, strictFlag, false, strictUsage)
+	cmd.Flags().StringVar(&s3Endpoint, s3EndpointFlag, "", s3EndpointUsage)
+	cmd.Flags().StringVar(&s3Region, s3RegionFlag, logcollector.DefaultS3Region, s3RegionUsage)
+	cmd.Flags().BoolVar(&s3PathStyle, s3PathStyleFlag, false, s3PathStyleUsage)
+	cmd.Flags().BoolVar(&dryRun, dryRunFlag, false, dryRunUsage)
+	cmd.Flags().StringVar(&sinceBundle, sinceBundleFlag, "", sinceBundleUsage)
+	cmd.Flags().StringSliceVar(&kubeContexts, contextFlag, []string{}, contextUsage)
+	cmd.Flags().BoolVar(&allContexts, allContextsFlag, false, allContextsUsage)
 
 	return cmd
 }
@@ -76,8 +192,9 @@ func logCollectorCommand() *cobra.Command {
 func runLogCollector(*cobra.Command, []string) error {
 	log.Info("---------    LOG COLLECTOR    --------- ")
 
-	if !clustered && len(namespaces) == 0 {
-		log.Fatalf("Either clustered option or at least one namespace in namespace option should be specified for collecting logs")
+	if !clustered && len(namespaces) == 0 && backup == "" && restore == "" {
+		log.Fatalf("Either clustered option, at least one namespace in namespace option or backup/restore option" +
+			" should be specified for collecting logs")
 	}
 
 	t := time.Now()
@@ -86,12 +203,46 @@ func runLogCollector(*cobra.Command, []string) error {
 		t.Hour(), t.Minute(), t.Second())
 
 	logCollector := logcollector.LogCollector{
-		KubeConfig:  kubeConfig,
-		OutputDir:   "triliovault-" + formatted,
-		CleanOutput: !keepSource,
-		Clustered:   clustered,
-		Namespaces:  namespaces,
-		Loglevel:    logLevel,
+		KubeConfig:      kubeConfig,
+		OutputDir:       "triliovault-" + formatted,
+		CleanOutput:     !keepSource,
+		Clustered:       clustered,
+		Namespaces:      namespaces,
+		Loglevel:        logLevel,
+		Parallelism:     parallelism,
+		LogSince:        since,
+		LogTail:         tail,
+		LogLimitBytes:   limitBytes,
+		Backup:          backup,
+		Restore:         restore,
+		Format:          format,
+		Output:          output,
+		ContinueOnError: continueOnError,
+		Strict:          strict,
+		SinceBundle:     sinceBundle,
+	}
+	if sinceTime != "" {
+		ts, err := time.Parse(time.RFC3339, sinceTime)
+		if err != nil {
+			log.Fatalf("Unable to parse %s value %s, it should be in RFC3339 format : %s", sinceTimeFlag, sinceTime,
+				err.Error())
+		}
+		logCollector.LogSinceTime = &ts
+	}
+	if strings.HasPrefix(output, logcollector.S3Scheme) {
+		logCollector.Sink = newS3Sink(logCollector.OutputDir)
+	}
+	if configFile != "" {
+		config, err := logcollector.LoadConfig(configFile)
+		if err != nil {
+			log.Fatalf("Unable to load config : %s", err.Error())
+		}
+		logCollector.Config = *config
+	}
+	mergeResourcesFlags(&logCollector.Config.Resources, include, excludeKinds, labelSelectors, skipLogs)
+	setContexts(&logCollector)
+	if dryRun {
+		return runDryRun(&logCollector)
 	}
 	err := logCollector.CollectLogsAndDump()
 	if err != nil {
@@ -110,6 +261,93 @@ func Execute() {
 	}
 }
 
+// runDryRun prints access of current user to resources which would be collected
+func runDryRun(logCollector *logcollector.LogCollector) error {
+	report, err := logCollector.DryRun()
+	if err != nil {
+		log.Fatalf("Dry run failed - %s", err.Error())
+	}
+	if denied := len(report.Denied()); denied != 0 {
+		log.Warnf("%d permissions needed for collection are denied, bundle would be incomplete", denied)
+	}
+	return logcollector.PrintPermissionReport(os.Stdout, report)
+}
+
+// newS3Sink returns sink uploading archive to s3 URL given as output, archive is named after 'outputDir' if key
+// of URL is a prefix ending with '/'
+func newS3Sink(outputDir string) logcollector.Sink {
+	bucket, key, err := logcollector.ParseS3URL(output)
+	if err != nil {
+		log.Fatalf("Invalid value %s for flag %s : %s", output, outputFlag, err.Error())
+	}
+	if strings.HasSuffix(key, "/") {
+		key += outputDir + "." + format
+	}
+
+	sink, err := logcollector.NewS3Sink(logcollector.S3Config{
+		Endpoint:        s3Endpoint,
+		Region:          s3Region,
+		Bucket:          bucket,
+		Key:             key,
+		PathStyle:       s3PathStyle,
+		AccessKeyID:     os.Getenv(logcollector.AccessKeyIDEnv),
+		SecretAccessKey: os.Getenv(logcollector.SecretAccessKeyEnv),
+		SessionToken:    os.Getenv(logcollector.SessionTokenEnv),
+	}, format)
+	if err != nil {
+		log.Fatalf("Unable to create s3 sink : %s", err.Error())
+	}
+	return sink
+}
+
+// setContexts sets kubeconfig contexts of context flags to collect from, multiple contexts are collected as
+// multi-cluster bundle
+func setContexts(logCollector *logcollector.LogCollector) {
+	contexts := kubeContexts
+	if allContexts {
+		var err error
+		if contexts, err = internal.KubeConfigContexts(kubeConfig); err != nil {
+			log.Fatalf("Unable to list contexts of kubeconfig : %s", err.Error())
+		}
+		if len(contexts) == 0 {
+			log.Fatalf("No contexts found in kubeconfig for flag %s", allContextsFlag)
+		}
+	}
+
+	if len(contexts) == 1 && !allContexts {
+		logCollector.Context = contexts[0]
+		return
+	}
+	if len(contexts) != 0 && (dryRun || sinceBundle != "") {
+		log.Fatalf("Cannot use flag %s or %s with multiple contexts", dryRunFlag, sinceBundleFlag)
+	}
+	logCollector.Contexts = contexts
+}
+
+// mergeResourcesFlags adds values of resources flags to resources config loaded from config file
+func mergeResourcesFlags(resources *logcollector.ResourcesConfig, includeValues, excludeKindsValues []string,
+	labelSelectorsValue string, skipLogsValue bool) {
+	for _, resource := range includeValues {
+		gvk, err := logcollector.ParseGroupVersionKind(strings.TrimSpace(resource))
+		if err != nil {
+			log.Fatalf("Invalid value for flag %s : %s", includeFlag, err.Error())
+		}
+		resources.Include = append(resources.Include, gvk)
+	}
+
+	for _, kind := range excludeKindsValues {
+		resources.ExcludeKinds = append(resources.ExcludeKinds, strings.TrimSpace(kind))
+	}
+
+	for _, selector := range strings.Split(labelSelectorsValue, ";") {
+		if selector = strings.TrimSpace(selector); selector != "" {
+			resources.LabelSelectors = append(resources.LabelSelectors, selector)
+		}
+	}
+
+	resources.SkipLogs = resources.SkipLogs || skipLogsValue
+}
+
 // preRun runs just before the run for any pre checks and setting up vars
 func preRun(*cobra.Command, []string) error {
 	// Setting Log Level
@@ -122,5 +360,43 @@ func preRun(*cobra.Command, []string) error {
 	if len(namespaces) != 0 && clustered {
 		log.Fatalf("Cannot use flag %s and %s scope at the same time", namespacesFlag, clusteredFlag)
 	}
+
+	if backup != "" && restore != "" {
+		log.Fatalf("Cannot use flag %s and %s at the same time", backupFlag, restoreFlag)
+	}
+
+	if format != logcollector.FormatZip && format != logcollector.FormatTarGz {
+		log.Fatalf("Invalid value %s for flag %s, it should be one of %s or %s", format, formatFlag,
+			logcollector.FormatZip, logcollector.FormatTarGz)
+	}
+
+	if parallelism < 1 {
+		log.Fatalf("Invalid value %d for flag %s, it should be greater than 0", parallelism, parallelismFlag)
+	}
+
+	if since != 0 && sinceTime != "" {
+		log.Fatalf("Cannot use flag %s and %s at the same time", sinceFlag, sinceTimeFlag)
+	}
+	if sinceBundle != "" && (since != 0 || sinceTime != "") {
+		log.Fatalf("Cannot use flag %s with %s or %s, logs are collected since start of bundle", sinceBundleFlag,
+			sinceFlag, sinceTimeFlag)
+	}
+	if len(kubeContexts) != 0 && allContexts {
+		log.Fatalf("Cannot use flag %s and %s at the same time", contextFlag, allContextsFlag)
+	}
+	for _, kubeContext := range kubeContexts {
+		if strings.TrimSpace(kubeContext) == "" {
+			log.Fatalf("Invalid value for flag %s, context names should not be empty", contextFlag)
+		}
+	}
+	if since < 0 {
+		log.Fatalf("Invalid value %s for flag %s, it should be a positive duration", since, sinceFlag)
+	}
+	if tail == 0 || tail < -1 {
+		log.Fatalf("Invalid value %d for flag %s, it should be greater than 0 or -1", tail, tailFlag)
+	}
+	if limitBytes < 0 {
+		log.Fatalf("Invalid value %d for flag %s, it should not be negative", limitBytes, limitBytesFlag)
+	}
 	return nil
 }
@@ -33,6 +33,12 @@ for specific backupPlan using available flags and options.`,
   # List of backups: order by [backupTimestamp] in [descending] order
   kubectl tvk-target-browser get backup --backup-plan-uid <uid> --order-by -backupTimestamp --target-name <name> --target-namespace <namespace>
 
+  # List of backups: order by [status] in [ascending] order and then by [backupTimestamp] in [descending] order
+  kubectl tvk-target-browser get backup --order-by status,-backupTimestamp --target-name <name> --target-namespace <namespace>
+
+  # List of backups: order by [size] in [descending] order
+  kubectl tvk-target-browser get backup --order-by -size --target-name <name> --target-namespace <namespace>
+
   # List of backups: filter by [backup-status]
   kubectl tvk-target-browser get backup --backup-plan-uid <uid> --backup-status Available --target-name <name> --target-namespace <namespace>
 
@@ -87,6 +93,9 @@ func init() {
 	backupCmd.Flags().StringVar(&backupUID, BackupUIDFlag, backupUIDDefault, backupUIDUsage)
 	backupCmd.Flags().StringVar(&expirationStartTime, ExpirationStarTimeFlag, "", expirationStartTimeUsage)
 	backupCmd.Flags().StringVar(&expirationEndTime, ExpirationEndTimeFlag, "", expirationEndTimeUsage)
+	backupCmd.ValidArgsFunction = completeBackupUIDs
+	registerFlagCompletion(backupCmd, BackupPlanUIDFlag, completeBackupPlanUIDs)
+	registerFlagCompletion(backupCmd, BackupUIDFlag, completeBackupUIDs)
 	getCmd.AddCommand(backupCmd)
 }
 
@@ -103,7 +112,12 @@ func getBackupList(args []string) error {
 		ExpirationStartTimestamp: expirationStartTime,
 		ExpirationEndTimestamp:   expirationEndTime,
 	}
-	err := targetBrowserAuthConfig.GetBackups(&bpOptions, args)
+	var err error
+	if offline {
+		err = offlineIndex.GetBackups(&bpOptions, args)
+	} else {
+		err = targetBrowserAuthConfig.GetBackups(&bpOptions, args)
+	}
 	if err != nil {
 		return err
 	}
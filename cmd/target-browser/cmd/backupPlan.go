@@ -33,6 +33,9 @@ using available flags and options.`,
 
   # List of backupPlans: order by [backupPlanTimestamp] in [descending] order
   kubectl tvk-target-browser get backupPlan --order-by -backupPlanTimestamp --target-name <name> --target-namespace <namespace>
+
+  # List of backupPlans: order by [type] and then by [successfulBackups] in [descending] order
+  kubectl tvk-target-browser get backupPlan --order-by type,-successfulBackups --target-name <name> --target-namespace <namespace>
   
   # List of backupPlans: filter by [tvkInstanceUID]
   kubectl tvk-target-browser get backupPlan --tvk-instance-uid <uid> --target-name <name> --target-namespace <namespace>
@@ -46,6 +49,7 @@ using available flags and options.`,
   # List of backupPlans: filter by [creationStartTime] and [creationEndTime]
   kubectl tvk-target-browser get backupPlan --creation-start-time <creation-start-time> --creation-end-time <creation-end-time>--target-name <name> --target-namespace <namespace>
 `,
+		ValidArgsFunction: completeBackupPlanUIDs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return getBackupPlanList(args)
 		},
@@ -63,7 +67,12 @@ func getBackupPlanList(args []string) error {
 		CommonListOptions: commonOptions,
 	}
 
-	err := targetBrowserAuthConfig.GetBackupPlans(&bpOptions, args)
+	var err error
+	if offline {
+		err = offlineIndex.GetBackupPlans(&bpOptions, args)
+	} else {
+		err = targetBrowserAuthConfig.GetBackupPlans(&bpOptions, args)
+	}
 	if err != nil {
 		return err
 	}
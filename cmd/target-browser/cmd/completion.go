@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	targetBrowser "github.com/trilioData/tvk-plugins/tools/target-browser"
+)
+
+const (
+	completionCacheDir = "tvk-target-browser-completion"
+	completionCacheTTL = 30 * time.Second
+
+	bashShell       = "bash"
+	zshShell        = "zsh"
+	fishShell       = "fish"
+	powershellShell = "powershell"
+)
+
+// completionCache is stored as a file per completion type & target so that consecutive TAB presses
+// don't re-authenticate and re-query target-browser
+type completionCache struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Completions []string  `json:"completions"`
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd())
+}
+
+// nolint:lll // ignore long line lint errors
+// completionCmd represents the completion command
+func completionCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   fmt.Sprintf("completion [%s|%s|%s|%s]", bashShell, zshShell, fishShell, powershellShell),
+		Short: "Generate shell completion script",
+		Long: `Generates shell completion script for target-browser. Completion of target names, backupPlan UIDs and backup UIDs
+is done by querying cluster and target-browser and results are cached for a short duration to keep completion responsive.`,
+		Example: `  # Load completion in current bash session
+  source <(target-browser completion bash)
+
+  # Load completion for each zsh session
+  target-browser completion zsh > "${fpath[1]}/_target-browser"
+`,
+		ValidArgs: []string{bashShell, zshShell, fishShell, powershellShell},
+		Args:      cobra.ExactValidArgs(1),
+		// flag parsing is disabled so that persistent required flags are not validated for completion command
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch args[0] {
+			case bashShell:
+				return rootCmd.GenBashCompletion(os.Stdout)
+			case zshShell:
+				return rootCmd.GenZshCompletion(os.Stdout)
+			case fishShell:
+				return rootCmd.GenFishCompletion(os.Stdout, true)
+			default:
+				return rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+			}
+		},
+	}
+
+	return cmd
+}
+
+// registerFlagCompletion registers dynamic completion function 'fn' for 'flag' of 'cmd'
+func registerFlagCompletion(cmd *cobra.Command, flag string,
+	fn func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective)) {
+	if err := cmd.RegisterFlagCompletionFunc(flag, fn); err != nil {
+		log.Fatalf("failed to register completion for flag %s - %s", flag, err.Error())
+	}
+}
+
+// completeTargetNames completes names of browsing enabled targets in target-namespace
+func completeTargetNames(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return cachedCompletions(toComplete, []string{TargetNameFlag, targetBrowserConfig.TargetNamespace},
+		func() ([]string, error) {
+			return targetBrowserConfig.GetBrowsableTargetNames(context.Background())
+		})
+}
+
+// completeBackupPlanUIDs completes backupPlan UIDs of target with backupPlan names as description
+func completeBackupPlanUIDs(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if targetBrowserConfig.TargetName == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cachedCompletions(toComplete, []string{BackupPlanUIDFlag, targetBrowserConfig.TargetNamespace,
+		targetBrowserConfig.TargetName}, func() ([]string, error) {
+		auth, err := targetBrowserConfig.Authenticate(context.Background())
+		if err != nil {
+			return nil, err
+		}
+
+		backupPlans, err := auth.ListAllBackupPlans(&targetBrowser.BackupPlanListOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		var completions []string
+		for i := range backupPlans {
+			completions = append(completions, fmt.Sprintf("%s\t%s", backupPlans[i].UID, backupPlans[i].Name))
+		}
+		return completions, nil
+	})
+}
+
+// completeBackupUIDs completes backup UIDs of target with backup names as description. Backups are filtered
+// on backupPlan UID if provided.
+func completeBackupUIDs(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if targetBrowserConfig.TargetName == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cachedCompletions(toComplete, []string{BackupUIDFlag, targetBrowserConfig.TargetNamespace,
+		targetBrowserConfig.TargetName, backupPlanUID}, func() ([]string, error) {
+		auth, err := targetBrowserConfig.Authenticate(context.Background())
+		if err != nil {
+			return nil, err
+		}
+
+		backups, err := auth.ListAllBackups(&targetBrowser.BackupListOptions{BackupPlanUID: backupPlanUID})
+		if err != nil {
+			return nil, err
+		}
+
+		var completions []string
+		for i := range backups {
+			completions = append(completions, fmt.Sprintf("%s\t%s", backups[i].UID, backups[i].Name))
+		}
+		return completions, nil
+	})
+}
+
+// cachedCompletions returns completions matching 'toComplete' from cache identified by 'keys' if it is not older
+// than completionCacheTTL, otherwise completions are listed again using 'list' and cached.
+func cachedCompletions(toComplete string, keys []string, list func() ([]string, error)) ([]string, cobra.ShellCompDirective) {
+	cacheFile := filepath.Join(os.TempDir(), completionCacheDir,
+		fmt.Sprintf("%x.json", sha256.Sum256([]byte(strings.Join(append(keys, targetBrowserConfig.KubeConfig), "/")))))
+
+	completions, ok := readCompletionCache(cacheFile)
+	if !ok {
+		var err error
+		completions, err = list()
+		if err != nil {
+			cobra.CompErrorln(err.Error())
+			return nil, cobra.ShellCompDirectiveError
+		}
+		writeCompletionCache(cacheFile, completions)
+	}
+
+	var matched []string
+	for _, completion := range completions {
+		if strings.HasPrefix(completion, toComplete) {
+			matched = append(matched, completion)
+		}
+	}
+	return matched, cobra.ShellCompDirectiveNoFileComp
+}
+
+func readCompletionCache(cacheFile string) ([]string, bool) {
+	data, err := ioutil.ReadFile(cacheFile)
+	if err != nil {
+		return nil, false
+	}
+
+	var cache completionCache
+	if err = json.Unmarshal(data, &cache); err != nil || time.Since(cache.Timestamp) > completionCacheTTL {
+		return nil, false
+	}
+	return cache.Completions, true
+}
+
+// writeCompletionCache writes completions to cache file, failures are ignored as cache is only an optimization
+func writeCompletionCache(cacheFile string, completions []string) {
+	data, err := json.Marshal(completionCache{Timestamp: time.Now(), Completions: completions})
+	if err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(cacheFile), 0700); err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return
+	}
+	if err = ioutil.WriteFile(cacheFile, data, 0600); err != nil {
+		cobra.CompDebugln(err.Error(), false)
+	}
+}
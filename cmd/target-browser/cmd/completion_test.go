@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestReadCompletionCache(t *testing.T) {
+	dir := t.TempDir()
+	writeCache := func(name string, cache interface{}) string {
+		data, err := json.Marshal(cache)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cacheFile := filepath.Join(dir, name)
+		if err = ioutil.WriteFile(cacheFile, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		return cacheFile
+	}
+	corrupted := filepath.Join(dir, "corrupted.json")
+	if err := ioutil.WriteFile(corrupted, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		cacheFile   string
+		completions []string
+		ok          bool
+	}{
+		{
+			name: "fresh cache",
+			cacheFile: writeCache("fresh.json", completionCache{Timestamp: time.Now(),
+				Completions: []string{"uid-1\tbackup-1"}}),
+			completions: []string{"uid-1\tbackup-1"},
+			ok:          true,
+		},
+		{
+			name: "expired cache",
+			cacheFile: writeCache("expired.json", completionCache{Timestamp: time.Now().Add(-2 * completionCacheTTL),
+				Completions: []string{"uid-1\tbackup-1"}}),
+		},
+		{name: "corrupted cache", cacheFile: corrupted},
+		{name: "missing cache", cacheFile: filepath.Join(dir, "missing.json")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			completions, ok := readCompletionCache(tt.cacheFile)
+			if ok != tt.ok || !reflect.DeepEqual(completions, tt.completions) {
+				t.Errorf("readCompletionCache() = %v, %t, want %v, %t", completions, ok, tt.completions, tt.ok)
+			}
+		})
+	}
+}
+
+func TestWriteCompletionCache(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), completionCacheDir, "cache.json")
+	completions := []string{"uid-1\tbackup-1", "uid-2\tbackup-2"}
+
+	writeCompletionCache(cacheFile, completions)
+	cached, ok := readCompletionCache(cacheFile)
+	if !ok || !reflect.DeepEqual(cached, completions) {
+		t.Errorf("readCompletionCache() = %v, %t, want %v, true", cached, ok, completions)
+	}
+}
+
+func TestCachedCompletions(t *testing.T) {
+	// cache is written under os.TempDir, which is pointed to a test directory
+	tmpDir, set := os.LookupEnv("TMPDIR")
+	if err := os.Setenv("TMPDIR", t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if set {
+			_ = os.Setenv("TMPDIR", tmpDir)
+		} else {
+			_ = os.Unsetenv("TMPDIR")
+		}
+	}()
+
+	keys := []string{t.Name()}
+
+	listed := 0
+	list := func() ([]string, error) {
+		listed++
+		return []string{"uid-1\tbackup-1", "uid-2\tbackup-2", "other\tbackup-3"}, nil
+	}
+
+	tests := []struct {
+		name        string
+		toComplete  string
+		completions []string
+	}{
+		{name: "all completions", toComplete: "", completions: []string{"uid-1\tbackup-1", "uid-2\tbackup-2",
+			"other\tbackup-3"}},
+		{name: "matching prefix", toComplete: "uid-", completions: []string{"uid-1\tbackup-1", "uid-2\tbackup-2"}},
+		{name: "no match", toComplete: "missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			completions, directive := cachedCompletions(tt.toComplete, keys, list)
+			if directive != cobra.ShellCompDirectiveNoFileComp || !reflect.DeepEqual(completions, tt.completions) {
+				t.Errorf("cachedCompletions() = %v, %d, want %v, %d", completions, directive, tt.completions,
+					cobra.ShellCompDirectiveNoFileComp)
+			}
+		})
+	}
+	if listed != 1 {
+		t.Errorf("completions listed %d times, want 1 as later completions are read from cache", listed)
+	}
+
+	errKeys := append(keys, "error")
+	completions, directive := cachedCompletions("", errKeys, func() ([]string, error) {
+		return nil, errors.New("target not reachable")
+	})
+	if directive != cobra.ShellCompDirectiveError || completions != nil {
+		t.Errorf("cachedCompletions() = %v, %d, want nil, %d", completions, directive, cobra.ShellCompDirectiveError)
+	}
+}
@@ -37,6 +37,8 @@ const (
 	backupCmdPluralName      = BackupCmdName + "s"
 	MetadataCmdName          = "metadata"
 	ResourceMetadataCmdName  = "resource-metadata"
+	SyncCmdName              = "sync"
+	VerifyCmdName            = "verify"
 	TrilioResourcesCmdName   = "trilio-resources"
 
 	pagesFlag    = "pages"
@@ -49,7 +51,8 @@ const (
 
 	OrderByFlag    = "order-by"
 	orderByDefault = "name"
-	orderByUsage   = "Parameter to use for ordering the paginated result set"
+	orderByUsage   = "Parameter to use for ordering the paginated result set. Multiple comma-separated keys and any " +
+		"displayed column (e.g. status,-size) are ordered client-side across all pages. Prefix key with '-' for descending order"
 
 	TvkInstanceUIDFlag  = "tvk-instance-uid"
 	tvkInstanceUIDUsage = "TVK instance id to filter backup/backupPlan"
@@ -66,6 +69,22 @@ const (
 	backupUIDDefault = ""
 	backupUIDUsage   = "backupUID to get all backup related to UID"
 
+	BackupPlanNameFlag  = "backup-plan-name"
+	backupPlanNameUsage = "Name of backupPlan, resolved to backupPlan UID. Can be used instead of [backup-plan-uid]"
+
+	BackupNameFlag  = "backup-name"
+	backupNameUsage = "Name of backup, resolved to backup UID. Can be used instead of [backup-uid]"
+
+	OfflineFlag  = "offline"
+	offlineUsage = "Query local index synced using 'sync' command instead of target-browser. Supported for backup, " +
+		"backupPlan and metadata"
+
+	IndexDBFlag  = "db"
+	indexDBUsage = "Path of local index database. Default is ~/.tvk-target-browser/<target-namespace>-<target-name>.db"
+
+	FullSyncFlag  = "full"
+	fullSyncUsage = "Rebuild index and sync metadata of all backups instead of only new or changed backups"
+
 	OperationScopeFlag  = "operation-scope"
 	operationScopeUsage = "Filter backup/backupPlan for [SingleNamespace, MultiNamespace]. " +
 		"Supported values can be in any case capital, small or mixed."
@@ -116,11 +135,15 @@ var (
 	backupPlanUID                          string
 	backupStatus                           string
 	backupUID                              string
+	backupPlanName                         string
+	backupName                             string
 	orderBy                                string
 	pages, pageSize                        int
 	creationStartTime, creationEndTime     string
 	expirationStartTime, expirationEndTime string
 	operationScope                         string
+	offline, fullSync                      bool
+	indexDBPath                            string
 
 	kinds []string
 )
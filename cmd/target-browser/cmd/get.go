@@ -15,8 +15,19 @@ import (
 var (
 	targetBrowserAuthConfig = &targetbrowser.AuthInfo{}
 	commonOptions           = targetbrowser.CommonListOptions{}
+
+	// offlineIndex is opened instead of authenticating with target-browser when [offline] flag is provided
+	offlineIndex *targetbrowser.Index
+	// resolver resolves backup & backupPlan names either using target-browser or offlineIndex
+	resolver backupResolver
 )
 
+// backupResolver resolves backup & backupPlan names to UIDs
+type backupResolver interface {
+	ResolveBackupPlanUID(name, tvkInstanceUID string) (string, error)
+	ResolveBackup(name, backupPlanUID, tvkInstanceUID string) (*targetbrowser.Backup, error)
+}
+
 // nolint:lll // ignore long line lint errors
 // getCmd represents the get command
 var getCmd = &cobra.Command{
@@ -33,6 +44,9 @@ which retrieves single object or list of objects of that resource.`,
 
   # Metadata of specific backup object
   kubectl tvk-target-browser get metadata --backup-uid <uid> --backup-plan-uid <uid> --target-name <name> --target-namespace <namespace>
+
+  # List of backups from local index synced using 'sync' command
+  kubectl tvk-target-browser get backup --offline --target-name <name> --target-namespace <namespace>
 `,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		var err error
@@ -41,8 +55,16 @@ which retrieves single object or list of objects of that resource.`,
 			return err
 		}
 
-		if targetBrowserAuthConfig, err = targetBrowserConfig.Authenticate(context.Background()); err != nil {
-			return err
+		if offline {
+			if offlineIndex, err = openIndex(false); err != nil {
+				return err
+			}
+			resolver = offlineIndex
+		} else {
+			if targetBrowserAuthConfig, err = targetBrowserConfig.Authenticate(context.Background()); err != nil {
+				return err
+			}
+			resolver = targetBrowserAuthConfig
 		}
 
 		commonOptions = targetbrowser.CommonListOptions{
@@ -57,6 +79,12 @@ which retrieves single object or list of objects of that resource.`,
 		}
 		return nil
 	},
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		if offlineIndex != nil {
+			return offlineIndex.Close()
+		}
+		return nil
+	},
 }
 
 func init() {
@@ -67,6 +95,8 @@ func init() {
 	getCmd.PersistentFlags().StringVar(&tvkInstanceUID, TvkInstanceUIDFlag, "", tvkInstanceUIDUsage)
 	getCmd.PersistentFlags().StringVar(&creationStartTime, CreationStartTimeFlag, "", creationStartTimeUsage)
 	getCmd.PersistentFlags().StringVar(&creationEndTime, CreationEndTimeFlag, "", creationEndTimeUsage)
+	getCmd.PersistentFlags().BoolVar(&offline, OfflineFlag, false, offlineUsage)
+	getCmd.PersistentFlags().StringVar(&indexDBPath, IndexDBFlag, "", indexDBUsage)
 
 	rootCmd.AddCommand(getCmd)
 }
@@ -85,6 +115,10 @@ func validateInput(cmd *cobra.Command) error {
 			InsecureSkipTLSFlag, CertificateAuthorityFlag)
 	}
 
+	if cmd.Flags().Changed(IndexDBFlag) && indexDBPath == "" {
+		return fmt.Errorf("[%s] flag value cannot be empty", IndexDBFlag)
+	}
+
 	if outputFormat != "" && !internal.AllowedOutputFormats.Has(outputFormat) {
 		return fmt.Errorf("[%s] flag invalid value. Usage - %s", OutputFormatFlag, OutputFormatFlagUsage)
 	}
@@ -129,3 +163,22 @@ func validateInput(cmd *cobra.Command) error {
 
 	return nil
 }
+
+// openIndex opens local index from [db] flag path or default path of target. If 'create' is true, index is
+// created if it doesn't exist.
+func openIndex(create bool) (*targetbrowser.Index, error) {
+	indexPath := indexDBPath
+	if indexPath == "" {
+		var err error
+		indexPath, err = targetbrowser.DefaultIndexPath(targetBrowserConfig.TargetNamespace, targetBrowserConfig.TargetName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	idx, err := targetbrowser.OpenIndex(indexPath, create)
+	if err != nil && !create {
+		return nil, fmt.Errorf("%s. Use '%s' command to create local index", err.Error(), SyncCmdName)
+	}
+	return idx, err
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	targetBrowser "github.com/trilioData/tvk-plugins/tools/target-browser"
@@ -19,31 +18,39 @@ func metadataCmd() *cobra.Command {
 		Long:  `Performs GET operation on target-browser's '/metadata' API and gets Backup high level metadata details`,
 		Example: `  # Get metadata details of specific backup
   kubectl tvk-target-browser get metadata --backup-uid <uid> --backup-plan-uid <uid> --target-name <name> --target-namespace <namespace>
+
+  # Get metadata details of specific backup using names
+  kubectl tvk-target-browser get metadata --backup-name <name> --backup-plan-name <name> --target-name <name> --target-namespace <namespace>
 `,
 		RunE: getMetadata,
 	}
 
 	cmd.Flags().StringVar(&backupPlanUID, BackupPlanUIDFlag, backupPlanUIDDefault, backupPlanUIDUsage)
-	err := cmd.MarkFlagRequired(BackupPlanUIDFlag)
-	if err != nil {
-		log.Fatalf("Invalid option or missing required flag %s - %s", BackupPlanUIDFlag, err.Error())
-	}
+	cmd.Flags().StringVar(&backupPlanName, BackupPlanNameFlag, "", backupPlanNameUsage)
 	cmd.Flags().StringVar(&backupUID, BackupUIDFlag, backupUIDDefault, backupUIDUsage)
-	err = cmd.MarkFlagRequired(BackupUIDFlag)
-	if err != nil {
-		log.Fatalf("Invalid option or missing required flag %s - %s", BackupUIDFlag, err.Error())
-	}
+	cmd.Flags().StringVar(&backupName, BackupNameFlag, "", backupNameUsage)
+	registerFlagCompletion(cmd, BackupPlanUIDFlag, completeBackupPlanUIDs)
+	registerFlagCompletion(cmd, BackupUIDFlag, completeBackupUIDs)
 	return cmd
 }
 
 func getMetadata(*cobra.Command, []string) error {
+	if err := resolveBackupAndBackupPlanUIDs(); err != nil {
+		return err
+	}
+
 	mdOptions := targetBrowser.MetadataListOptions{
 		BackupPlanUID: backupPlanUID,
 		BackupUID:     backupUID,
 		OutputFormat:  outputFormat,
 	}
 
-	err := targetBrowserAuthConfig.GetMetadata(&mdOptions)
+	var err error
+	if offline {
+		err = offlineIndex.GetMetadata(&mdOptions)
+	} else {
+		err = targetBrowserAuthConfig.GetMetadata(&mdOptions)
+	}
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"fmt"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
@@ -20,6 +22,9 @@ func resourceMetadataCmd() *cobra.Command {
 		Example: `  # Get resource-metadata details of specific backup
   kubectl tvk-target-browser get resource-metadata --backup-uid <uid> --backup-plan-uid <uid> --name <name> --group <group> 
 	--version <version> --kind <kind>
+
+  # Get resource-metadata details of specific backup using backup name
+  kubectl tvk-target-browser get resource-metadata --backup-name <name> --name <name> --group <group> --version <version> --kind <kind>
 `,
 		RunE: getResourceMetadata,
 	}
@@ -27,19 +32,12 @@ func resourceMetadataCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&group, groupFlag, groupFlagShort, groupDefault, groupUsage)
 
 	cmd.Flags().StringVar(&backupPlanUID, BackupPlanUIDFlag, backupPlanUIDDefault, backupPlanUIDUsage)
-	err := cmd.MarkFlagRequired(BackupPlanUIDFlag)
-	if err != nil {
-		log.Fatalf("Invalid option or missing required flag %s - %s", BackupPlanUIDFlag, err.Error())
-	}
-
+	cmd.Flags().StringVar(&backupPlanName, BackupPlanNameFlag, "", backupPlanNameUsage)
 	cmd.Flags().StringVar(&backupUID, BackupUIDFlag, backupUIDDefault, backupUIDUsage)
-	err = cmd.MarkFlagRequired(BackupUIDFlag)
-	if err != nil {
-		log.Fatalf("Invalid option or missing required flag %s - %s", BackupUIDFlag, err.Error())
-	}
+	cmd.Flags().StringVar(&backupName, BackupNameFlag, "", backupNameUsage)
 
 	cmd.Flags().StringVarP(&version, versionFlag, versionFlagShort, versionDefault, versionUsage)
-	err = cmd.MarkFlagRequired(versionFlag)
+	err := cmd.MarkFlagRequired(versionFlag)
 	if err != nil {
 		log.Fatalf("Invalid option or missing required flag %s - %s", versionFlag, err.Error())
 	}
@@ -56,10 +54,21 @@ func resourceMetadataCmd() *cobra.Command {
 		log.Fatalf("Invalid option or missing required flag %s - %s", nameFlag, err.Error())
 	}
 
+	registerFlagCompletion(cmd, BackupPlanUIDFlag, completeBackupPlanUIDs)
+	registerFlagCompletion(cmd, BackupUIDFlag, completeBackupUIDs)
+
 	return cmd
 }
 
 func getResourceMetadata(*cobra.Command, []string) error {
+	if offline {
+		return fmt.Errorf("[%s] flag is not supported for %s", OfflineFlag, ResourceMetadataCmdName)
+	}
+
+	if err := resolveBackupAndBackupPlanUIDs(); err != nil {
+		return err
+	}
+
 	resourceMdOptions := targetBrowser.ResourceMetadataListOptions{
 		BackupPlanUID: backupPlanUID,
 		BackupUID:     backupUID,
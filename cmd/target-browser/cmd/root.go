@@ -54,4 +54,5 @@ func init() {
 	if err := rootCmd.MarkPersistentFlagRequired(TargetNameFlag); err != nil {
 		log.Fatalf("failed to mark flag %s as required - %s", TargetNameFlag, err.Error())
 	}
+	registerFlagCompletion(rootCmd, TargetNameFlag, completeTargetNames)
 }
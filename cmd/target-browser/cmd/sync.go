@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(syncCmd())
+}
+
+// nolint:lll // ignore long line lint errors
+// syncCmd represents the sync command
+func syncCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   SyncCmdName,
+		Short: "Sync backupPlans, backups and metadata of mounted target into local index",
+		Long: `Pulls backupPlans, backups and metadata of backups from target-browser into local SQLite index which can be queried
+using [offline] flag of get command when cluster or target-browser is not reachable. Subsequent syncs are incremental,
+they only list backupPlans and backups created since last sync, refresh backups whose status may still change and list
+older backupPlans and backups again only when their count on target differs from index, which removes the ones deleted
+or expired from target. Metadata is only fetched for backups which are new or whose status changed since last sync.`,
+		Example: `  # Sync local index of target
+  kubectl tvk-target-browser sync --target-name <name> --target-namespace <namespace>
+
+  # Sync all backups into local index at specific path
+  kubectl tvk-target-browser sync --full --db <path> --target-name <name> --target-namespace <namespace>
+
+  # List of backups from local index
+  kubectl tvk-target-browser get backup --offline --target-name <name> --target-namespace <namespace>
+`,
+		RunE: syncIndex,
+	}
+
+	cmd.Flags().StringVar(&indexDBPath, IndexDBFlag, "", indexDBUsage)
+	cmd.Flags().BoolVar(&fullSync, FullSyncFlag, false, fullSyncUsage)
+	return cmd
+}
+
+func syncIndex(cmd *cobra.Command, _ []string) error {
+	if err := validateInput(cmd); err != nil {
+		return err
+	}
+
+	auth, err := targetBrowserConfig.Authenticate(context.Background())
+	if err != nil {
+		return err
+	}
+
+	idx, err := openIndex(true)
+	if err != nil {
+		return err
+	}
+	defer idx.Close()
+
+	result, err := auth.SyncIndex(idx, fullSync)
+	if err != nil {
+		return err
+	}
+
+	syncType := "full"
+	if result.Incremental {
+		syncType = "incremental"
+	}
+	log.Infof("%s sync completed - synced %d backupPlans, %d backups and metadata of %d backups, removed %d "+
+		"deleted or expired backupPlans and backups", syncType, result.BackupPlans, result.Backups, result.Metadata,
+		result.Removed)
+	return nil
+}
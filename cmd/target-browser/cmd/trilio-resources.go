@@ -18,9 +18,14 @@ func trilioResourcesCmd() *cobra.Command {
 	var cmd = &cobra.Command{
 		Use:   TrilioResourcesCmdName,
 		Short: "Get trilio resources for specific backup",
-		Long:  `Performs GET operation on target-browser's '/backup/{backupUID}/trilio-resources' API and gets trilio resources for backup`,
+		Long: `Performs GET operation on target-browser's '/backup/{backupUID}/trilio-resources' API and gets trilio resources for backup.
+Backups can be given either by UID or by name, names are resolved to UIDs`,
 		Example: `  # Get trilio resources for specific backup
-  kubectl tvk-target-browser get backup trilio-resources <backup-uid> --backup-plan-uid <uid> --kinds ClusterBackupPlan,Backup,Hook --target-name <name> --target-namespace <namespace>`,
+  kubectl tvk-target-browser get backup trilio-resources <backup-uid> --backup-plan-uid <uid> --kinds ClusterBackupPlan,Backup,Hook --target-name <name> --target-namespace <namespace>
+
+  # Get trilio resources for specific backup using names
+  kubectl tvk-target-browser get backup trilio-resources <backup-name> --backup-plan-name <name> --kinds ClusterBackupPlan,Backup,Hook --target-name <name> --target-namespace <namespace>`,
+		ValidArgsFunction: completeBackupUIDs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return getTrilioResources(args)
 		},
@@ -29,17 +34,29 @@ func trilioResourcesCmd() *cobra.Command {
 	cmd.Flags().StringSliceVar(&kinds, kindsFlag, []string{}, kindsUsage)
 
 	cmd.Flags().StringVar(&backupPlanUID, BackupPlanUIDFlag, backupPlanUIDDefault, backupPlanUIDUsage)
+	cmd.Flags().StringVar(&backupPlanName, BackupPlanNameFlag, "", backupPlanNameUsage)
+	registerFlagCompletion(cmd, BackupPlanUIDFlag, completeBackupPlanUIDs)
 
 	return cmd
 }
 
 func getTrilioResources(args []string) error {
+	if offline {
+		return fmt.Errorf("[%s] flag is not supported for %s", OfflineFlag, TrilioResourcesCmdName)
+	}
 
 	if len(args) == 0 {
 		return fmt.Errorf("at-least 1 backupUID is needed")
 	}
 
-	args = removeDuplicates(args)
+	if err := resolveBackupPlanUID(); err != nil {
+		return err
+	}
+
+	args, err := resolveBackupUIDs(removeDuplicates(args))
+	if err != nil {
+		return err
+	}
 
 	trilioResourcesOptions := targetBrowser.TrilioResourcesListOptions{
 		BackupPlanUID:     backupPlanUID,
@@ -48,7 +65,7 @@ func getTrilioResources(args []string) error {
 		CommonListOptions: commonOptions,
 	}
 
-	err := targetBrowserAuthConfig.GetTrilioResources(&trilioResourcesOptions, args)
+	err = targetBrowserAuthConfig.GetTrilioResources(&trilioResourcesOptions, args)
 	if err != nil {
 		return err
 	}
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/araddon/dateparse"
+	"github.com/google/uuid"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -31,3 +33,70 @@ func parseTimestamp(timestamp string) (*time.Time, error) {
 	}
 	return &ts, nil
 }
+
+// resolveBackupAndBackupPlanUIDs validates backup & backupPlan flags and resolves backup & backupPlan names to UIDs.
+// If backup is given by name and backupPlan is not given, backupPlan UID of resolved backup is used.
+func resolveBackupAndBackupPlanUIDs() error {
+	if backupUID != "" && backupName != "" {
+		return fmt.Errorf("[%s] and [%s] flags cannot be provided together", BackupUIDFlag, BackupNameFlag)
+	}
+	if backupUID == "" && backupName == "" {
+		return fmt.Errorf("either [%s] or [%s] flag value is required", BackupUIDFlag, BackupNameFlag)
+	}
+
+	if err := resolveBackupPlanUID(); err != nil {
+		return err
+	}
+	if backupPlanUID == "" && backupName == "" {
+		return fmt.Errorf("either [%s] or [%s] flag value is required", BackupPlanUIDFlag, BackupPlanNameFlag)
+	}
+
+	if backupName != "" {
+		backup, err := resolver.ResolveBackup(backupName, backupPlanUID, tvkInstanceUID)
+		if err != nil {
+			return err
+		}
+		backupUID = backup.UID
+		if backupPlanUID == "" {
+			backupPlanUID = backup.BackupPlanUID
+		}
+	}
+
+	return nil
+}
+
+// resolveBackupPlanUID resolves backupPlan name to UID if backupPlan is given by name
+func resolveBackupPlanUID() error {
+	if backupPlanUID != "" && backupPlanName != "" {
+		return fmt.Errorf("[%s] and [%s] flags cannot be provided together", BackupPlanUIDFlag, BackupPlanNameFlag)
+	}
+
+	if backupPlanName != "" {
+		var err error
+		backupPlanUID, err = resolver.ResolveBackupPlanUID(backupPlanName, tvkInstanceUID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveBackupUIDs returns UIDs for given backup UIDs or names. Args which are not valid UUIDs are considered
+// as backup names and resolved to UIDs.
+func resolveBackupUIDs(args []string) ([]string, error) {
+	var uids []string
+	for _, arg := range args {
+		if _, err := uuid.Parse(arg); err == nil {
+			uids = append(uids, arg)
+			continue
+		}
+
+		backup, err := resolver.ResolveBackup(arg, backupPlanUID, tvkInstanceUID)
+		if err != nil {
+			return nil, err
+		}
+		uids = append(uids, backup.UID)
+	}
+
+	return removeDuplicates(uids), nil
+}
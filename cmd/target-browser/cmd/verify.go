@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	targetBrowser "github.com/trilioData/tvk-plugins/tools/target-browser"
+)
+
+func init() {
+	rootCmd.AddCommand(verifyCmd())
+}
+
+// nolint:lll // ignore long line lint errors
+// verifyCmd represents the verify command
+func verifyCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   VerifyCmdName + " [backup-uid|backup-name]...",
+		Short: "Verify integrity of backups stored on mounted target",
+		Long: `Verifies specific backups or all backups of a backupPlan stored on mounted target. For each backup, checks that metadata and every
+resource-metadata entry is retrievable, backup status is Available, expiration is in future and trilio-resources [Target, Policy, Hook, Secret]
+referenced by backupPlan are available. Prints pass/fail report per backup and fails if any backup fails verification.`,
+		Example: `  # Verify specific backups
+  kubectl tvk-target-browser verify <backup-uid> <backup-name> --target-name <name> --target-namespace <namespace>
+
+  # Verify all backups of backupPlan
+  kubectl tvk-target-browser verify --backup-plan-uid <uid> --target-name <name> --target-namespace <namespace>
+
+  # Verify all backups of backupPlan using name and print report in json format
+  kubectl tvk-target-browser verify --backup-plan-name <name> --target-name <name> --target-namespace <namespace> -o json
+`,
+		ValidArgsFunction: completeBackupUIDs,
+		RunE:              verifyBackups,
+	}
+
+	cmd.Flags().StringVar(&backupPlanUID, BackupPlanUIDFlag, backupPlanUIDDefault, backupPlanUIDUsage)
+	cmd.Flags().StringVar(&backupPlanName, BackupPlanNameFlag, "", backupPlanNameUsage)
+	cmd.Flags().StringVar(&tvkInstanceUID, TvkInstanceUIDFlag, "", tvkInstanceUIDUsage)
+	registerFlagCompletion(cmd, BackupPlanUIDFlag, completeBackupPlanUIDs)
+
+	return cmd
+}
+
+func verifyBackups(cmd *cobra.Command, args []string) error {
+	if err := validateInput(cmd); err != nil {
+		return err
+	}
+
+	auth, err := targetBrowserConfig.Authenticate(context.Background())
+	if err != nil {
+		return err
+	}
+	targetBrowserAuthConfig, resolver = auth, auth
+
+	if err = resolveBackupPlanUID(); err != nil {
+		return err
+	}
+	if len(args) == 0 && backupPlanUID == "" {
+		return fmt.Errorf("either backup UIDs/names or one of [%s], [%s] flag value is required",
+			BackupPlanUIDFlag, BackupPlanNameFlag)
+	}
+
+	backupUIDs, err := resolveBackupUIDs(removeDuplicates(args))
+	if err != nil {
+		return err
+	}
+
+	report, err := auth.VerifyBackups(&targetBrowser.VerifyOptions{
+		BackupPlanUID:  backupPlanUID,
+		BackupUIDs:     backupUIDs,
+		TvkInstanceUID: tvkInstanceUID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = targetBrowser.PrintVerificationReport(report, outputFormat); err != nil {
+		return err
+	}
+	if !report.Passed {
+		return fmt.Errorf("verification failed for %d of %d backups", report.Failed, report.Total)
+	}
+	return nil
+}
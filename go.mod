@@ -21,6 +21,7 @@ require (
 	k8s.io/cli-runtime v0.21.2
 	k8s.io/client-go v0.21.2
 	k8s.io/component-base v0.21.2 // indirect
+	modernc.org/sqlite v1.10.6
 	sigs.k8s.io/controller-runtime v0.8.2
 	sigs.k8s.io/yaml v1.2.0
 )
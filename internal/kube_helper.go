@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 
 	ctrlRuntime "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
@@ -46,13 +47,63 @@ func NewEnv(kubeConfig string, scheme *runtime.Scheme) (*Accessor, error) {
 	return accessor, nil
 }
 
+// NewEnvForContext returns a new Kubernetes environment with accessor for 'kubeContext' of kubeconfig, current
+// context is used if 'kubeContext' is empty
+func NewEnvForContext(kubeConfig, kubeContext string, scheme *runtime.Scheme) (*Accessor, error) {
+	if kubeContext == "" {
+		return NewEnv(kubeConfig, scheme)
+	}
+
+	confPath, err := NewConfigFromCommandline(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	if confPath == "" {
+		return nil, fmt.Errorf("kubeconfig not found for using context %s", kubeContext)
+	}
+
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: confPath},
+		&clientcmd.ConfigOverrides{CurrentContext: kubeContext}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rest config of context %s. %v", kubeContext, err)
+	}
+	return newAccessorForConfig(restConfig, scheme)
+}
+
+// KubeConfigContexts returns sorted names of contexts defined in kubeconfig
+func KubeConfigContexts(kubeConfig string) ([]string, error) {
+	confPath, err := NewConfigFromCommandline(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	if confPath == "" {
+		return nil, errors.New("kubeconfig not found for listing its contexts")
+	}
+
+	kubeConf, err := clientcmd.LoadFromFile(confPath)
+	if err != nil {
+		return nil, err
+	}
+	contexts := make([]string, 0, len(kubeConf.Contexts))
+	for name := range kubeConf.Contexts {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+	return contexts, nil
+}
+
 // NewAccessor returns a new instance of an accessor.
 func NewAccessor(kubeConfig string, scheme *runtime.Scheme) (*Accessor, error) {
 	restConfig, err := LoadKubeConfigOrDie(kubeConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create rest config. %v", err)
 	}
+	return newAccessorForConfig(restConfig, scheme)
+}
 
+// newAccessorForConfig returns a new instance of an accessor for 'restConfig'
+func newAccessorForConfig(restConfig *rest.Config, scheme *runtime.Scheme) (*Accessor, error) {
 	// copy to avoid mutating the passed-in config
 	restConfig = rest.CopyConfig(restConfig)
 	// set the warning handler for this client to ignore warnings
@@ -97,6 +148,11 @@ func NewConfigFromCommandline(kubeConfig string) (string, error) {
 	if kubeConfig != "" {
 		kubeConf = kubeConfig
 		if err := normalizeFile(&kubeConf); err != nil {
+			// default kubeconfig doesn't exist in pods, service account of pod is used instead. Explicitly given
+			// kubeconfig which doesn't exist is an error.
+			if os.IsNotExist(err) && kubeConfig == KubeConfigDefault && IsInCluster() {
+				return "", nil
+			}
 			return "", err
 		}
 		return kubeConf, nil
@@ -118,6 +174,12 @@ func NewConfigFromCommandline(kubeConfig string) (string, error) {
 	return "", nil
 }
 
+// IsInCluster returns true if running in a pod with service account credentials mounted
+func IsInCluster() bool {
+	_, err := rest.InClusterConfig()
+	return err == nil
+}
+
 func normalizeFile(path *string) error {
 	// If the path uses the homedir ~, expand the path.
 	var err error
@@ -1,6 +1,7 @@
 package targetbrowsertest
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -18,6 +19,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -34,6 +36,7 @@ const (
 	successfulBackups         = "successfulBackups"
 	backupTimestamp           = "backupTimestamp"
 	status                    = "status"
+	size                      = "size"
 	cmdBackupPlan             = cmd.BackupPlanCmdName
 	cmdBackup                 = cmd.BackupCmdName
 	cmdMetadata               = cmd.MetadataCmdName
@@ -45,6 +48,7 @@ const (
 	flagBackupUIDFlag         = flagPrefix + cmd.BackupUIDFlag
 	flagBackupStatus          = flagPrefix + cmd.BackupStatusFlag
 	flagBackupPlanUIDFlag     = flagPrefix + cmd.BackupPlanUIDFlag
+	flagBackupName            = flagPrefix + cmd.BackupNameFlag
 	flagPageSize              = flagPrefix + cmd.PageSizeFlag
 	flagTargetNamespace       = flagPrefix + cmd.TargetNamespaceFlag
 	flagTargetName            = flagPrefix + cmd.TargetNameFlag
@@ -58,6 +62,10 @@ const (
 	flagExpirationStartTime   = flagPrefix + cmd.ExpirationStarTimeFlag
 	flagExpirationEndTime     = flagPrefix + cmd.ExpirationEndTimeFlag
 	flagOperationScope        = flagPrefix + cmd.OperationScopeFlag
+	flagOffline               = flagPrefix + cmd.OfflineFlag
+	flagIndexDB               = flagPrefix + cmd.IndexDBFlag
+	cmdSync                   = cmd.SyncCmdName
+	cmdVerify                 = cmd.VerifyCmdName
 	kubeConf                  = ""
 	pageSize                  = 1
 	backupCreationStartDate   = "2021-05-16"
@@ -848,6 +856,41 @@ var _ = Describe("Target Browser Tests", func() {
 				}
 			})
 
+			It(fmt.Sprintf("Should sort on multiple keys '%s,%s%s'", status, hyphen, name), func() {
+				args := []string{cmdGet, cmdBackup, flagOrderBy, status + "," + hyphen + name}
+				backupData := runCmdBackup(args)
+				for index := 0; index < len(backupData)-1; index++ {
+					Expect(backupData[index].Status <= backupData[index+1].Status).Should(BeTrue())
+					if backupData[index].Status == backupData[index+1].Status {
+						Expect(backupData[index].Name >= backupData[index+1].Name).Should(BeTrue())
+					}
+				}
+			})
+
+			It(fmt.Sprintf("Should sort in descending order '%s' as quantity", size), func() {
+				args := []string{cmdGet, cmdBackup, flagOrderBy, hyphen + size}
+				backupData := runCmdBackup(args)
+				for index := 0; index < len(backupData)-1; index++ {
+					if backupData[index].Size == "" || backupData[index+1].Size == "" {
+						continue
+					}
+					current, err := resource.ParseQuantity(backupData[index].Size)
+					Expect(err).To(BeNil())
+					next, err := resource.ParseQuantity(backupData[index+1].Size)
+					Expect(err).To(BeNil())
+					Expect(current.Cmp(next) >= 0).Should(BeTrue())
+				}
+			})
+
+			It(fmt.Sprintf("Should fail if flag %s is given with unknown column", flagOrderBy), func() {
+				args := []string{cmdGet, cmdBackup, flagOrderBy, status + ",invalid-column"}
+				args = append(args, commonArgs...)
+				command := exec.Command(targetBrowserBinaryFilePath, args...)
+				output, err := command.CombinedOutput()
+				Expect(err).Should(HaveOccurred())
+				Expect(string(output)).Should(ContainSubstring("unknown ordering key \"invalid-column\""))
+			})
+
 			It(fmt.Sprintf("Should sort in descending order '%s'", status), func() {
 				isLast = true
 				args := []string{cmdGet, cmdBackup, flagBackupPlanUIDFlag, backupPlanUIDs[0], flagOrderBy, hyphen + status}
@@ -1396,8 +1439,8 @@ var _ = Describe("Target Browser Tests", func() {
 				command := exec.Command(targetBrowserBinaryFilePath, args...)
 				output, err := command.CombinedOutput()
 				Expect(err).Should(HaveOccurred())
-				Expect(string(output)).Should(ContainSubstring(fmt.Sprintf("required flag(s) \"%s\", \"%s\" not set",
-					cmd.BackupPlanUIDFlag, cmd.BackupUIDFlag)))
+				Expect(string(output)).Should(ContainSubstring(fmt.Sprintf("either [%s] or [%s] flag value is required",
+					cmd.BackupUIDFlag, cmd.BackupNameFlag)))
 			})
 
 			It(fmt.Sprintf("Should fail if flag %s not given", cmd.BackupUIDFlag), func() {
@@ -1406,8 +1449,8 @@ var _ = Describe("Target Browser Tests", func() {
 				command := exec.Command(targetBrowserBinaryFilePath, args...)
 				output, err := command.CombinedOutput()
 				Expect(err).Should(HaveOccurred())
-				Expect(string(output)).Should(ContainSubstring(fmt.Sprintf("required flag(s) \"%s\" not set",
-					cmd.BackupUIDFlag)))
+				Expect(string(output)).Should(ContainSubstring(fmt.Sprintf("either [%s] or [%s] flag value is required",
+					cmd.BackupUIDFlag, cmd.BackupNameFlag)))
 			})
 
 			It(fmt.Sprintf("Should fail if flag %s not given", cmd.BackupPlanUIDFlag), func() {
@@ -1416,8 +1459,37 @@ var _ = Describe("Target Browser Tests", func() {
 				command := exec.Command(targetBrowserBinaryFilePath, args...)
 				output, err := command.CombinedOutput()
 				Expect(err).Should(HaveOccurred())
-				Expect(string(output)).Should(ContainSubstring(fmt.Sprintf("required flag(s) \"%s\" not set",
-					cmd.BackupPlanUIDFlag)))
+				Expect(string(output)).Should(ContainSubstring(fmt.Sprintf("either [%s] or [%s] flag value is required",
+					cmd.BackupPlanUIDFlag, cmd.BackupPlanNameFlag)))
+			})
+
+			It(fmt.Sprintf("Should fail if flag %s and %s both are given", cmd.BackupUIDFlag, cmd.BackupNameFlag), func() {
+				args := []string{cmdGet, cmdMetadata, flagBackupUIDFlag, backupUID, flagBackupName, "backup-name"}
+				args = append(args, commonArgs...)
+				command := exec.Command(targetBrowserBinaryFilePath, args...)
+				output, err := command.CombinedOutput()
+				Expect(err).Should(HaveOccurred())
+				Expect(string(output)).Should(ContainSubstring(fmt.Sprintf("[%s] and [%s] flags cannot be provided together",
+					cmd.BackupUIDFlag, cmd.BackupNameFlag)))
+			})
+
+			It(fmt.Sprintf("Should get metadata if flag %s is given instead of UIDs", cmd.BackupNameFlag), func() {
+				backupData := runCmdBackup([]string{cmdGet, cmdBackup, backupUID})
+				Expect(len(backupData)).To(Equal(1))
+				args := []string{cmdGet, cmdMetadata, flagBackupName, backupData[0].Name}
+				args = append(args, commonArgs...)
+				command := exec.Command(targetBrowserBinaryFilePath, args...)
+				_, err := command.CombinedOutput()
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+
+			It(fmt.Sprintf("Should fail if flag %s is given with non-existent name", cmd.BackupNameFlag), func() {
+				args := []string{cmdGet, cmdMetadata, flagBackupName, "non-existent-backup"}
+				args = append(args, commonArgs...)
+				command := exec.Command(targetBrowserBinaryFilePath, args...)
+				output, err := command.CombinedOutput()
+				Expect(err).Should(HaveOccurred())
+				Expect(string(output)).Should(ContainSubstring("backup with name \"non-existent-backup\" not found on target"))
 			})
 
 			It(fmt.Sprintf("Should fail if flag %s is given without value", cmd.BackupPlanUIDFlag), func() {
@@ -1572,6 +1644,97 @@ var _ = Describe("Target Browser Tests", func() {
 			})
 		})
 	})
+
+	Context("Sync and verify command test-cases", func() {
+		var (
+			backupPlanUIDs []string
+			indexPath      string
+			once           sync.Once
+			isLast         bool
+		)
+		BeforeEach(func() {
+			once.Do(func() {
+				createTarget(true)
+				_, err := exec.Command(createBackupScript, "1", "2").Output()
+				Expect(err).To(BeNil())
+				time.Sleep(2 * time.Minute) // wait to sync data on target browser
+				backupPlanUIDs = verifyBackupPlansAndBackupsOnNFS(1, 2)
+				indexDir, err := ioutil.TempDir("", "target-browser-index")
+				Expect(err).To(BeNil())
+				indexPath = filepath.Join(indexDir, "index.db")
+			})
+		})
+		AfterEach(func() {
+			if isLast {
+				deleteTarget(false)
+				removeBackupPlanDir(backupPlanUIDs)
+				Expect(os.RemoveAll(filepath.Dir(indexPath))).To(BeNil())
+			}
+		})
+
+		It(fmt.Sprintf("Should fail to query offline if index is not synced using %s command", cmdSync), func() {
+			args := []string{cmdGet, cmdBackup, flagOffline, flagIndexDB, indexPath}
+			args = append(args, commonArgs...)
+			command := exec.Command(targetBrowserBinaryFilePath, args...)
+			output, err := command.CombinedOutput()
+			Expect(err).Should(HaveOccurred())
+			Expect(string(output)).Should(ContainSubstring(fmt.Sprintf("Use '%s' command to create local index", cmdSync)))
+		})
+
+		It("Should list same backups and backupPlans offline after sync", func() {
+			args := []string{cmdSync, flagIndexDB, indexPath}
+			args = append(args, commonArgs...)
+			command := exec.Command(targetBrowserBinaryFilePath, args...)
+			output, err := command.CombinedOutput()
+			Expect(err).ShouldNot(HaveOccurred(), string(output))
+
+			onlineBackups := runCmdBackup([]string{cmdGet, cmdBackup, flagOrderBy, name})
+			offlineBackups := runCmdBackup([]string{cmdGet, cmdBackup, flagOrderBy, name, flagOffline, flagIndexDB, indexPath})
+			Expect(offlineBackups).Should(Equal(onlineBackups))
+
+			onlineBackupPlans := runCmdBackupPlan([]string{cmdGet, cmdBackupPlan, flagOrderBy, name})
+			offlineBackupPlans := runCmdBackupPlan([]string{cmdGet, cmdBackupPlan, flagOrderBy, name, flagOffline,
+				flagIndexDB, indexPath})
+			Expect(offlineBackupPlans).Should(Equal(onlineBackupPlans))
+		})
+
+		It(fmt.Sprintf("Should fail %s if neither backups nor backupPlan is given", cmdVerify), func() {
+			args := append([]string{cmdVerify}, commonArgs...)
+			command := exec.Command(targetBrowserBinaryFilePath, args...)
+			output, err := command.CombinedOutput()
+			Expect(err).Should(HaveOccurred())
+			Expect(string(output)).Should(ContainSubstring(fmt.Sprintf("either backup UIDs/names or one of [%s], [%s] flag value is required",
+				cmd.BackupPlanUIDFlag, cmd.BackupPlanNameFlag)))
+		})
+
+		It(fmt.Sprintf("Should report verification of every backup of backupPlan using %s command", cmdVerify), func() {
+			args := []string{cmdVerify, flagBackupPlanUIDFlag, backupPlanUIDs[0]}
+			args = append(args, commonArgs...)
+			command := exec.Command(targetBrowserBinaryFilePath, args...)
+			output, _ := command.Output()
+			var report targetbrowser.VerificationReport
+			Expect(json.Unmarshal(output, &report)).To(BeNil(), string(output))
+			Expect(report.Total).To(Equal(2))
+			for idx := range report.Backups {
+				Expect(report.Backups[idx].BackupPlanUID).To(Equal(backupPlanUIDs[0]))
+				Expect(report.Backups[idx].Checks).ShouldNot(BeEmpty())
+			}
+		})
+
+		It("Should get metadata of backup offline after sync", func() {
+			isLast = true
+			backupData := runCmdBackup([]string{cmdGet, cmdBackup, flagOffline, flagIndexDB, indexPath})
+			Expect(len(backupData)).To(Equal(2))
+
+			args := []string{cmdGet, cmdMetadata, flagBackupUIDFlag, backupData[0].UID, flagBackupPlanUIDFlag,
+				backupData[0].BackupPlanUID, flagOffline, flagIndexDB, indexPath}
+			args = append(args, commonArgs...)
+			command := exec.Command(targetBrowserBinaryFilePath, args...)
+			output, err := command.CombinedOutput()
+			Expect(err).ShouldNot(HaveOccurred(), string(output))
+			Expect(string(output)).Should(ContainSubstring(backupData[0].BackupPlanUID))
+		})
+	})
 })
 
 func validateBackupPlanKind(backupPlanData []targetbrowser.BackupPlan, backupPlanUIDSet, clusterBackupPlanUIDSet sets.String) {
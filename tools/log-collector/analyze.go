@@ -0,0 +1,506 @@
+package logcollector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+// Severity of an analysis finding
+type Severity string
+
+const (
+	SeverityCritical Severity = "Critical"
+	SeverityHigh     Severity = "High"
+	SeverityMedium   Severity = "Medium"
+	SeverityLow      Severity = "Low"
+
+	FormatText = "text"
+
+	ClusterBackupKind       = "ClusterBackup"
+	ClusterRestoreKind      = "ClusterRestore"
+	StorageClassKind        = "StorageClass"
+	VolumeSnapshotClassKind = "VolumeSnapshotClass"
+
+	statusFailed     = "Failed"
+	statusAvailable  = "Available"
+	crashLoopBackOff = "CrashLoopBackOff"
+	eventTypeWarning = "Warning"
+
+	// maxEventSamples is the number of objects listed for a warning event reason
+	maxEventSamples = 3
+)
+
+var (
+	severityPriority = map[Severity]int{SeverityCritical: 0, SeverityHigh: 1, SeverityMedium: 2, SeverityLow: 3}
+
+	// analyzedKinds are fetched from cluster for analysis, in order of preference of group versions
+	analyzedKinds = []schema.GroupVersionKind{
+		{Group: internal.TriliovaultGroup, Version: internal.V1Version, Kind: internal.BackupKind},
+		{Group: internal.TriliovaultGroup, Version: internal.V1Version, Kind: ClusterBackupKind},
+		{Group: internal.TriliovaultGroup, Version: internal.V1Version, Kind: RestoreKind},
+		{Group: internal.TriliovaultGroup, Version: internal.V1Version, Kind: ClusterRestoreKind},
+		{Group: internal.TriliovaultGroup, Version: internal.V1Version, Kind: internal.TargetKind},
+		{Group: internal.TriliovaultGroup, Version: internal.V1Version, Kind: LicenseKind},
+		{Version: CoreGv, Kind: Pod},
+		{Version: CoreGv, Kind: EventKind},
+		{Group: StorageGroup, Version: "v1", Kind: StorageClassKind},
+		{Group: SnapshotStorageGroup, Version: "v1", Kind: VolumeSnapshotClassKind},
+		{Group: SnapshotStorageGroup, Version: "v1beta1", Kind: VolumeSnapshotClassKind},
+	}
+
+	// eventHints are remediation hints of well known warning event reasons
+	eventHints = map[string]string{
+		"FailedMount":        "Check PVC binding, CSI node plugin pods and secrets/configmaps referred by the pod volumes",
+		"FailedAttachVolume": "Check CSI controller plugin logs and VolumeAttachment objects of the volume",
+		"FailedScheduling":   "Check node resources, taints and PVC topology constraints of the pod",
+		"ProvisioningFailed": "Check storage provisioner logs and StorageClass parameters",
+		"BackOff":            "Check previous container logs of the pod for the cause of restarts",
+		"FailedCreate":       "Check quota, limit ranges and admission webhooks in the namespace",
+	}
+)
+
+// Finding is a problem found by analysis with its remediation hint
+type Finding struct {
+	Severity    Severity `json:"severity"`
+	Rule        string   `json:"rule"`
+	Object      string   `json:"object"`
+	Message     string   `json:"message"`
+	Remediation string   `json:"remediation"`
+}
+
+// analysisObjects are objects to be analyzed grouped by kind
+type analysisObjects map[string][]unstructured.Unstructured
+
+func (o analysisObjects) add(obj *unstructured.Unstructured) {
+	o[obj.GetKind()] = append(o[obj.GetKind()], *obj)
+}
+
+// analysisRule returns findings of a kind of problem
+type analysisRule func(objects analysisObjects) []Finding
+
+var analysisRules = []analysisRule{
+	failedBackupRestoreRule,
+	unavailableTargetRule,
+	crashLoopBackOffRule,
+	warningEventsRule,
+	expiredLicenseRule,
+	missingVolumeSnapshotClassRule,
+}
+
+// AnalyzeCluster fetches TVK state from cluster in namespaces, or complete cluster if clustered, and analyzes it
+func (l *LogCollector) AnalyzeCluster() ([]Finding, error) {
+	if err := l.initializeKubeClients(); err != nil {
+		return nil, err
+	}
+
+	objects := make(analysisObjects)
+	listedKinds := sets.NewString()
+	for _, gvk := range analyzedKinds {
+		if listedKinds.Has(gvk.Kind) || !internal.CheckIfAPIVersionKindAvailable(l.disClient, gvk) {
+			continue
+		}
+		listedKinds.Insert(gvk.Kind)
+
+		items, err := l.listAnalyzedKind(gvk)
+		if err != nil {
+			return nil, err
+		}
+		for idx := range items {
+			items[idx].SetGroupVersionKind(gvk)
+			objects.add(&items[idx])
+		}
+	}
+
+	return analyze(objects), nil
+}
+
+// listAnalyzedKind lists objects of 'gvk' in namespaces of log collector or in all namespaces if clustered
+func (l *LogCollector) listAnalyzedKind(gvk schema.GroupVersionKind) ([]unstructured.Unstructured, error) {
+	namespaces := l.Namespaces
+	if l.Clustered || gvk.Kind == StorageClassKind || gvk.Kind == VolumeSnapshotClassKind ||
+		gvk.Kind == ClusterBackupKind || gvk.Kind == ClusterRestoreKind {
+		namespaces = []string{""}
+	}
+
+	var items []unstructured.Unstructured
+	for _, namespace := range namespaces {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := l.k8sClient.List(context.Background(), list, client.InNamespace(namespace)); err != nil {
+			l.logger().Errorf("Unable to list %s : %s", gvk.Kind, err.Error())
+			return nil, err
+		}
+		items = append(items, list.Items...)
+	}
+	return items, nil
+}
+
+// AnalyzeBundle analyzes objects of an existing bundle directory, zip or tar.gz archive
+func AnalyzeBundle(bundlePath string) ([]Finding, error) {
+	objects := make(analysisObjects)
+	err := readBundle(bundlePath, func(name string, content []byte) error {
+		events, err := parseBundleEvents(name, content)
+		if err != nil {
+			log.Warnf("Skipping %s : %s", name, err.Error())
+			return nil
+		}
+		if events != nil {
+			for _, event := range events {
+				objects.add(event)
+			}
+			return nil
+		}
+
+		obj, err := parseBundleObject(name, content)
+		if err != nil {
+			log.Warnf("Skipping %s : %s", name, err.Error())
+			return nil
+		}
+		if obj != nil {
+			objects.add(obj)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return analyze(objects), nil
+}
+
+// parseBundleObject parses YAML file of bundle at 'name'. Nil is returned for files which are not objects.
+func parseBundleObject(name string, content []byte) (*unstructured.Unstructured, error) {
+	if !strings.HasSuffix(name, ".yaml") {
+		return nil, nil
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(content, &obj.Object); err != nil {
+		return nil, err
+	}
+	if obj.Object == nil {
+		return nil, nil
+	}
+
+	if obj.GetKind() == "" {
+		return nil, nil
+	}
+	return obj, nil
+}
+
+// analyze runs all analysis rules over 'objects' and returns findings sorted by priority
+func analyze(objects analysisObjects) []Finding {
+	findings := []Finding{}
+	for _, rule := range analysisRules {
+		findings = append(findings, rule(objects)...)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Severity != findings[j].Severity {
+			return severityPriority[findings[i].Severity] < severityPriority[findings[j].Severity]
+		}
+		if findings[i].Rule != findings[j].Rule {
+			return findings[i].Rule < findings[j].Rule
+		}
+		return findings[i].Object < findings[j].Object
+	})
+	return findings
+}
+
+// failedBackupRestoreRule finds failed backups and restores along with their status conditions
+func failedBackupRestoreRule(objects analysisObjects) []Finding {
+	var findings []Finding
+	for _, kind := range []string{internal.BackupKind, ClusterBackupKind, RestoreKind, ClusterRestoreKind} {
+		for idx := range objects[kind] {
+			obj := &objects[kind][idx]
+			status, _, _ := unstructured.NestedString(obj.Object, "status", "status")
+			if status != statusFailed {
+				continue
+			}
+
+			message := fmt.Sprintf("%s failed", kind)
+			if conditions := failedConditions(obj); len(conditions) != 0 {
+				message = fmt.Sprintf("%s, conditions: %s", message, strings.Join(conditions, "; "))
+			}
+			findings = append(findings, Finding{
+				Severity: SeverityCritical,
+				Rule:     "Failed" + kind,
+				Object:   objectName(obj),
+				Message:  message,
+				Remediation: fmt.Sprintf("Check status conditions with 'kubectl describe %s' and logs of TVK "+
+					"pods of the failed phase", strings.ToLower(kind)),
+			})
+		}
+	}
+	return findings
+}
+
+// failedConditions returns phase, status and reason of status conditions of TVK object which are not completed
+func failedConditions(obj *unstructured.Unstructured) []string {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "condition")
+	var result []string
+	for _, condition := range conditions {
+		conditionMap, ok := condition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		phase, _, _ := unstructured.NestedString(conditionMap, "phase")
+		status, _, _ := unstructured.NestedString(conditionMap, "status")
+		reason, _, _ := unstructured.NestedString(conditionMap, "reason")
+		if status != statusFailed && !strings.Contains(strings.ToLower(reason), "fail") {
+			continue
+		}
+		result = append(result, strings.TrimSpace(fmt.Sprintf("%s %s: %s", phase, status, reason)))
+	}
+	return result
+}
+
+// unavailableTargetRule finds targets which are not available
+func unavailableTargetRule(objects analysisObjects) []Finding {
+	var findings []Finding
+	for idx := range objects[internal.TargetKind] {
+		obj := &objects[internal.TargetKind][idx]
+		status, _, _ := unstructured.NestedString(obj.Object, "status", "status")
+		if status == statusAvailable {
+			continue
+		}
+		if status == "" {
+			status = "Unknown"
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityCritical,
+			Rule:     "TargetNotAvailable",
+			Object:   objectName(obj),
+			Message:  fmt.Sprintf("Target status is %s", status),
+			Remediation: "Verify Target credentials, bucket or NFS export and network connectivity from cluster, " +
+				"then check logs of target validation pod",
+		})
+	}
+	return findings
+}
+
+// crashLoopBackOffRule finds pods having containers in CrashLoopBackOff
+func crashLoopBackOffRule(objects analysisObjects) []Finding {
+	var findings []Finding
+	for idx := range objects[Pod] {
+		obj := &objects[Pod][idx]
+		var containers []string
+		for _, field := range []string{"initContainerStatuses", "containerStatuses"} {
+			statuses, _, _ := unstructured.NestedSlice(obj.Object, "status", field)
+			for _, status := range statuses {
+				statusMap, ok := status.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				reason, _, _ := unstructured.NestedString(statusMap, "state", "waiting", "reason")
+				if reason != crashLoopBackOff {
+					continue
+				}
+				name, _, _ := unstructured.NestedString(statusMap, "name")
+				restarts := nestedInt64(statusMap, "restartCount")
+				containers = append(containers, fmt.Sprintf("%s (%d restarts)", name, restarts))
+			}
+		}
+		if len(containers) == 0 {
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity:    SeverityHigh,
+			Rule:        crashLoopBackOff,
+			Object:      objectName(obj),
+			Message:     fmt.Sprintf("Containers in %s: %s", crashLoopBackOff, strings.Join(containers, ", ")),
+			Remediation: "Check previous logs of the containers for the cause of crash and their resource limits",
+		})
+	}
+	return findings
+}
+
+// warningEventsRule groups warning events by reason
+func warningEventsRule(objects analysisObjects) []Finding {
+	type reasonEvents struct {
+		count   int64
+		objects sets.String
+	}
+	reasons := make(map[string]*reasonEvents)
+	for idx := range objects[EventKind] {
+		obj := &objects[EventKind][idx]
+		eventType, _, _ := unstructured.NestedString(obj.Object, "type")
+		if eventType != eventTypeWarning {
+			continue
+		}
+		reason, _, _ := unstructured.NestedString(obj.Object, "reason")
+		count := nestedInt64(obj.Object, "count")
+		if count < 1 {
+			count = 1
+		}
+		kind, _, _ := unstructured.NestedString(obj.Object, "involvedObject", "kind")
+		namespace, _, _ := unstructured.NestedString(obj.Object, "involvedObject", "namespace")
+		name, _, _ := unstructured.NestedString(obj.Object, "involvedObject", "name")
+
+		if reasons[reason] == nil {
+			reasons[reason] = &reasonEvents{objects: sets.NewString()}
+		}
+		reasons[reason].count += count
+		reasons[reason].objects.Insert(fmt.Sprintf("%s %s/%s", strings.ToLower(kind), namespace, name))
+	}
+
+	var findings []Finding
+	for reason, events := range reasons {
+		samples := events.objects.List()
+		if len(samples) > maxEventSamples {
+			samples = append(samples[:maxEventSamples], fmt.Sprintf("%d more", events.objects.Len()-maxEventSamples))
+		}
+		remediation, ok := eventHints[reason]
+		if !ok {
+			remediation = "Check messages of the events in Events directory of bundle or 'kubectl get events'"
+		}
+		findings = append(findings, Finding{
+			Severity:    SeverityMedium,
+			Rule:        "WarningEvents",
+			Object:      reason,
+			Message:     fmt.Sprintf("%d warning events for %s", events.count, strings.Join(samples, ", ")),
+			Remediation: remediation,
+		})
+	}
+	return findings
+}
+
+// expiredLicenseRule finds expired or inactive licenses
+func expiredLicenseRule(objects analysisObjects) []Finding {
+	var findings []Finding
+	for idx := range objects[LicenseKind] {
+		obj := &objects[LicenseKind][idx]
+		status, _, _ := unstructured.NestedString(obj.Object, "status", "status")
+		expiration, _, _ := unstructured.NestedString(obj.Object, "status", "expirationTimestamp")
+		if expiration == "" {
+			expiration, _, _ = unstructured.NestedString(obj.Object, "status", "properties", "expirationTimestamp")
+		}
+
+		expiredAt, err := time.Parse(time.RFC3339, expiration)
+		switch {
+		case status == "Expired" || (err == nil && expiredAt.Before(time.Now())):
+			findings = append(findings, Finding{
+				Severity:    SeverityCritical,
+				Rule:        "LicenseExpired",
+				Object:      objectName(obj),
+				Message:     fmt.Sprintf("License expired at %s", valueOrUnknown(expiration)),
+				Remediation: "Renew TVK license and update License object with the new key",
+			})
+		case status != "" && status != "Active":
+			findings = append(findings, Finding{
+				Severity:    SeverityHigh,
+				Rule:        "LicenseNotActive",
+				Object:      objectName(obj),
+				Message:     fmt.Sprintf("License status is %s", status),
+				Remediation: "Check message in License status, license may not cover the cluster or its node count",
+			})
+		}
+	}
+	return findings
+}
+
+// missingVolumeSnapshotClassRule finds storage provisioners without a VolumeSnapshotClass of their driver
+func missingVolumeSnapshotClassRule(objects analysisObjects) []Finding {
+	drivers := sets.NewString()
+	for idx := range objects[VolumeSnapshotClassKind] {
+		driver, _, _ := unstructured.NestedString(objects[VolumeSnapshotClassKind][idx].Object, "driver")
+		drivers.Insert(driver)
+	}
+
+	var findings []Finding
+	reported := sets.NewString()
+	for idx := range objects[StorageClassKind] {
+		obj := &objects[StorageClassKind][idx]
+		provisioner, _, _ := unstructured.NestedString(obj.Object, "provisioner")
+		if provisioner == "" || drivers.Has(provisioner) || reported.Has(provisioner) {
+			continue
+		}
+		reported.Insert(provisioner)
+		findings = append(findings, Finding{
+			Severity: SeverityHigh,
+			Rule:     "MissingVolumeSnapshotClass",
+			Object:   provisioner,
+			Message: fmt.Sprintf("No VolumeSnapshotClass found for provisioner %s of StorageClass %s", provisioner,
+				obj.GetName()),
+			Remediation: "Install a CSI driver with snapshot support for the provisioner and create a " +
+				"VolumeSnapshotClass with its driver",
+		})
+	}
+	return findings
+}
+
+// nestedInt64 returns integer field of object read from cluster or parsed from bundle YAML as float
+func nestedInt64(obj map[string]interface{}, fields ...string) int64 {
+	value, _, _ := unstructured.NestedFieldNoCopy(obj, fields...)
+	switch number := value.(type) {
+	case int64:
+		return number
+	case float64:
+		return int64(number)
+	}
+	return 0
+}
+
+// objectName returns kind and namespaced name of object
+func objectName(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return fmt.Sprintf("%s/%s", strings.ToLower(obj.GetKind()), obj.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", strings.ToLower(obj.GetKind()), obj.GetNamespace(), obj.GetName())
+}
+
+// printStructured prints 'value' in json or yaml 'format' and returns true, false is returned for other formats
+func printStructured(w io.Writer, value interface{}, format string) (bool, error) {
+	switch format {
+	case internal.FormatJSON:
+		buf, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return true, err
+		}
+		_, err = fmt.Fprintln(w, string(buf))
+		return true, err
+
+	case internal.FormatYAML:
+		buf, err := yaml.Marshal(value)
+		if err != nil {
+			return true, err
+		}
+		_, err = w.Write(buf)
+		return true, err
+	}
+	return false, nil
+}
+
+// PrintFindings prints findings in text, json or yaml format
+func PrintFindings(w io.Writer, findings []Finding, format string) error {
+	if printed, err := printStructured(w, findings, format); printed || err != nil {
+		return err
+	}
+
+	if len(findings) == 0 {
+		_, err := fmt.Fprintln(w, "No problems found")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for idx := range findings {
+		fmt.Fprintf(tw, "[%s]\t%s\t%s\n", findings[idx].Severity, findings[idx].Rule, findings[idx].Object)
+		fmt.Fprintf(tw, "\t%s\n", findings[idx].Message)
+		fmt.Fprintf(tw, "\tHint: %s\n", findings[idx].Remediation)
+	}
+	return tw.Flush()
+}
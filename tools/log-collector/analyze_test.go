@@ -0,0 +1,222 @@
+package logcollector
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+func analysisObject(kind, namespace, name string, fields map[string]interface{}) *unstructured.Unstructured {
+	obj := map[string]interface{}{"kind": kind, "metadata": map[string]interface{}{"name": name}}
+	if namespace != "" {
+		obj["metadata"].(map[string]interface{})["namespace"] = namespace
+	}
+	for key, value := range fields {
+		obj[key] = value
+	}
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func warningEvent(reason, kind, name string, count int64) *unstructured.Unstructured {
+	return analysisObject(EventKind, "app", name+"."+reason, map[string]interface{}{
+		"type": eventTypeWarning, "reason": reason, "count": count,
+		"involvedObject": map[string]interface{}{"kind": kind, "namespace": "app", "name": name}})
+}
+
+// findingKey is severity, rule and object of a finding
+type findingKey struct {
+	severity Severity
+	rule     string
+	object   string
+}
+
+func findingKeys(findings []Finding) []findingKey {
+	var keys []findingKey
+	for idx := range findings {
+		keys = append(keys, findingKey{findings[idx].Severity, findings[idx].Rule, findings[idx].Object})
+	}
+	return keys
+}
+
+func TestAnalyze(t *testing.T) {
+	expired := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	valid := time.Now().Add(time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name     string
+		objects  []*unstructured.Unstructured
+		findings []findingKey
+	}{
+		{
+			name: "failed backup and restore",
+			objects: []*unstructured.Unstructured{
+				analysisObject(internal.BackupKind, "app", "failed", map[string]interface{}{"status": map[string]interface{}{
+					"status": statusFailed}}),
+				analysisObject(internal.BackupKind, "app", "available", map[string]interface{}{
+					"status": map[string]interface{}{"status": statusAvailable}}),
+				analysisObject(ClusterRestoreKind, "", "restore", map[string]interface{}{"status": map[string]interface{}{
+					"status": statusFailed}}),
+			},
+			findings: []findingKey{
+				{SeverityCritical, "FailedBackup", "backup app/failed"},
+				{SeverityCritical, "FailedClusterRestore", "clusterrestore/restore"},
+			},
+		},
+		{
+			name: "unavailable targets",
+			objects: []*unstructured.Unstructured{
+				analysisObject(internal.TargetKind, "app", "available", map[string]interface{}{
+					"status": map[string]interface{}{"status": statusAvailable}}),
+				analysisObject(internal.TargetKind, "app", "unavailable", map[string]interface{}{
+					"status": map[string]interface{}{"status": "Unavailable"}}),
+				analysisObject(internal.TargetKind, "app", "new", nil),
+			},
+			findings: []findingKey{
+				{SeverityCritical, "TargetNotAvailable", "target app/new"},
+				{SeverityCritical, "TargetNotAvailable", "target app/unavailable"},
+			},
+		},
+		{
+			name: "crash looping containers",
+			objects: []*unstructured.Unstructured{
+				analysisObject(Pod, "app", "crashing", map[string]interface{}{"status": map[string]interface{}{
+					"containerStatuses": []interface{}{map[string]interface{}{"name": "app", "restartCount": float64(5),
+						"state": map[string]interface{}{"waiting": map[string]interface{}{"reason": crashLoopBackOff}}}}}}),
+				analysisObject(Pod, "app", "running", map[string]interface{}{"status": map[string]interface{}{
+					"containerStatuses": []interface{}{map[string]interface{}{"name": "app",
+						"state": map[string]interface{}{"running": map[string]interface{}{}}}}}}),
+			},
+			findings: []findingKey{{SeverityHigh, crashLoopBackOff, "pod app/crashing"}},
+		},
+		{
+			name: "warning events grouped by reason",
+			objects: []*unstructured.Unstructured{
+				warningEvent("FailedMount", Pod, "web-1", 3),
+				warningEvent("FailedMount", Pod, "web-2", 1),
+				warningEvent("BackOff", Pod, "web-1", 0),
+				analysisObject(EventKind, "app", "normal", map[string]interface{}{"type": "Normal", "reason": "Pulled"}),
+			},
+			findings: []findingKey{
+				{SeverityMedium, "WarningEvents", "BackOff"},
+				{SeverityMedium, "WarningEvents", "FailedMount"},
+			},
+		},
+		{
+			name: "expired and inactive licenses",
+			objects: []*unstructured.Unstructured{
+				analysisObject(LicenseKind, "tvk", "expired", map[string]interface{}{"status": map[string]interface{}{
+					"status": "Active", "properties": map[string]interface{}{"expirationTimestamp": expired}}}),
+				analysisObject(LicenseKind, "tvk", "inactive", map[string]interface{}{"status": map[string]interface{}{
+					"status": "Invalid", "expirationTimestamp": valid}}),
+				analysisObject(LicenseKind, "tvk", "active", map[string]interface{}{"status": map[string]interface{}{
+					"status": "Active", "expirationTimestamp": valid}}),
+			},
+			findings: []findingKey{
+				{SeverityCritical, "LicenseExpired", "license tvk/expired"},
+				{SeverityHigh, "LicenseNotActive", "license tvk/inactive"},
+			},
+		},
+		{
+			name: "provisioners without volume snapshot class",
+			objects: []*unstructured.Unstructured{
+				analysisObject(StorageClassKind, "", "csi", map[string]interface{}{"provisioner": "csi.example.com"}),
+				analysisObject(StorageClassKind, "", "standard", map[string]interface{}{"provisioner": "legacy.example.com"}),
+				analysisObject(StorageClassKind, "", "standard-2", map[string]interface{}{"provisioner": "legacy.example.com"}),
+				analysisObject(VolumeSnapshotClassKind, "", "csi", map[string]interface{}{"driver": "csi.example.com"}),
+			},
+			findings: []findingKey{{SeverityHigh, "MissingVolumeSnapshotClass", "legacy.example.com"}},
+		},
+		{
+			name: "findings sorted by severity",
+			objects: []*unstructured.Unstructured{
+				warningEvent("FailedScheduling", Pod, "web-1", 1),
+				analysisObject(StorageClassKind, "", "standard", map[string]interface{}{"provisioner": "legacy.example.com"}),
+				analysisObject(internal.TargetKind, "app", "target", nil),
+			},
+			findings: []findingKey{
+				{SeverityCritical, "TargetNotAvailable", "target app/target"},
+				{SeverityHigh, "MissingVolumeSnapshotClass", "legacy.example.com"},
+				{SeverityMedium, "WarningEvents", "FailedScheduling"},
+			},
+		},
+		{name: "no findings"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := make(analysisObjects)
+			for _, obj := range tt.objects {
+				objects.add(obj)
+			}
+			if got := findingKeys(analyze(objects)); !reflect.DeepEqual(got, tt.findings) {
+				t.Errorf("analyze() = %v, want %v", got, tt.findings)
+			}
+		})
+	}
+}
+
+func TestWarningEventsRuleMessage(t *testing.T) {
+	objects := make(analysisObjects)
+	for _, name := range []string{"web-1", "web-2", "web-3", "web-4"} {
+		objects.add(warningEvent("FailedMount", Pod, name, 2))
+	}
+
+	findings := warningEventsRule(objects)
+	if len(findings) != 1 {
+		t.Fatalf("warningEventsRule() returned %d findings, want 1", len(findings))
+	}
+	want := "8 warning events for pod app/web-1, pod app/web-2, pod app/web-3, 1 more"
+	if findings[0].Message != want {
+		t.Errorf("warningEventsRule() message = %q, want %q", findings[0].Message, want)
+	}
+	if findings[0].Remediation != eventHints["FailedMount"] {
+		t.Errorf("warningEventsRule() remediation = %q, want %q", findings[0].Remediation, eventHints["FailedMount"])
+	}
+}
+
+func TestAnalyzeBundle(t *testing.T) {
+	bundle := t.TempDir()
+	files := map[string]string{
+		"Backup/app/failed.yaml": "apiVersion: triliovault.trilio.io/v1\nkind: Backup\nmetadata:\n  name: failed\n" +
+			"  namespace: app\nstatus:\n  status: Failed\n",
+		"Target/app/target.yaml": "apiVersion: triliovault.trilio.io/v1\nkind: Target\nmetadata:\n  name: target\n" +
+			"  namespace: app\nstatus:\n  status: Available\n",
+		"Events/app/pod.web.yaml": "- type: Warning\n  reason: FailedMount\n  count: 2\n",
+		"Pod/app/web/web.log":     "password=REDACTED\n",
+		"invalid.yaml":            "kind: [",
+		"no-kind.yaml":            "name: value\n",
+	}
+	for name, content := range files {
+		filePath := filepath.Join(bundle, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filePath, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	findings, err := AnalyzeBundle(bundle)
+	if err != nil {
+		t.Fatalf("AnalyzeBundle() error = %v", err)
+	}
+	want := []findingKey{{SeverityCritical, "FailedBackup", "backup app/failed"},
+		{SeverityMedium, "WarningEvents", "FailedMount"}}
+	if got := findingKeys(findings); !reflect.DeepEqual(got, want) {
+		t.Errorf("AnalyzeBundle() = %v, want %v", got, want)
+	}
+	if message := "2 warning events for pod app/web"; findings[1].Message != message {
+		t.Errorf("AnalyzeBundle() message = %q, want %q", findings[1].Message, message)
+	}
+
+	if _, err = AnalyzeBundle(filepath.Join(bundle, "missing")); err == nil {
+		t.Errorf("AnalyzeBundle() of missing bundle returned no error")
+	}
+}
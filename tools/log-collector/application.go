@@ -0,0 +1,235 @@
+package logcollector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apiv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+const (
+	RestoreKind = "Restore"
+
+	helmReleaseNameAnnotation = "meta.helm.sh/release-name"
+	helmInstanceLabel         = "app.kubernetes.io/instance"
+	helmReleaseLabel          = "release"
+)
+
+// applicationScope is the application backed up by a backupPlan, objects in scope are collected along with
+// TVK resources
+type applicationScope struct {
+	namespaces   sets.String
+	selectors    []labels.Selector
+	helmReleases sets.String
+	// all is true when backupPlan has no components and whole namespace is backed up
+	all bool
+}
+
+// matches returns true if 'obj' belongs to application
+func (a *applicationScope) matches(obj *unstructured.Unstructured) bool {
+	if !a.namespaces.Has(obj.GetNamespace()) {
+		return false
+	}
+	if a.all {
+		return true
+	}
+
+	objLabels := labels.Set(obj.GetLabels())
+	for _, selector := range a.selectors {
+		if selector.Matches(objLabels) {
+			return true
+		}
+	}
+
+	return a.helmReleases.Has(obj.GetAnnotations()[helmReleaseNameAnnotation]) ||
+		a.helmReleases.Has(objLabels[helmInstanceLabel]) || a.helmReleases.Has(objLabels[helmReleaseLabel])
+}
+
+// resolveApplicationScope reads Backup or Restore given by user and resolves application scope of its backupPlan.
+// Returns nil if neither Backup nor Restore is given.
+func (l *LogCollector) resolveApplicationScope() (*applicationScope, error) {
+	switch {
+	case l.Backup != "":
+		namespace, name, err := parseNamespacedName(l.Backup, l.Namespaces)
+		if err != nil {
+			return nil, err
+		}
+		return l.getBackupApplicationScope(namespace, name)
+
+	case l.Restore != "":
+		namespace, name, err := parseNamespacedName(l.Restore, l.Namespaces)
+		if err != nil {
+			return nil, err
+		}
+		return l.getRestoreApplicationScope(namespace, name)
+	}
+
+	return nil, nil
+}
+
+// getBackupApplicationScope returns application scope of backupPlan of Backup 'namespace/name'
+func (l *LogCollector) getBackupApplicationScope(namespace, name string) (*applicationScope, error) {
+	backup, err := l.getTrilioObject(internal.BackupKind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	bpName, _, _ := unstructured.NestedString(backup.Object, "spec", "backupPlan", "name")
+	bpNamespace, _, _ := unstructured.NestedString(backup.Object, "spec", "backupPlan", "namespace")
+	if bpName == "" {
+		return nil, fmt.Errorf("backupPlan of %s %s/%s not found", internal.BackupKind, namespace, name)
+	}
+	if bpNamespace == "" {
+		bpNamespace = namespace
+	}
+
+	backupPlan, err := l.getTrilioObject(internal.BackupPlanKind, bpNamespace, bpName)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, err := getBackupPlanApplicationScope(backupPlan)
+	if err != nil {
+		return nil, err
+	}
+	scope.namespaces.Insert(namespace)
+	l.logger().Infof("Collecting application of %s %s/%s from namespaces %v", internal.BackupKind, namespace, name,
+		scope.namespaces.List())
+	return scope, nil
+}
+
+// getRestoreApplicationScope returns application scope of Restore 'namespace/name'. If Restore is from a Backup,
+// scope of its backupPlan is used in restore namespace, otherwise complete restore namespace is collected.
+func (l *LogCollector) getRestoreApplicationScope(namespace, name string) (*applicationScope, error) {
+	restore, err := l.getTrilioObject(RestoreKind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	restoreNamespace, _, _ := unstructured.NestedString(restore.Object, "spec", "restoreNamespace")
+	if restoreNamespace == "" {
+		restoreNamespace = namespace
+	}
+
+	scope := &applicationScope{namespaces: sets.NewString(), helmReleases: sets.NewString(), all: true}
+	backupName, _, _ := unstructured.NestedString(restore.Object, "spec", "source", "backup", "name")
+	if backupName != "" {
+		backupNamespace, _, _ := unstructured.NestedString(restore.Object, "spec", "source", "backup", "namespace")
+		if backupNamespace == "" {
+			backupNamespace = namespace
+		}
+
+		backupScope, bErr := l.getBackupApplicationScope(backupNamespace, backupName)
+		if bErr != nil {
+			// backup may have been deleted after restore, collecting complete restore namespace is still useful
+			l.logger().Warnf("Unable to resolve application of %s %s/%s : %s", internal.BackupKind, backupNamespace, backupName,
+				bErr.Error())
+		} else {
+			scope = backupScope
+		}
+	}
+
+	scope.namespaces.Insert(namespace, restoreNamespace)
+	l.logger().Infof("Collecting application of %s %s/%s from namespaces %v", RestoreKind, namespace, name,
+		scope.namespaces.List())
+	return scope, nil
+}
+
+// getBackupPlanApplicationScope returns namespaces, label selectors and helm releases of backupPlan components
+func getBackupPlanApplicationScope(backupPlan *unstructured.Unstructured) (*applicationScope, error) {
+	scope := &applicationScope{namespaces: sets.NewString(backupPlan.GetNamespace()), helmReleases: sets.NewString()}
+
+	components, _, _ := unstructured.NestedMap(backupPlan.Object, "spec", "backupPlanComponents")
+
+	helmReleases, _, _ := unstructured.NestedStringSlice(components, "helmReleases")
+	scope.helmReleases.Insert(helmReleases...)
+
+	custom, _, _ := unstructured.NestedSlice(components, "custom")
+	selectors, err := toLabelSelectors(custom)
+	if err != nil {
+		return nil, err
+	}
+	scope.selectors = append(scope.selectors, selectors...)
+
+	operators, _, _ := unstructured.NestedSlice(components, "operators")
+	for _, operator := range operators {
+		operatorObj, ok := operator.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if release, _, _ := unstructured.NestedString(operatorObj, "helmRelease"); release != "" {
+			scope.helmReleases.Insert(release)
+		}
+		for _, field := range []string{"operatorResourceSelector", "applicationResourceSelector"} {
+			operatorSelectors, _, _ := unstructured.NestedSlice(operatorObj, field)
+			selectors, err = toLabelSelectors(operatorSelectors)
+			if err != nil {
+				return nil, err
+			}
+			scope.selectors = append(scope.selectors, selectors...)
+		}
+	}
+
+	scope.all = len(scope.selectors) == 0 && scope.helmReleases.Len() == 0
+	return scope, nil
+}
+
+// toLabelSelectors converts label selectors of backupPlan components
+func toLabelSelectors(values []interface{}) ([]labels.Selector, error) {
+	var selectors []labels.Selector
+	for _, value := range values {
+		valueMap, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		var labelSelector apiv1.LabelSelector
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(valueMap, &labelSelector); err != nil {
+			return nil, err
+		}
+		selector, err := apiv1.LabelSelectorAsSelector(&labelSelector)
+		if err != nil {
+			return nil, err
+		}
+		if !selector.Empty() {
+			selectors = append(selectors, selector)
+		}
+	}
+	return selectors, nil
+}
+
+// getTrilioObject gets triliovault object of 'kind' with given 'namespace' and 'name'
+func (l *LogCollector) getTrilioObject(kind, namespace, name string) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{Group: internal.TriliovaultGroup, Version: "v1", Kind: kind})
+	err := l.k8sClient.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: name}, obj)
+	if err != nil {
+		l.logger().Errorf("Unable to get %s %s/%s : %s", kind, namespace, name, err.Error())
+		return nil, err
+	}
+	return obj, nil
+}
+
+// parseNamespacedName parses 'namespace/name'. If namespace is not given, only namespace in 'namespaces' is used.
+func parseNamespacedName(namespacedName string, namespaces []string) (namespace, name string, err error) {
+	parts := strings.Split(namespacedName, "/")
+	switch {
+	case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+		return parts[0], parts[1], nil
+	case len(parts) == 1 && parts[0] != "" && len(namespaces) == 1:
+		return namespaces[0], parts[0], nil
+	case len(parts) == 1 && parts[0] != "":
+		return "", "", fmt.Errorf("namespace of %q is required, provide it as namespace/name", namespacedName)
+	default:
+		return "", "", fmt.Errorf("invalid value %q, it should be in namespace/name format", namespacedName)
+	}
+}
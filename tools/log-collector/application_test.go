@@ -0,0 +1,246 @@
+package logcollector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+// trilioObject returns triliovault object of 'kind' with 'spec'
+func trilioObject(kind, namespace, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{Group: internal.TriliovaultGroup, Version: "v1", Kind: kind})
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func selectorStrings(selectors []labels.Selector) []string {
+	var list []string
+	for _, selector := range selectors {
+		list = append(list, selector.String())
+	}
+	return list
+}
+
+func TestGetBackupPlanApplicationScope(t *testing.T) {
+	tests := []struct {
+		name         string
+		components   map[string]interface{}
+		selectors    []string
+		helmReleases []string
+		all          bool
+		wantErr      bool
+	}{
+		{
+			name: "custom selectors",
+			components: map[string]interface{}{"custom": []interface{}{
+				map[string]interface{}{"matchLabels": map[string]interface{}{"app": "web"}},
+				map[string]interface{}{"matchExpressions": []interface{}{map[string]interface{}{"key": "tier",
+					"operator": "In", "values": []interface{}{"db"}}}},
+				map[string]interface{}{},
+			}},
+			selectors: []string{"app=web", "tier in (db)"},
+		},
+		{
+			name:         "helm releases",
+			components:   map[string]interface{}{"helmReleases": []interface{}{"mysql", "redis"}},
+			helmReleases: []string{"mysql", "redis"},
+		},
+		{
+			name: "operator with helm release and selectors",
+			components: map[string]interface{}{"operators": []interface{}{map[string]interface{}{
+				"operatorId":  "etcd",
+				"helmRelease": "etcd-operator",
+				"operatorResourceSelector": []interface{}{map[string]interface{}{
+					"matchLabels": map[string]interface{}{"name": "etcd-operator"}}},
+				"applicationResourceSelector": []interface{}{map[string]interface{}{
+					"matchLabels": map[string]interface{}{"app": "etcd"}}},
+			}}},
+			selectors:    []string{"name=etcd-operator", "app=etcd"},
+			helmReleases: []string{"etcd-operator"},
+		},
+		{name: "empty components", components: map[string]interface{}{}, all: true},
+		{name: "no components", all: true},
+		{
+			name: "invalid selector",
+			components: map[string]interface{}{"custom": []interface{}{map[string]interface{}{
+				"matchExpressions": []interface{}{map[string]interface{}{"key": "tier", "operator": "Unknown"}}}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := map[string]interface{}{}
+			if tt.components != nil {
+				spec["backupPlanComponents"] = tt.components
+			}
+			scope, err := getBackupPlanApplicationScope(trilioObject(internal.BackupPlanKind, "app", "bp", spec))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getBackupPlanApplicationScope() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := selectorStrings(scope.selectors); !reflect.DeepEqual(got, tt.selectors) {
+				t.Errorf("selectors = %v, want %v", got, tt.selectors)
+			}
+			if got := scope.helmReleases.List(); strings.Join(got, ",") != strings.Join(tt.helmReleases, ",") {
+				t.Errorf("helmReleases = %v, want %v", got, tt.helmReleases)
+			}
+			if scope.all != tt.all || !reflect.DeepEqual(scope.namespaces.List(), []string{"app"}) {
+				t.Errorf("all = %t, namespaces = %v, want %t, [app]", scope.all, scope.namespaces.List(), tt.all)
+			}
+		})
+	}
+}
+
+func TestApplicationScopeMatches(t *testing.T) {
+	selectors, err := toLabelSelectors([]interface{}{map[string]interface{}{
+		"matchLabels": map[string]interface{}{"app": "web"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scope := &applicationScope{namespaces: sets.NewString("app"), selectors: selectors, helmReleases: sets.NewString("mysql")}
+	allScope := &applicationScope{namespaces: sets.NewString("app"), helmReleases: sets.NewString(), all: true}
+
+	object := func(namespace string, objLabels, annotations map[string]string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		obj.SetNamespace(namespace)
+		obj.SetLabels(objLabels)
+		obj.SetAnnotations(annotations)
+		return obj
+	}
+
+	tests := []struct {
+		name  string
+		scope *applicationScope
+		obj   *unstructured.Unstructured
+		want  bool
+	}{
+		{name: "matching labels", scope: scope, obj: object("app", map[string]string{"app": "web"}, nil), want: true},
+		{name: "helm release annotation", scope: scope,
+			obj: object("app", nil, map[string]string{helmReleaseNameAnnotation: "mysql"}), want: true},
+		{name: "helm instance label", scope: scope, obj: object("app", map[string]string{helmInstanceLabel: "mysql"}, nil),
+			want: true},
+		{name: "helm release label", scope: scope, obj: object("app", map[string]string{helmReleaseLabel: "mysql"}, nil),
+			want: true},
+		{name: "unrelated object", scope: scope, obj: object("app", map[string]string{"app": "db"}, nil)},
+		{name: "other namespace", scope: scope, obj: object("other", map[string]string{"app": "web"}, nil)},
+		{name: "whole namespace", scope: allScope, obj: object("app", nil, nil), want: true},
+		{name: "other namespace of whole namespace", scope: allScope, obj: object("other", nil, nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scope.matches(tt.obj); got != tt.want {
+				t.Errorf("matches() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRestoreApplicationScope(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: internal.TriliovaultGroup, Version: "v1"}
+	scheme := runtime.NewScheme()
+	for _, kind := range []string{internal.BackupKind, internal.BackupPlanKind, RestoreKind} {
+		gvk.Kind = kind
+		scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		gvk.Kind = kind + "List"
+		scheme.AddKnownTypeWithName(gvk, &unstructured.UnstructuredList{})
+	}
+
+	backupSource := func(name string) map[string]interface{} {
+		return map[string]interface{}{"restoreNamespace": "target", "source": map[string]interface{}{
+			"backup": map[string]interface{}{"name": name, "namespace": "app"}}}
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		trilioObject(internal.BackupPlanKind, "app", "bp", map[string]interface{}{"backupPlanComponents": map[string]interface{}{
+			"helmReleases": []interface{}{"mysql"}}}),
+		trilioObject(internal.BackupKind, "app", "backup", map[string]interface{}{"backupPlan": map[string]interface{}{
+			"name": "bp"}}),
+		trilioObject(RestoreKind, "restore", "from-backup", backupSource("backup")),
+		trilioObject(RestoreKind, "restore", "from-deleted-backup", backupSource("deleted")),
+		trilioObject(RestoreKind, "restore", "from-location", map[string]interface{}{"source": map[string]interface{}{
+			"location": "s3://bucket/backup"}}),
+	).Build()
+	l := &LogCollector{k8sClient: k8sClient}
+
+	tests := []struct {
+		name         string
+		restore      string
+		namespaces   []string
+		helmReleases []string
+		all          bool
+		wantErr      bool
+	}{
+		{name: "restore from backup", restore: "from-backup", namespaces: []string{"app", "restore", "target"},
+			helmReleases: []string{"mysql"}},
+		{name: "restore from deleted backup collects restore namespace", restore: "from-deleted-backup",
+			namespaces: []string{"restore", "target"}, all: true},
+		{name: "restore from location collects restore namespace", restore: "from-location",
+			namespaces: []string{"restore"}, all: true},
+		{name: "missing restore", restore: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope, err := l.getRestoreApplicationScope("restore", tt.restore)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getRestoreApplicationScope() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(scope.namespaces.List(), tt.namespaces) || scope.all != tt.all ||
+				strings.Join(scope.helmReleases.List(), ",") != strings.Join(tt.helmReleases, ",") {
+				t.Errorf("scope = namespaces %v, helmReleases %v, all %t, want %v, %v, %t", scope.namespaces.List(),
+					scope.helmReleases.List(), scope.all, tt.namespaces, tt.helmReleases, tt.all)
+			}
+		})
+	}
+}
+
+func TestParseNamespacedName(t *testing.T) {
+	tests := []struct {
+		name           string
+		namespacedName string
+		namespaces     []string
+		namespace      string
+		objName        string
+		wantErr        bool
+	}{
+		{name: "namespace and name", namespacedName: "app/backup", namespace: "app", objName: "backup"},
+		{name: "name with single namespace", namespacedName: "backup", namespaces: []string{"app"}, namespace: "app",
+			objName: "backup"},
+		{name: "name without namespace", namespacedName: "backup", wantErr: true},
+		{name: "name with multiple namespaces", namespacedName: "backup", namespaces: []string{"app", "db"},
+			wantErr: true},
+		{name: "empty name", namespacedName: "app/", wantErr: true},
+		{name: "empty namespace", namespacedName: "/backup", wantErr: true},
+		{name: "too many parts", namespacedName: "app/backup/1", wantErr: true},
+		{name: "empty", namespacedName: "", namespaces: []string{"app"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namespace, name, err := parseNamespacedName(tt.namespacedName, tt.namespaces)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseNamespacedName() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if namespace != tt.namespace || name != tt.objName {
+				t.Errorf("parseNamespacedName() = %s, %s, want %s, %s", namespace, name, tt.namespace, tt.objName)
+			}
+		})
+	}
+}
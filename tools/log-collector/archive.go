@@ -0,0 +1,309 @@
+package logcollector
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	FormatZip   = "zip"
+	FormatTarGz = "tar.gz"
+	// StdoutOutput as output writes archive to stdout
+	StdoutOutput = "-"
+
+	// spoolThreshold is the size up to which an archive entry is buffered in memory before spilling to a temp file
+	spoolThreshold = 8 << 20
+)
+
+// openBundle opens sink of collector or, if none is set, archive of given format at output path. If source folder
+// is to be kept, bundle is written to output directory as well.
+func (l *LogCollector) openBundle() error {
+	sink := l.Sink
+	if sink == nil {
+		format := l.Format
+		if format == "" {
+			format = FormatZip
+		}
+		output := l.Output
+		if output == "" {
+			output = l.OutputDir + "." + format
+		}
+
+		var err error
+		if sink, err = newArchiveFileSink(output, format); err != nil {
+			return err
+		}
+	}
+
+	if !l.CleanOutput {
+		sink = NewMultiSink(sink, NewDirSink(l.OutputDir))
+	}
+	l.bundle = sink
+	return nil
+}
+
+// validateFormat returns error if 'format' is not a supported archive format
+func validateFormat(format string) error {
+	if format != FormatZip && format != FormatTarGz {
+		return fmt.Errorf("unsupported archive format %q, supported formats are %s and %s", format, FormatZip,
+			FormatTarGz)
+	}
+	return nil
+}
+
+// archiveSink streams bundle files into a zip or tar.gz archive. Entries are buffered until closed and then
+// written to archive one at a time, so that concurrent writers don't interleave.
+type archiveSink struct {
+	mutex   sync.Mutex
+	out     io.WriteCloser
+	zipW    *zip.Writer
+	gzipW   *gzip.Writer
+	tarW    *tar.Writer
+	modTime time.Time
+}
+
+// NewArchiveSink returns a sink writing archive of 'format' to 'out', which is closed along with the sink
+func NewArchiveSink(out io.WriteCloser, format string) (Sink, error) {
+	return newArchiveSink(out, format)
+}
+
+func newArchiveSink(out io.WriteCloser, format string) (*archiveSink, error) {
+	if err := validateFormat(format); err != nil {
+		return nil, err
+	}
+
+	a := &archiveSink{out: out, modTime: time.Now()}
+	switch format {
+	case FormatTarGz:
+		a.gzipW = gzip.NewWriter(a.out)
+		a.tarW = tar.NewWriter(a.gzipW)
+	default:
+		a.zipW = zip.NewWriter(a.out)
+	}
+	return a, nil
+}
+
+// newArchiveFileSink creates archive of 'format' at 'output' path or on stdout if 'output' is StdoutOutput
+func newArchiveFileSink(output, format string) (*archiveSink, error) {
+	if err := validateFormat(format); err != nil {
+		return nil, err
+	}
+
+	if output == StdoutOutput {
+		log.Infof("Writing %s archive to stdout", format)
+		return newArchiveSink(nopWriteCloser{Writer: os.Stdout}, format)
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		log.Errorf("Error Creating archive File : %s", err.Error())
+		return nil, err
+	}
+	log.Infof("Creating archive : %s", output)
+	return newArchiveSink(file, format)
+}
+
+func (a *archiveSink) Create(path string) (io.WriteCloser, error) {
+	return &archiveEntry{archive: a, path: filepath.ToSlash(path)}, nil
+}
+
+// add writes entry of 'size' bytes read from 'content' to archive
+func (a *archiveSink) add(path string, size int64, content io.Reader) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.tarW != nil {
+		err := a.tarW.WriteHeader(&tar.Header{
+			Name:     path,
+			Mode:     0644,
+			Size:     size,
+			ModTime:  a.modTime,
+			Typeflag: tar.TypeReg,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(a.tarW, content)
+		return err
+	}
+
+	w, err := a.zipW.CreateHeader(&zip.FileHeader{Name: path, Method: zip.Deflate, Modified: a.modTime})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, content)
+	return err
+}
+
+func (a *archiveSink) Close() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	var err error
+	if a.tarW != nil {
+		if tErr := a.tarW.Close(); tErr != nil {
+			err = tErr
+		}
+		if gErr := a.gzipW.Close(); gErr != nil && err == nil {
+			err = gErr
+		}
+	} else if zErr := a.zipW.Close(); zErr != nil {
+		err = zErr
+	}
+	if cErr := a.out.Close(); cErr != nil && err == nil {
+		err = cErr
+	}
+	if err != nil {
+		log.Errorf("Unable to close archive : %s", err.Error())
+	}
+	return err
+}
+
+// archiveEntry buffers content of an archive file in memory, spilling to a temp file once it grows beyond
+// spoolThreshold, and adds it to archive on close
+type archiveEntry struct {
+	archive *archiveSink
+	path    string
+	buf     bytes.Buffer
+	spool   *os.File
+	size    int64
+}
+
+func (e *archiveEntry) Write(p []byte) (int, error) {
+	if e.spool == nil && e.buf.Len()+len(p) > spoolThreshold {
+		spool, err := ioutil.TempFile("", "log-collector-")
+		if err != nil {
+			return 0, err
+		}
+		e.spool = spool
+		if _, err = e.buf.WriteTo(spool); err != nil {
+			return 0, err
+		}
+	}
+
+	var n int
+	var err error
+	if e.spool != nil {
+		n, err = e.spool.Write(p)
+	} else {
+		n, err = e.buf.Write(p)
+	}
+	e.size += int64(n)
+	return n, err
+}
+
+func (e *archiveEntry) Close() error {
+	if e.spool == nil {
+		return e.archive.add(e.path, e.size, &e.buf)
+	}
+
+	defer func() {
+		_ = e.spool.Close()
+		_ = os.Remove(e.spool.Name())
+	}()
+	if _, err := e.spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return e.archive.add(e.path, e.size, e.spool)
+}
+
+// readBundle calls 'fn' with slash separated path and content of every file of bundle directory, zip or tar.gz
+// archive at 'bundlePath'
+func readBundle(bundlePath string, fn func(name string, content []byte) error) error {
+	info, err := os.Stat(bundlePath)
+	if err != nil {
+		log.Errorf("Unable to read bundle : %s", err.Error())
+		return err
+	}
+
+	switch {
+	case info.IsDir():
+		return filepath.Walk(bundlePath, func(filePath string, fileInfo os.FileInfo, wErr error) error {
+			if wErr != nil || fileInfo.IsDir() {
+				return wErr
+			}
+			content, rErr := ioutil.ReadFile(filePath)
+			if rErr != nil {
+				return rErr
+			}
+			relPath, rErr := filepath.Rel(bundlePath, filePath)
+			if rErr != nil {
+				return rErr
+			}
+			return fn(filepath.ToSlash(relPath), content)
+		})
+
+	case strings.HasSuffix(bundlePath, "."+FormatTarGz) || strings.HasSuffix(bundlePath, ".tgz"):
+		file, oErr := os.Open(bundlePath)
+		if oErr != nil {
+			return oErr
+		}
+		defer file.Close()
+		gzipR, gErr := gzip.NewReader(file)
+		if gErr != nil {
+			return gErr
+		}
+		tarR := tar.NewReader(gzipR)
+		for {
+			header, tErr := tarR.Next()
+			if tErr == io.EOF {
+				return nil
+			}
+			if tErr != nil {
+				return tErr
+			}
+			if header.Typeflag != tar.TypeReg {
+				continue
+			}
+			content, rErr := ioutil.ReadAll(tarR)
+			if rErr != nil {
+				return rErr
+			}
+			if err = fn(header.Name, content); err != nil {
+				return err
+			}
+		}
+
+	default:
+		zipR, zErr := zip.OpenReader(bundlePath)
+		if zErr != nil {
+			log.Errorf("Unable to read bundle, it should be a directory, zip or tar.gz archive : %s", zErr.Error())
+			return zErr
+		}
+		defer zipR.Close()
+		for _, file := range zipR.File {
+			if file.FileInfo().IsDir() {
+				continue
+			}
+			content, rErr := readZipFile(file)
+			if rErr != nil {
+				return rErr
+			}
+			if err = fn(filepath.ToSlash(file.Name), content); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
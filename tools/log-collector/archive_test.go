@@ -0,0 +1,86 @@
+package logcollector
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeBundleFiles writes 'files' to 'sink' concurrently and closes it
+func writeBundleFiles(t *testing.T, sink Sink, files map[string][]byte) {
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+	err := runParallel(len(paths), len(paths), func(index int) error {
+		w, err := sink.Create(paths[index])
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(files[paths[index]]); err != nil {
+			return err
+		}
+		return w.Close()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readBundleFiles returns content of all files of bundle at 'bundlePath' keyed by path
+func readBundleFiles(t *testing.T, bundlePath string) map[string][]byte {
+	files := make(map[string][]byte)
+	if err := readBundle(bundlePath, func(name string, content []byte) error {
+		files[name] = content
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestArchiveSinkRoundTrip(t *testing.T) {
+	files := map[string][]byte{
+		SummaryJSONFile:                    []byte(`{"tvkVersion":"2.5.0"}`),
+		"Pod/tvk/web.server.current.log":   []byte("line 1\nline 2\n"),
+		"Events/tvk/Pod.web.yaml":          {},
+		"spooled/large.log":                bytes.Repeat([]byte("x"), spoolThreshold+1),
+		"clusters/prod/" + SummaryJSONFile: []byte(`{}`),
+	}
+
+	for _, format := range []string{FormatZip, FormatTarGz} {
+		t.Run(format, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "bundle."+format)
+			sink, err := newArchiveFileSink(output, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeBundleFiles(t, sink, files)
+
+			if got := readBundleFiles(t, output); !reflect.DeepEqual(got, files) {
+				t.Errorf("archive contains %d files %v, want %d files", len(got), bundleFileNames(got), len(files))
+			}
+		})
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	for format, wantErr := range map[string]bool{FormatZip: false, FormatTarGz: false, "tar": true, "": true} {
+		if err := validateFormat(format); (err != nil) != wantErr {
+			t.Errorf("validateFormat(%q) error = %v, wantErr %t", format, err, wantErr)
+		}
+	}
+}
+
+func bundleFileNames(files map[string][]byte) []string {
+	var names []string
+	for name, content := range files {
+		names = append(names, fmt.Sprintf("%s(%d)", name, len(content)))
+	}
+	return names
+}
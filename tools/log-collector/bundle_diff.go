@@ -0,0 +1,172 @@
+package logcollector
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ChangeType is the type of change of an object between two bundles
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "Added"
+	ChangeRemoved  ChangeType = "Removed"
+	ChangeModified ChangeType = "Modified"
+
+	// maxChangedFields is the maximum number of changed fields listed for a modified object
+	maxChangedFields = 20
+)
+
+// ignoredDiffFields change on every update of object and are not listed as changed fields
+var ignoredDiffFields = sets.NewString("metadata.resourceVersion", "metadata.managedFields", "metadata.generation")
+
+// ObjectChange is a change of an object between two bundles. Cluster is the directory of cluster of object in
+// multi-cluster bundles. Fields are changed fields of modified object, they are unknown if object is recorded as
+// unchanged in an incremental bundle.
+type ObjectChange struct {
+	Type               ChangeType `json:"type"`
+	Cluster            string     `json:"cluster,omitempty"`
+	Kind               string     `json:"kind"`
+	Namespace          string     `json:"namespace,omitempty"`
+	Name               string     `json:"name"`
+	OldResourceVersion string     `json:"oldResourceVersion,omitempty"`
+	NewResourceVersion string     `json:"newResourceVersion,omitempty"`
+	Fields             []string   `json:"fields,omitempty"`
+}
+
+// DiffBundles returns objects added, removed or modified from bundle at 'oldPath' to bundle at 'newPath', sorted by
+// cluster, kind, namespace and name. Objects are compared by resource version and content if both bundles have it.
+func DiffBundles(oldPath, newPath string) ([]ObjectChange, error) {
+	oldObjects, _, err := readBundleObjects(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newObjects, _, err := readBundleObjects(newPath)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []ObjectChange{}
+	for objPath, newObject := range newObjects {
+		oldObject, ok := oldObjects[objPath]
+		if !ok {
+			changes = append(changes, newObjectChange(ChangeAdded, newObject, "", newObject.entry.ResourceVersion))
+			continue
+		}
+		if change, modified := diffBundleObjects(oldObject, newObject); modified {
+			changes = append(changes, change)
+		}
+	}
+	for objPath, oldObject := range oldObjects {
+		if _, ok := newObjects[objPath]; !ok {
+			changes = append(changes, newObjectChange(ChangeRemoved, oldObject, oldObject.entry.ResourceVersion, ""))
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Cluster != changes[j].Cluster {
+			return changes[i].Cluster < changes[j].Cluster
+		}
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		if changes[i].Namespace != changes[j].Namespace {
+			return changes[i].Namespace < changes[j].Namespace
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes, nil
+}
+
+func newObjectChange(changeType ChangeType, obj *bundleObject, oldVersion, newVersion string) ObjectChange {
+	entry := &obj.entry
+	return ObjectChange{Type: changeType, Cluster: obj.cluster, Kind: entry.Kind, Namespace: entry.Namespace, Name: entry.Name,
+		OldResourceVersion: oldVersion, NewResourceVersion: newVersion}
+}
+
+// diffBundleObjects returns change of object between bundles and true if it is modified
+func diffBundleObjects(oldObject, newObject *bundleObject) (ObjectChange, bool) {
+	change := newObjectChange(ChangeModified, newObject, oldObject.entry.ResourceVersion,
+		newObject.entry.ResourceVersion)
+
+	if oldObject.content != nil && newObject.content != nil {
+		change.Fields = diffFields("", oldObject.content.Object, newObject.content.Object)
+		if len(change.Fields) > maxChangedFields {
+			change.Fields = append(change.Fields[:maxChangedFields], "...")
+		}
+		return change, len(change.Fields) != 0 || change.OldResourceVersion != change.NewResourceVersion
+	}
+	return change, change.OldResourceVersion != change.NewResourceVersion
+}
+
+// diffFields returns sorted paths of fields which differ between 'oldValue' and 'newValue'. Lists are compared as
+// a whole.
+func diffFields(prefix string, oldValue, newValue interface{}) []string {
+	if ignoredDiffFields.Has(prefix) {
+		return nil
+	}
+	oldMap, oldIsMap := oldValue.(map[string]interface{})
+	newMap, newIsMap := newValue.(map[string]interface{})
+	if !oldIsMap || !newIsMap {
+		if reflect.DeepEqual(oldValue, newValue) {
+			return nil
+		}
+		return []string{prefix}
+	}
+
+	keys := sets.NewString()
+	for key := range oldMap {
+		keys.Insert(key)
+	}
+	for key := range newMap {
+		keys.Insert(key)
+	}
+
+	var fields []string
+	for _, key := range keys.List() {
+		field := key
+		if prefix != "" {
+			field = prefix + "." + key
+		}
+		fields = append(fields, diffFields(field, oldMap[key], newMap[key])...)
+	}
+	return fields
+}
+
+// PrintObjectChanges prints changes of objects between bundles in text, json or yaml format, text lists cluster of
+// changes of multi-cluster bundles
+func PrintObjectChanges(w io.Writer, changes []ObjectChange, format string) error {
+	if printed, err := printStructured(w, changes, format); printed || err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "No changes found")
+		return err
+	}
+	multiCluster := false
+	for idx := range changes {
+		multiCluster = multiCluster || changes[idx].Cluster != ""
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	if multiCluster {
+		fmt.Fprint(tw, "CLUSTER\t")
+	}
+	fmt.Fprintln(tw, "CHANGE\tKIND\tNAMESPACE\tNAME\tRESOURCE VERSION\tFIELDS")
+	for idx := range changes {
+		change := &changes[idx]
+		if multiCluster {
+			fmt.Fprintf(tw, "%s\t", dashIfEmpty(change.Cluster))
+		}
+		versions := change.OldResourceVersion + " -> " + change.NewResourceVersion
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", change.Type, change.Kind, dashIfEmpty(change.Namespace),
+			change.Name, versions, dashIfEmpty(strings.Join(change.Fields, ",")))
+	}
+	return tw.Flush()
+}
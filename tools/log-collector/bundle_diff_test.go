@@ -0,0 +1,180 @@
+package logcollector
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldValue interface{}
+		newValue interface{}
+		fields   []string
+	}{
+		{name: "equal values", oldValue: map[string]interface{}{"spec": map[string]interface{}{"replicas": 1}},
+			newValue: map[string]interface{}{"spec": map[string]interface{}{"replicas": 1}}},
+		{
+			name: "nested changes, additions and removals",
+			oldValue: map[string]interface{}{"spec": map[string]interface{}{"replicas": 1, "paused": true},
+				"status": map[string]interface{}{"phase": "Pending"}},
+			newValue: map[string]interface{}{"spec": map[string]interface{}{"replicas": 2, "image": "web:2"},
+				"status": map[string]interface{}{"phase": "Pending"}},
+			fields: []string{"spec.image", "spec.paused", "spec.replicas"},
+		},
+		{
+			name:     "lists compared as a whole",
+			oldValue: map[string]interface{}{"args": []interface{}{"a", "b"}},
+			newValue: map[string]interface{}{"args": []interface{}{"a", "c"}},
+			fields:   []string{"args"},
+		},
+		{
+			name: "ignored fields",
+			oldValue: map[string]interface{}{"metadata": map[string]interface{}{"resourceVersion": "1", "generation": 1,
+				"managedFields": []interface{}{"a"}, "labels": map[string]interface{}{"app": "web"}}},
+			newValue: map[string]interface{}{"metadata": map[string]interface{}{"resourceVersion": "2", "generation": 2,
+				"managedFields": []interface{}{"b"}, "labels": map[string]interface{}{"app": "api"}}},
+			fields: []string{"metadata.labels.app"},
+		},
+		{
+			name:     "map replaced by value",
+			oldValue: map[string]interface{}{"spec": map[string]interface{}{"replicas": 1}},
+			newValue: map[string]interface{}{"spec": "none"},
+			fields:   []string{"spec"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffFields("", tt.oldValue, tt.newValue); !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("diffFields() = %v, want %v", got, tt.fields)
+			}
+		})
+	}
+}
+
+func testBundleObject(kind, name, resourceVersion, spec string) []byte {
+	return []byte("apiVersion: v1\nkind: " + kind + "\nmetadata:\n  name: " + name + "\n  namespace: app\n" +
+		"  resourceVersion: \"" + resourceVersion + "\"\nspec:\n  value: " + spec + "\n")
+}
+
+func TestDiffBundles(t *testing.T) {
+	oldBundle := filepath.Join(t.TempDir(), "old")
+	writeBundleFiles(t, NewDirSink(oldBundle), map[string][]byte{
+		"ConfigMap/app/unchanged.yaml": testBundleObject("ConfigMap", "unchanged", "1", "a"),
+		"ConfigMap/app/modified.yaml":  testBundleObject("ConfigMap", "modified", "1", "a"),
+		"ConfigMap/app/removed.yaml":   testBundleObject("ConfigMap", "removed", "1", "a"),
+		"Service/app/updated.yaml":     testBundleObject("Service", "updated", "1", "a"),
+		"Service/app/recorded.yaml":    testBundleObject("Service", "recorded", "1", "a"),
+		"Pod/app/web/web.current.log":  []byte("log line\n"),
+	})
+
+	// new bundle is incremental, objects unchanged since old bundle are only recorded in object manifest
+	newBundle := filepath.Join(t.TempDir(), "new")
+	writeBundleFiles(t, NewDirSink(newBundle), map[string][]byte{
+		"ConfigMap/app/unchanged.yaml": testBundleObject("ConfigMap", "unchanged", "1", "a"),
+		"ConfigMap/app/modified.yaml":  testBundleObject("ConfigMap", "modified", "2", "b"),
+		"ConfigMap/app/added.yaml":     testBundleObject("ConfigMap", "added", "3", "a"),
+		ObjectManifestFile: []byte("objects:\n" +
+			"- path: Service/app/updated.yaml\n  kind: Service\n  namespace: app\n  name: updated\n  resourceVersion: \"4\"\n" +
+			"- path: Service/app/recorded.yaml\n  kind: Service\n  namespace: app\n  name: recorded\n" +
+			"  resourceVersion: \"1\"\n  unchanged: true\n"),
+	})
+
+	changes, err := DiffBundles(oldBundle, newBundle)
+	if err != nil {
+		t.Fatalf("DiffBundles() error = %v", err)
+	}
+	want := []ObjectChange{
+		{Type: ChangeAdded, Kind: "ConfigMap", Namespace: "app", Name: "added", NewResourceVersion: "3"},
+		{Type: ChangeModified, Kind: "ConfigMap", Namespace: "app", Name: "modified", OldResourceVersion: "1",
+			NewResourceVersion: "2", Fields: []string{"spec.value"}},
+		{Type: ChangeRemoved, Kind: "ConfigMap", Namespace: "app", Name: "removed", OldResourceVersion: "1"},
+		{Type: ChangeModified, Kind: "Service", Namespace: "app", Name: "updated", OldResourceVersion: "1",
+			NewResourceVersion: "4"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("DiffBundles() = %+v, want %+v", changes, want)
+	}
+
+	if _, err = DiffBundles(oldBundle, filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("DiffBundles() of missing bundle returned no error")
+	}
+}
+
+func TestDiffMultiClusterBundles(t *testing.T) {
+	oldBundle := filepath.Join(t.TempDir(), "old")
+	writeBundleFiles(t, NewDirSink(oldBundle), map[string][]byte{
+		ClustersSummaryJSONFile:                     []byte(`{"clusters": []}`),
+		"clusters/east/ConfigMap/app/config.yaml":   testBundleObject("ConfigMap", "config", "1", "a"),
+		"clusters/east/ConfigMap/app/removed.yaml":  testBundleObject("ConfigMap", "removed", "1", "a"),
+		"clusters/west/ConfigMap/app/config.yaml":   testBundleObject("ConfigMap", "config", "1", "a"),
+		"clusters/west/Service/app/recorded.yaml":   testBundleObject("Service", "recorded", "1", "a"),
+		"clusters/west/Events/app/events.yaml":      testBundleObject("EventList", "events", "1", "a"),
+		"clusters/west/Pod/app/web/web.current.log": []byte("log line\n"),
+	})
+
+	// objects of west cluster unchanged since old bundle are only recorded in its object manifest
+	newBundle := filepath.Join(t.TempDir(), "new")
+	writeBundleFiles(t, NewDirSink(newBundle), map[string][]byte{
+		ClustersSummaryJSONFile:                   []byte(`{"clusters": []}`),
+		"clusters/east/ConfigMap/app/config.yaml": testBundleObject("ConfigMap", "config", "1", "a"),
+		"clusters/west/ConfigMap/app/config.yaml": testBundleObject("ConfigMap", "config", "2", "b"),
+		"clusters/west/" + ObjectManifestFile: []byte("objects:\n" +
+			"- path: Service/app/recorded.yaml\n  kind: Service\n  namespace: app\n  name: recorded\n" +
+			"  resourceVersion: \"1\"\n  unchanged: true\n" +
+			"- path: Service/app/updated.yaml\n  kind: Service\n  namespace: app\n  name: updated\n  resourceVersion: \"3\"\n"),
+	})
+
+	changes, err := DiffBundles(oldBundle, newBundle)
+	if err != nil {
+		t.Fatalf("DiffBundles() error = %v", err)
+	}
+	want := []ObjectChange{
+		{Type: ChangeRemoved, Cluster: "east", Kind: "ConfigMap", Namespace: "app", Name: "removed", OldResourceVersion: "1"},
+		{Type: ChangeModified, Cluster: "west", Kind: "ConfigMap", Namespace: "app", Name: "config", OldResourceVersion: "1",
+			NewResourceVersion: "2", Fields: []string{"spec.value"}},
+		{Type: ChangeAdded, Cluster: "west", Kind: "Service", Namespace: "app", Name: "updated", NewResourceVersion: "3"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("DiffBundles() = %+v, want %+v", changes, want)
+	}
+
+	var out bytes.Buffer
+	if err = PrintObjectChanges(&out, changes, ""); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(out.String(), "\n"); !strings.HasPrefix(lines[0], "CLUSTER") ||
+		!strings.HasPrefix(lines[1], "east") {
+		t.Errorf("PrintObjectChanges() = %s, want cluster of changes listed", out.String())
+	}
+
+	if _, err = loadBaseBundle(newBundle); err == nil {
+		t.Errorf("loadBaseBundle() of multi-cluster bundle returned no error")
+	}
+}
+
+func TestSplitClusterPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		cluster string
+		relPath string
+	}{
+		{name: "clusters/east/ConfigMap/app/config.yaml", cluster: "east", relPath: "ConfigMap/app/config.yaml"},
+		{name: "clusters/east/" + ObjectManifestFile, cluster: "east", relPath: ObjectManifestFile},
+		{name: "ConfigMap/app/config.yaml", relPath: "ConfigMap/app/config.yaml"},
+		{name: ObjectManifestFile, relPath: ObjectManifestFile},
+		{name: "clusters/east", relPath: "clusters/east"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cluster, relPath := splitClusterPath(tt.name); cluster != tt.cluster || relPath != tt.relPath {
+				t.Errorf("splitClusterPath() = %s, %s, want %s, %s", cluster, relPath, tt.cluster, tt.relPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package logcollector
+
+import (
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const ErrorsFile = "errors.yaml"
+
+// collectionError is a failure encountered while collecting a resource
+type collectionError struct {
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace,omitempty"`
+	Status    int32  `json:"status,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	Message   string `json:"message"`
+}
+
+type collectionErrorsFile struct {
+	Errors []collectionError `json:"errors"`
+}
+
+// collectionErrors records failures of collection, it is safe to be used concurrently
+type collectionErrors struct {
+	mutex  sync.Mutex
+	errors []collectionError
+}
+
+// add records 'err' of collecting 'resource' in 'namespace' along with its HTTP status if it is an API error
+func (c *collectionErrors) add(resource, namespace string, err error) {
+	entry := collectionError{Resource: resource, Namespace: namespace, Message: err.Error()}
+	var apiStatus apierrors.APIStatus
+	if stderrors.As(err, &apiStatus) {
+		entry.Status = apiStatus.Status().Code
+		entry.Reason = string(apiStatus.Status().Reason)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.errors = append(c.errors, entry)
+}
+
+func (c *collectionErrors) list() []collectionError {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]collectionError{}, c.errors...)
+}
+
+// handleError returns 'err' of collecting 'resource' in 'namespace' to abort collection, in continue-on-error mode
+// it is recorded and nil is returned to continue with other resources
+func (l *LogCollector) handleError(resource, namespace string, err error) error {
+	if !l.ContinueOnError {
+		return err
+	}
+	l.collectionErrors.add(resource, namespace, err)
+	l.logger().Warnf("Skipping %s : %s", resource, err.Error())
+	return nil
+}
+
+// writeCollectionErrors writes errors.yaml listing failures of collection, nothing is written if there are none
+func (l *LogCollector) writeCollectionErrors() error {
+	errs := l.collectionErrors.list()
+	if len(errs) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Resource != errs[j].Resource {
+			return errs[i].Resource < errs[j].Resource
+		}
+		return errs[i].Namespace < errs[j].Namespace
+	})
+
+	buf, err := yaml.Marshal(collectionErrorsFile{Errors: errs})
+	if err != nil {
+		l.logger().Errorf("Unable to marshal the content : %s", err.Error())
+		return err
+	}
+	return l.writeFile(ErrorsFile, buf)
+}
+
+// checkCollectionErrors returns error if failures are recorded and nothing could be collected or strict mode is set
+func (l *LogCollector) checkCollectionErrors() error {
+	errs := l.collectionErrors.list()
+	if len(errs) == 0 {
+		return nil
+	}
+
+	l.logger().Warnf("%d failures encountered during collection, refer %s in bundle", len(errs), ErrorsFile)
+	if l.summary.collected() == 0 {
+		return fmt.Errorf("nothing could be collected, %d failures encountered, refer %s in bundle", len(errs),
+			ErrorsFile)
+	}
+	if l.Strict {
+		return fmt.Errorf("%d failures encountered during collection in strict mode, refer %s in bundle", len(errs),
+			ErrorsFile)
+	}
+	return nil
+}
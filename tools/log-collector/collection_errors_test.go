@@ -0,0 +1,148 @@
+package logcollector
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+func TestCollectionErrorsAdd(t *testing.T) {
+	notFound := apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "web")
+
+	tests := []struct {
+		name  string
+		err   error
+		entry collectionError
+	}{
+		{
+			name: "api error",
+			err:  apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("access denied")),
+			entry: collectionError{Resource: "pods", Namespace: "app", Status: 403, Reason: "Forbidden",
+				Message: "pods is forbidden: access denied"},
+		},
+		{
+			name: "wrapped api error",
+			err:  fmt.Errorf("listing pods: %w", notFound),
+			entry: collectionError{Resource: "pods", Namespace: "app", Status: 404, Reason: "NotFound",
+				Message: `listing pods: pods "web" not found`},
+		},
+		{
+			name:  "other error",
+			err:   errors.New("connection refused"),
+			entry: collectionError{Resource: "pods", Namespace: "app", Message: "connection refused"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs collectionErrors
+			errs.add("pods", "app", tt.err)
+			if got := errs.list(); !reflect.DeepEqual(got, []collectionError{tt.entry}) {
+				t.Errorf("list() = %+v, want %+v", got, []collectionError{tt.entry})
+			}
+		})
+	}
+}
+
+func TestHandleError(t *testing.T) {
+	err := errors.New("connection refused")
+
+	tests := []struct {
+		name            string
+		continueOnError bool
+		wantErr         bool
+		recorded        int
+	}{
+		{name: "abort on error", wantErr: true},
+		{name: "continue on error", continueOnError: true, recorded: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &LogCollector{ContinueOnError: tt.continueOnError}
+			if got := l.handleError("pods", "app", err); (got != nil) != tt.wantErr {
+				t.Errorf("handleError() = %v, wantErr %t", got, tt.wantErr)
+			}
+			if recorded := len(l.collectionErrors.list()); recorded != tt.recorded {
+				t.Errorf("handleError() recorded %d errors, want %d", recorded, tt.recorded)
+			}
+		})
+	}
+}
+
+func TestCheckCollectionErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		strict    bool
+		errors    int
+		collected int
+		wantErr   string
+	}{
+		{name: "no errors", collected: 1},
+		{name: "errors with collected objects", errors: 2, collected: 1},
+		{name: "nothing collected", errors: 2, wantErr: "nothing could be collected"},
+		{name: "errors in strict mode", strict: true, errors: 1, collected: 1, wantErr: "in strict mode"},
+		{name: "strict mode without errors", strict: true, collected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &LogCollector{Strict: tt.strict}
+			l.summary.addObjects(Pod, tt.collected)
+			for idx := 0; idx < tt.errors; idx++ {
+				l.collectionErrors.add("pods", "app", errors.New("connection refused"))
+			}
+
+			err := l.checkCollectionErrors()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("checkCollectionErrors() error = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("checkCollectionErrors() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWriteCollectionErrors(t *testing.T) {
+	dir := t.TempDir()
+	l := &LogCollector{bundle: NewDirSink(dir)}
+	if err := l.writeCollectionErrors(); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("writeCollectionErrors() without errors wrote %d files, want none", len(files))
+	}
+
+	l.collectionErrors.add("services", "", errors.New("timeout"))
+	l.collectionErrors.add("pods", "b", errors.New("connection refused"))
+	l.collectionErrors.add("pods", "a", errors.New("connection refused"))
+	if err := l.writeCollectionErrors(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, ErrorsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written collectionErrorsFile
+	if err = yaml.Unmarshal(content, &written); err != nil {
+		t.Fatal(err)
+	}
+	want := []collectionError{
+		{Resource: "pods", Namespace: "a", Message: "connection refused"},
+		{Resource: "pods", Namespace: "b", Message: "connection refused"},
+		{Resource: "services", Message: "timeout"},
+	}
+	if !reflect.DeepEqual(written.Errors, want) {
+		t.Errorf("%s = %+v, want %+v", ErrorsFile, written.Errors, want)
+	}
+}
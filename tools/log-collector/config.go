@@ -0,0 +1,84 @@
+package logcollector
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Config is the log-collector configuration file which customizes collection of resources & logs
+type Config struct {
+	Redaction RedactionConfig `json:"redaction,omitempty"`
+	Resources ResourcesConfig `json:"resources,omitempty"`
+}
+
+// ResourcesConfig customizes which resources are collected in addition to or instead of default TVK resources
+type ResourcesConfig struct {
+	// Include are the resources collected completely, irrespective of TVK label and default exclusions
+	Include []GroupVersionKind `json:"include,omitempty"`
+	// ExcludeKinds are the kinds which are not collected, exclusion takes precedence over inclusion
+	ExcludeKinds []string `json:"excludeKinds,omitempty"`
+	// LabelSelectors select objects to be collected along with objects having TVK label
+	LabelSelectors []string `json:"labelSelectors,omitempty"`
+	// SkipLogs skips collection of pod logs
+	SkipLogs bool `json:"skipLogs,omitempty"`
+}
+
+// GroupVersionKind identifies resource to be included. Empty 'Group' is core group and empty 'Version' matches
+// all versions of group.
+type GroupVersionKind struct {
+	Group   string `json:"group,omitempty"`
+	Version string `json:"version,omitempty"`
+	Kind    string `json:"kind"`
+}
+
+// RedactionConfig contains user supplied redaction rules applied in addition to built-in rules
+type RedactionConfig struct {
+	Rules []RedactionRule `json:"rules,omitempty"`
+}
+
+// RedactionRule replaces all matches of regex 'Pattern' in collected YAMLs and logs with 'Replacement'.
+// 'Replacement' can refer regex groups like ${1}, defaults to 'REDACTED'.
+type RedactionRule struct {
+	Name        string `json:"name"`
+	Pattern     string `json:"pattern"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// LoadConfig reads log-collector configuration from file at 'configPath'
+func LoadConfig(configPath string) (*Config, error) {
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	if err = yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("invalid config file %s : %s", configPath, err.Error())
+	}
+	return config, nil
+}
+
+// ParseGroupVersionKind parses resource given in 'kind', 'group/kind' or 'group/version/kind' format. Core group
+// resources can be given as 'kind' or '/version/kind' e.g. 'ConfigMap', '/v1/ConfigMap', 'apps/v1/Deployment'.
+func ParseGroupVersionKind(gvk string) (GroupVersionKind, error) {
+	var parsed GroupVersionKind
+	parts := strings.Split(gvk, "/")
+	switch len(parts) {
+	case 1:
+		parsed = GroupVersionKind{Kind: parts[0]}
+	case 2:
+		parsed = GroupVersionKind{Group: parts[0], Kind: parts[1]}
+	case 3:
+		parsed = GroupVersionKind{Group: parts[0], Version: parts[1], Kind: parts[2]}
+	default:
+		return parsed, fmt.Errorf("invalid resource %q, it should be in [group/[version/]]kind format", gvk)
+	}
+
+	if parsed.Kind == "" {
+		return parsed, fmt.Errorf("invalid resource %q, kind is required", gvk)
+	}
+	return parsed, nil
+}
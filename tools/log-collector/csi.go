@@ -0,0 +1,401 @@
+package logcollector
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apiv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+const (
+	PersistentVolumeClaimKind = "PersistentVolumeClaim"
+	CSIDriverKind             = "CSIDriver"
+	CSINodeKind               = "CSINode"
+	VolumeAttachmentKind      = "VolumeAttachment"
+	VolumeSnapshotContentKind = "VolumeSnapshotContent"
+
+	storageClassAnnotation = "volume.beta.kubernetes.io/storage-class"
+
+	// nodeDriverRegistrar is the CSI sidecar registering node plugin of a driver with kubelet
+	nodeDriverRegistrar        = "node-driver-registrar"
+	kubeletRegistrationPathArg = "--kubelet-registration-path"
+	// csiAddressArg is the argument of CSI sidecars giving socket of the driver they talk to
+	csiAddressArg = "--csi-address"
+)
+
+var (
+	// csiKinds are CSI objects collected for drivers of TVK related PVCs, in order of preference of group versions
+	csiKinds = [][]schema.GroupVersionKind{
+		{{Group: StorageGroup, Version: "v1", Kind: CSIDriverKind}, {Group: StorageGroup, Version: "v1beta1", Kind: CSIDriverKind}},
+		{{Group: StorageGroup, Version: "v1", Kind: CSINodeKind}, {Group: StorageGroup, Version: "v1beta1", Kind: CSINodeKind}},
+		{{Group: StorageGroup, Version: "v1", Kind: VolumeAttachmentKind}},
+		{
+			{Group: SnapshotStorageGroup, Version: "v1", Kind: VolumeSnapshotContentKind},
+			{Group: SnapshotStorageGroup, Version: "v1beta1", Kind: VolumeSnapshotContentKind},
+		},
+	}
+
+	// csiWorkloadKinds are kinds of workloads running node and controller plugins of CSI drivers
+	csiWorkloadKinds = []string{"DaemonSet", "Deployment", "StatefulSet"}
+
+	// csiSidecars are images of CSI sidecars run by controller pods of CSI drivers
+	csiSidecars = []string{"csi-provisioner", "csi-attacher", "csi-snapshotter", "csi-resizer"}
+)
+
+// csiRecorder records storage classes and volumes of collected PVCs and collected pods, it is safe to be used
+// concurrently
+type csiRecorder struct {
+	mutex          sync.Mutex
+	storageClasses sets.String
+	volumes        sets.String
+	pods           sets.String
+}
+
+// record records storage class and volume of PVC and namespaced name of pod 'obj'
+func (c *csiRecorder) record(obj *unstructured.Unstructured) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.storageClasses == nil {
+		c.storageClasses, c.volumes, c.pods = sets.NewString(), sets.NewString(), sets.NewString()
+	}
+
+	switch obj.GetKind() {
+	case PersistentVolumeClaimKind:
+		storageClass, _, _ := unstructured.NestedString(obj.Object, "spec", "storageClassName")
+		if storageClass == "" {
+			storageClass = obj.GetAnnotations()[storageClassAnnotation]
+		}
+		if storageClass != "" {
+			c.storageClasses.Insert(storageClass)
+		}
+		if volume, _, _ := unstructured.NestedString(obj.Object, "spec", "volumeName"); volume != "" {
+			c.volumes.Insert(volume)
+		}
+	case Pod:
+		c.pods.Insert(types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}.String())
+	}
+}
+
+func (c *csiRecorder) state() (storageClasses, pods sets.String) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return sets.NewString(c.storageClasses.UnsortedList()...), sets.NewString(c.pods.UnsortedList()...)
+}
+
+// persistentVolumes returns names of persistent volumes bound to collected PVCs
+func (c *csiRecorder) persistentVolumes() sets.String {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return sets.NewString(c.volumes.UnsortedList()...)
+}
+
+// collectCSIDiagnostics collects CSIDriver, CSINode, VolumeAttachment, VolumeSnapshotContent objects and pods with
+// logs of CSI drivers which are provisioners of storage classes of collected PVCs
+func (l *LogCollector) collectCSIDiagnostics() error {
+	storageClasses, collectedPods := l.csi.state()
+	drivers, err := l.getCSIDrivers(storageClasses)
+	if err != nil {
+		return l.handleError(StorageClassKind, "", err)
+	}
+	if drivers.Len() == 0 {
+		l.logger().Info("No CSI drivers found for collected PVCs")
+		return nil
+	}
+	l.logger().Infof("Collecting diagnostics of CSI drivers %v", drivers.List())
+
+	for _, gvks := range csiKinds {
+		kind := gvks[0].Kind
+		if l.selection.isExcluded(kind) {
+			l.logger().Infof("Skipping excluded '%s' Resource", kind)
+			continue
+		}
+		objects, lErr := l.listClusterObjects(gvks)
+		if lErr != nil {
+			if hErr := l.handleError(kind, "", lErr); hErr != nil {
+				return hErr
+			}
+			continue
+		}
+		for idx := range objects {
+			if !referencesCSIDriver(&objects[idx], drivers) {
+				continue
+			}
+			if wErr := l.writeYaml(kind, objects[idx]); wErr != nil {
+				return wErr
+			}
+		}
+	}
+
+	if l.selection.isExcluded(Pod) {
+		return nil
+	}
+	pods, err := l.getCSIDriverPods(drivers)
+	if err != nil {
+		return l.handleError(Pod, "", err)
+	}
+	var driverPods unstructured.UnstructuredList
+	for idx := range pods {
+		if !collectedPods.Has(types.NamespacedName{Namespace: pods[idx].GetNamespace(), Name: pods[idx].GetName()}.String()) {
+			driverPods.Items = append(driverPods.Items, pods[idx])
+		}
+	}
+	return l.writeObjectsAndLogs(driverPods, Pod)
+}
+
+// getCSIDrivers returns provisioners of 'storageClasses'
+func (l *LogCollector) getCSIDrivers(storageClasses sets.String) (sets.String, error) {
+	drivers := sets.NewString()
+	if storageClasses.Len() == 0 {
+		return drivers, nil
+	}
+
+	var storageClassList storagev1.StorageClassList
+	if err := l.k8sClient.List(context.Background(), &storageClassList); err != nil {
+		l.logger().Errorf("Unable to list StorageClasses : %s", err.Error())
+		return drivers, err
+	}
+	for idx := range storageClassList.Items {
+		if storageClasses.Has(storageClassList.Items[idx].Name) {
+			drivers.Insert(storageClassList.Items[idx].Provisioner)
+		}
+	}
+	return drivers, nil
+}
+
+// listClusterObjects lists objects of first group version kind of 'gvks' available on cluster
+func (l *LogCollector) listClusterObjects(gvks []schema.GroupVersionKind) ([]unstructured.Unstructured, error) {
+	for _, gvk := range gvks {
+		if !internal.CheckIfAPIVersionKindAvailable(l.disClient, gvk) {
+			continue
+		}
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := l.k8sClient.List(context.Background(), list); err != nil {
+			l.logger().Errorf("Unable to list %s : %s", gvk.Kind, err.Error())
+			return nil, err
+		}
+		for idx := range list.Items {
+			list.Items[idx].SetGroupVersionKind(gvk)
+		}
+		return list.Items, nil
+	}
+	l.logger().Debugf("%s not found on cluster", gvks[0].Kind)
+	return nil, nil
+}
+
+// referencesCSIDriver returns true if CSI object 'obj' belongs to one of 'drivers'
+func referencesCSIDriver(obj *unstructured.Unstructured, drivers sets.String) bool {
+	switch obj.GetKind() {
+	case CSIDriverKind:
+		return drivers.Has(obj.GetName())
+	case CSINodeKind:
+		nodeDrivers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "drivers")
+		for _, nodeDriver := range nodeDrivers {
+			driver, ok := nodeDriver.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if name, _, _ := unstructured.NestedString(driver, "name"); drivers.Has(name) {
+				return true
+			}
+		}
+		return false
+	case VolumeAttachmentKind:
+		attacher, _, _ := unstructured.NestedString(obj.Object, "spec", "attacher")
+		return drivers.Has(attacher)
+	default:
+		driver, _, _ := unstructured.NestedString(obj.Object, "spec", "driver")
+		return drivers.Has(driver)
+	}
+}
+
+// getCSIDriverPods returns pods of CSI 'drivers'. Node plugin DaemonSets of drivers are found by their
+// node-driver-registrar sidecar and controller Deployments and StatefulSets by CSI sidecars of the drivers running in
+// namespaces of node plugins, pods of these workloads are listed with their label selectors.
+func (l *LogCollector) getCSIDriverPods(drivers sets.String) ([]unstructured.Unstructured, error) {
+	var daemonSets appsv1.DaemonSetList
+	if err := l.k8sClient.List(context.Background(), &daemonSets); err != nil {
+		l.logger().Errorf("Unable to list DaemonSets : %s", err.Error())
+		return nil, err
+	}
+
+	var workloads []csiWorkload
+	namespaces := sets.NewString()
+	for idx := range daemonSets.Items {
+		daemonSet := &daemonSets.Items[idx]
+		if registersCSIDriver(&daemonSet.Spec.Template.Spec, drivers) {
+			namespaces.Insert(daemonSet.Namespace)
+			workloads = append(workloads, csiWorkload{namespace: daemonSet.Namespace, name: daemonSet.Name,
+				selector: daemonSet.Spec.Selector})
+		}
+	}
+
+	for _, namespace := range namespaces.List() {
+		var deployments appsv1.DeploymentList
+		if err := l.k8sClient.List(context.Background(), &deployments, client.InNamespace(namespace)); err != nil {
+			l.logger().Errorf("Unable to list Deployments : %s", err.Error())
+			return nil, err
+		}
+		for idx := range deployments.Items {
+			deployment := &deployments.Items[idx]
+			if runsCSIController(&deployment.Spec.Template.Spec, drivers) {
+				workloads = append(workloads, csiWorkload{namespace: namespace, name: deployment.Name,
+					selector: deployment.Spec.Selector})
+			}
+		}
+
+		var statefulSets appsv1.StatefulSetList
+		if err := l.k8sClient.List(context.Background(), &statefulSets, client.InNamespace(namespace)); err != nil {
+			l.logger().Errorf("Unable to list StatefulSets : %s", err.Error())
+			return nil, err
+		}
+		for idx := range statefulSets.Items {
+			statefulSet := &statefulSets.Items[idx]
+			if runsCSIController(&statefulSet.Spec.Template.Spec, drivers) {
+				workloads = append(workloads, csiWorkload{namespace: namespace, name: statefulSet.Name,
+					selector: statefulSet.Spec.Selector})
+			}
+		}
+	}
+
+	listed := sets.NewString()
+	var driverPods []unstructured.Unstructured
+	for idx := range workloads {
+		pods, err := l.listWorkloadPods(&workloads[idx])
+		if err != nil {
+			return nil, err
+		}
+		for podIdx := range pods {
+			name := types.NamespacedName{Namespace: pods[podIdx].GetNamespace(), Name: pods[podIdx].GetName()}.String()
+			if !listed.Has(name) {
+				listed.Insert(name)
+				driverPods = append(driverPods, pods[podIdx])
+			}
+		}
+	}
+	return driverPods, nil
+}
+
+// csiWorkload is a workload running node or controller plugin of a CSI driver
+type csiWorkload struct {
+	namespace string
+	name      string
+	selector  *apiv1.LabelSelector
+}
+
+// listWorkloadPods lists pods matching label selector of 'workload' in its namespace
+func (l *LogCollector) listWorkloadPods(workload *csiWorkload) ([]unstructured.Unstructured, error) {
+	selector, err := apiv1.LabelSelectorAsSelector(workload.selector)
+	if err != nil || selector.Empty() {
+		l.logger().Debugf("Skipping pods of CSI workload %s/%s with invalid or empty selector", workload.namespace,
+			workload.name)
+		return nil, nil
+	}
+
+	podList := &unstructured.UnstructuredList{}
+	podList.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("PodList"))
+	if err = l.k8sClient.List(context.Background(), podList, client.InNamespace(workload.namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		l.logger().Errorf("Unable to list Pods : %s", err.Error())
+		return nil, err
+	}
+	for idx := range podList.Items {
+		podList.Items[idx].SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(Pod))
+	}
+	return podList.Items, nil
+}
+
+// registersCSIDriver returns true if pod template 'spec' of a node plugin runs node-driver-registrar and registers
+// one of 'drivers', either by plugin directory of kubelet registration path or by driver name given as value of an
+// argument or environment variable of its containers
+func registersCSIDriver(spec *corev1.PodSpec, drivers sets.String) bool {
+	registrar := false
+	for idx := range spec.Containers {
+		container := &spec.Containers[idx]
+		if !strings.Contains(container.Image, nodeDriverRegistrar) {
+			continue
+		}
+		registrar = true
+		if registrationPath := containerArg(container, kubeletRegistrationPathArg); registrationPath != "" &&
+			drivers.Has(path.Base(path.Dir(registrationPath))) {
+			return true
+		}
+	}
+	return registrar && referencesDriverName(spec, drivers)
+}
+
+// referencesDriverName returns true if one of 'drivers' is given as value of an argument or environment variable of
+// containers of pod template 'spec'
+func referencesDriverName(spec *corev1.PodSpec, drivers sets.String) bool {
+	for idx := range spec.Containers {
+		container := &spec.Containers[idx]
+		for _, arg := range append(append([]string{}, container.Command...), container.Args...) {
+			value := arg
+			if separator := strings.Index(arg, "="); separator >= 0 {
+				value = arg[separator+1:]
+			}
+			if drivers.Has(value) {
+				return true
+			}
+		}
+		for envIdx := range container.Env {
+			if drivers.Has(container.Env[envIdx].Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containerArg returns value of argument 'name' of 'container' given as name=value or as separate arguments, with
+// references of container environment variables expanded
+func containerArg(container *corev1.Container, name string) string {
+	value := ""
+	args := append(append([]string{}, container.Command...), container.Args...)
+	for idx, arg := range args {
+		if strings.HasPrefix(arg, name+"=") {
+			value = strings.TrimPrefix(arg, name+"=")
+			break
+		}
+		if arg == name && idx+1 < len(args) {
+			value = args[idx+1]
+			break
+		}
+	}
+	for idx := range container.Env {
+		value = strings.ReplaceAll(value, "$("+container.Env[idx].Name+")", container.Env[idx].Value)
+	}
+	return value
+}
+
+// runsCSIController returns true if pod template 'spec' of a controller plugin runs a CSI sidecar talking to one of
+// 'drivers', either by plugin directory of CSI address or by driver name given as value of an argument or environment
+// variable of its containers
+func runsCSIController(spec *corev1.PodSpec, drivers sets.String) bool {
+	sidecar := false
+	for idx := range spec.Containers {
+		container := &spec.Containers[idx]
+		for _, name := range csiSidecars {
+			if !strings.Contains(container.Image, name) {
+				continue
+			}
+			sidecar = true
+			if address := containerArg(container, csiAddressArg); address != "" && drivers.Has(path.Base(path.Dir(address))) {
+				return true
+			}
+		}
+	}
+	return sidecar && referencesDriverName(spec, drivers)
+}
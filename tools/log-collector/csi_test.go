@@ -0,0 +1,257 @@
+package logcollector
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testDriver      = "ebs.csi.aws.com"
+	testOtherDriver = "efs.csi.aws.com"
+)
+
+func registrarContainer(args ...string) corev1.Container {
+	return corev1.Container{Name: "node-driver-registrar",
+		Image: "k8s.gcr.io/sig-storage/csi-node-driver-registrar:v2.1.0", Args: args}
+}
+
+func TestRegistersCSIDriver(t *testing.T) {
+	drivers := sets.NewString(testDriver)
+
+	tests := []struct {
+		name string
+		spec corev1.PodSpec
+		want bool
+	}{
+		{
+			name: "registration path argument",
+			spec: corev1.PodSpec{Containers: []corev1.Container{
+				registrarContainer("--kubelet-registration-path=/var/lib/kubelet/plugins/ebs.csi.aws.com/csi.sock")}},
+			want: true,
+		},
+		{
+			name: "registration path given as separate argument",
+			spec: corev1.PodSpec{Containers: []corev1.Container{
+				registrarContainer("--kubelet-registration-path", "/var/lib/kubelet/plugins/ebs.csi.aws.com/csi.sock")}},
+			want: true,
+		},
+		{
+			name: "registration path referring environment variable",
+			spec: corev1.PodSpec{Containers: []corev1.Container{func() corev1.Container {
+				container := registrarContainer("--kubelet-registration-path=$(DRIVER_REG_SOCK_PATH)")
+				container.Env = []corev1.EnvVar{{Name: "DRIVER_REG_SOCK_PATH",
+					Value: "/var/lib/kubelet/plugins/ebs.csi.aws.com/csi.sock"}}
+				return container
+			}()}},
+			want: true,
+		},
+		{
+			name: "driver name argument of plugin container",
+			spec: corev1.PodSpec{Containers: []corev1.Container{
+				registrarContainer("--kubelet-registration-path=/var/lib/kubelet/plugins/csi-hostpath/csi.sock"),
+				{Name: "hostpath", Args: []string{"--drivername=" + testDriver}},
+			}},
+			want: true,
+		},
+		{
+			name: "driver name environment variable of plugin container",
+			spec: corev1.PodSpec{Containers: []corev1.Container{
+				registrarContainer("--kubelet-registration-path=/var/lib/kubelet/plugins/csi-plugin/csi.sock"),
+				{Name: "plugin", Env: []corev1.EnvVar{{Name: "CSI_DRIVER_NAME", Value: testDriver}}},
+			}},
+			want: true,
+		},
+		{
+			name: "registrar of other driver",
+			spec: corev1.PodSpec{Containers: []corev1.Container{
+				registrarContainer("--kubelet-registration-path=/var/lib/kubelet/plugins/pd.csi.storage.gke.io/csi.sock"),
+				{Name: "plugin", Args: []string{"--endpoint=unix:/csi/csi.sock", "--log-prefix=" + testDriver + "-node"}},
+			}},
+		},
+		{
+			name: "driver name without registrar",
+			spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "plugin", Args: []string{"--drivername=" + testDriver}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := registersCSIDriver(&tt.spec, drivers); got != tt.want {
+				t.Errorf("registersCSIDriver() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func testWorkloadTemplate(app string, containers ...corev1.Container) (*apiv1.LabelSelector, corev1.PodTemplateSpec) {
+	labels := map[string]string{"app": app}
+	return &apiv1.LabelSelector{MatchLabels: labels},
+		corev1.PodTemplateSpec{ObjectMeta: apiv1.ObjectMeta{Labels: labels}, Spec: corev1.PodSpec{Containers: containers}}
+}
+
+func testPod(namespace, name, app string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: apiv1.ObjectMeta{Namespace: namespace, Name: name, Labels: map[string]string{"app": app}}}
+}
+
+func sidecarContainer(sidecar string, args ...string) corev1.Container {
+	return corev1.Container{Name: sidecar, Image: "k8s.gcr.io/sig-storage/" + sidecar + ":v2.1.1", Args: args}
+}
+
+func TestRunsCSIController(t *testing.T) {
+	drivers := sets.NewString(testDriver)
+
+	tests := []struct {
+		name string
+		spec corev1.PodSpec
+		want bool
+	}{
+		{
+			name: "csi address argument",
+			spec: corev1.PodSpec{Containers: []corev1.Container{
+				sidecarContainer("csi-provisioner", "--csi-address=/var/lib/csi/sockets/ebs.csi.aws.com/csi.sock")}},
+			want: true,
+		},
+		{
+			name: "csi address referring environment variable",
+			spec: corev1.PodSpec{Containers: []corev1.Container{func() corev1.Container {
+				container := sidecarContainer("csi-attacher", "--csi-address", "$(ADDRESS)")
+				container.Env = []corev1.EnvVar{{Name: "ADDRESS", Value: "unix:///csi/ebs.csi.aws.com/csi.sock"}}
+				return container
+			}()}},
+			want: true,
+		},
+		{
+			name: "driver name argument of plugin container",
+			spec: corev1.PodSpec{Containers: []corev1.Container{
+				sidecarContainer("csi-provisioner", "--csi-address=/csi/csi.sock"),
+				{Name: "plugin", Args: []string{"controller", "--driver-name=" + testDriver}},
+			}},
+			want: true,
+		},
+		{
+			name: "sidecar of other driver",
+			spec: corev1.PodSpec{Containers: []corev1.Container{
+				sidecarContainer("csi-provisioner", "--csi-address=/var/lib/csi/sockets/efs.csi.aws.com/csi.sock"),
+				{Name: "plugin", Env: []corev1.EnvVar{{Name: "CSI_DRIVER_NAME", Value: testOtherDriver}}},
+			}},
+		},
+		{
+			name: "sidecar without driver reference",
+			spec: corev1.PodSpec{Containers: []corev1.Container{sidecarContainer("csi-resizer", "--csi-address=/csi/csi.sock")}},
+		},
+		{
+			name: "driver name without sidecar",
+			spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "plugin", Args: []string{"--driver-name=" + testDriver}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runsCSIController(&tt.spec, drivers); got != tt.want {
+				t.Errorf("runsCSIController() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCSIDriverPods(t *testing.T) {
+	nodeSelector, nodeTemplate := testWorkloadTemplate("ebs-csi-node",
+		registrarContainer("--kubelet-registration-path=/var/lib/kubelet/plugins/ebs.csi.aws.com/csi.sock"))
+	efsNodeSelector, efsNodeTemplate := testWorkloadTemplate("efs-csi-node",
+		registrarContainer("--kubelet-registration-path=/var/lib/kubelet/plugins/efs.csi.aws.com/csi.sock"))
+	otherSelector, otherTemplate := testWorkloadTemplate("gce-pd-node",
+		registrarContainer("--kubelet-registration-path=/var/lib/kubelet/plugins/pd.csi.storage.gke.io/csi.sock"))
+	controllerSelector, controllerTemplate := testWorkloadTemplate("ebs-csi-controller",
+		sidecarContainer("csi-provisioner", "--csi-address=/var/lib/csi/sockets/ebs.csi.aws.com/csi.sock"))
+	snapshotterSelector, snapshotterTemplate := testWorkloadTemplate("ebs-snapshot-controller",
+		sidecarContainer("csi-snapshotter", "--csi-address=/csi/csi.sock"),
+		corev1.Container{Name: "plugin", Env: []corev1.EnvVar{{Name: "CSI_DRIVER_NAME", Value: testDriver}}})
+	efsControllerSelector, efsControllerTemplate := testWorkloadTemplate("efs-csi-controller",
+		sidecarContainer("csi-provisioner", "--csi-address=/csi/csi.sock"),
+		corev1.Container{Name: "plugin", Args: []string{"--driver-name=" + testOtherDriver}})
+	appSelector, appTemplate := testWorkloadTemplate("web", corev1.Container{Name: "web", Image: "nginx"})
+
+	k8sClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+		&appsv1.DaemonSet{ObjectMeta: apiv1.ObjectMeta{Namespace: "kube-system", Name: "ebs-csi-node"},
+			Spec: appsv1.DaemonSetSpec{Selector: nodeSelector, Template: nodeTemplate}},
+		&appsv1.DaemonSet{ObjectMeta: apiv1.ObjectMeta{Namespace: "kube-system", Name: "efs-csi-node"},
+			Spec: appsv1.DaemonSetSpec{Selector: efsNodeSelector, Template: efsNodeTemplate}},
+		&appsv1.DaemonSet{ObjectMeta: apiv1.ObjectMeta{Namespace: "gce", Name: "gce-pd-node"},
+			Spec: appsv1.DaemonSetSpec{Selector: otherSelector, Template: otherTemplate}},
+		&appsv1.Deployment{ObjectMeta: apiv1.ObjectMeta{Namespace: "kube-system", Name: "ebs-csi-controller"},
+			Spec: appsv1.DeploymentSpec{Selector: controllerSelector, Template: controllerTemplate}},
+		&appsv1.StatefulSet{ObjectMeta: apiv1.ObjectMeta{Namespace: "kube-system", Name: "ebs-snapshot-controller"},
+			Spec: appsv1.StatefulSetSpec{Selector: snapshotterSelector, Template: snapshotterTemplate}},
+		&appsv1.Deployment{ObjectMeta: apiv1.ObjectMeta{Namespace: "kube-system", Name: "efs-csi-controller"},
+			Spec: appsv1.DeploymentSpec{Selector: efsControllerSelector, Template: efsControllerTemplate}},
+		&appsv1.Deployment{ObjectMeta: apiv1.ObjectMeta{Namespace: "kube-system", Name: "web"},
+			Spec: appsv1.DeploymentSpec{Selector: appSelector, Template: appTemplate}},
+		&appsv1.Deployment{ObjectMeta: apiv1.ObjectMeta{Namespace: "gce", Name: "gce-pd-controller"},
+			Spec: appsv1.DeploymentSpec{Selector: controllerSelector, Template: controllerTemplate}},
+		testPod("kube-system", "ebs-csi-node-1", "ebs-csi-node"),
+		testPod("kube-system", "ebs-csi-node-2", "ebs-csi-node"),
+		testPod("kube-system", "ebs-csi-controller-1", "ebs-csi-controller"),
+		testPod("kube-system", "ebs-snapshot-controller-0", "ebs-snapshot-controller"),
+		testPod("kube-system", "efs-csi-node-1", "efs-csi-node"),
+		testPod("kube-system", "efs-csi-controller-1", "efs-csi-controller"),
+		testPod("kube-system", "web-1", "web"),
+		testPod("gce", "gce-pd-node-1", "gce-pd-node"),
+		testPod("gce", "gce-pd-controller-1", "ebs-csi-controller"),
+		testPod("app", "ebs-csi-node-3", "ebs-csi-node"),
+	).Build()
+
+	tests := []struct {
+		name    string
+		drivers sets.String
+		pods    []string
+	}{
+		{
+			name:    "node and controller pods of driver",
+			drivers: sets.NewString(testDriver),
+			pods: []string{"kube-system/ebs-csi-controller-1", "kube-system/ebs-csi-node-1", "kube-system/ebs-csi-node-2",
+				"kube-system/ebs-snapshot-controller-0"},
+		},
+		{
+			name:    "node and controller pods of other driver in same namespace",
+			drivers: sets.NewString(testOtherDriver),
+			pods:    []string{"kube-system/efs-csi-controller-1", "kube-system/efs-csi-node-1"},
+		},
+		{
+			name:    "node and controller pods of both drivers",
+			drivers: sets.NewString(testDriver, testOtherDriver),
+			pods: []string{"kube-system/ebs-csi-controller-1", "kube-system/ebs-csi-node-1", "kube-system/ebs-csi-node-2",
+				"kube-system/ebs-snapshot-controller-0", "kube-system/efs-csi-controller-1", "kube-system/efs-csi-node-1"},
+		},
+		{name: "driver without node plugin", drivers: sets.NewString("nfs.csi.k8s.io")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &LogCollector{k8sClient: k8sClient}
+			pods, err := l.getCSIDriverPods(tt.drivers)
+			if err != nil {
+				t.Fatalf("getCSIDriverPods() error = %v", err)
+			}
+			var names []string
+			for idx := range pods {
+				if pods[idx].GetKind() != Pod {
+					t.Errorf("getCSIDriverPods() returned %s of kind %q", pods[idx].GetName(), pods[idx].GetKind())
+				}
+				names = append(names, types.NamespacedName{Namespace: pods[idx].GetNamespace(), Name: pods[idx].GetName()}.String())
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tt.pods) {
+				t.Errorf("getCSIDriverPods() = %v, want %v", names, tt.pods)
+			}
+		})
+	}
+}
@@ -0,0 +1,359 @@
+package logcollector
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+const (
+	EventsGroup          = "events.k8s.io"
+	PersistentVolumeKind = "PersistentVolume"
+	EventsTimelineFile   = "events-timeline.txt"
+)
+
+var (
+	// eventGroupVersions are group versions events are listed from, in order of preference
+	eventGroupVersions = []string{EventsGroup + "/v1", CoreGv}
+
+	// eventKinds are kinds of involved objects of events by their lowercase name used in names of events files
+	eventKinds = lowercaseKinds(Pod, "Node", "Namespace", PersistentVolumeKind, PersistentVolumeClaimKind, "Service",
+		"ConfigMap", "ServiceAccount", "Endpoints", "Deployment", "ReplicaSet", "StatefulSet", "DaemonSet", "Job",
+		"CronJob", "HorizontalPodAutoscaler", "Ingress", StorageClassKind, VolumeSnapshotClassKind, "VolumeSnapshot",
+		VolumeSnapshotContentKind, VolumeAttachmentKind, CSIDriverKind, CSINodeKind, internal.BackupKind,
+		internal.BackupPlanKind, RestoreKind, internal.TargetKind, LicenseKind, ClusterBackupKind, ClusterRestoreKind,
+		"ClusterBackupPlan", "Policy", "Hook")
+)
+
+// lowercaseKinds returns 'kinds' keyed by their lowercase name
+func lowercaseKinds(kinds ...string) map[string]string {
+	lowercase := make(map[string]string, len(kinds))
+	for _, kind := range kinds {
+		lowercase[strings.ToLower(kind)] = kind
+	}
+	return lowercase
+}
+
+// eventRecord is an event of core/v1 or events.k8s.io/v1 merged with events of the same involved object, type, reason
+// and message
+type eventRecord struct {
+	Type               string                 `json:"type,omitempty"`
+	Reason             string                 `json:"reason,omitempty"`
+	Message            string                 `json:"message,omitempty"`
+	ReportingComponent string                 `json:"reportingComponent,omitempty"`
+	Count              int64                  `json:"count"`
+	FirstTimestamp     apiv1.Time             `json:"firstTimestamp"`
+	LastTimestamp      apiv1.Time             `json:"lastTimestamp"`
+	InvolvedObject     corev1.ObjectReference `json:"involvedObject"`
+}
+
+// newEventRecord returns record of core/v1 or events.k8s.io/v1 'event'
+func newEventRecord(event *unstructured.Unstructured) *eventRecord {
+	involvedField := "involvedObject"
+	if _, ok := event.Object["regarding"]; ok {
+		involvedField = "regarding"
+	}
+	involved, _, _ := unstructured.NestedStringMap(event.Object, involvedField)
+
+	record := &eventRecord{
+		Type:           nestedStringOf(event, []string{"type"}),
+		Reason:         nestedStringOf(event, []string{"reason"}),
+		Message:        nestedStringOf(event, []string{"message"}, []string{"note"}),
+		Count:          1,
+		FirstTimestamp: apiv1.NewTime(eventFirstTime(event)),
+		LastTimestamp:  apiv1.NewTime(eventTime(event)),
+		InvolvedObject: corev1.ObjectReference{APIVersion: involved["apiVersion"], Kind: involved["kind"],
+			Namespace: involved["namespace"], Name: involved["name"]},
+	}
+	if record.FirstTimestamp.IsZero() {
+		record.FirstTimestamp = record.LastTimestamp
+	}
+	record.ReportingComponent = nestedStringOf(event, []string{"reportingController"}, []string{"reportingComponent"},
+		[]string{"source", "component"}, []string{"deprecatedSource", "component"})
+	for _, field := range [][]string{{"series", "count"}, {"count"}, {"deprecatedCount"}} {
+		if count := nestedInt64(event.Object, field...); count > 0 {
+			record.Count = count
+			break
+		}
+	}
+	return record
+}
+
+// merge merges count and timestamps of repeated event 'other' into record
+func (r *eventRecord) merge(other *eventRecord) {
+	r.Count += other.Count
+	if other.FirstTimestamp.Before(&r.FirstTimestamp) {
+		r.FirstTimestamp = other.FirstTimestamp
+	}
+	if r.LastTimestamp.Before(&other.LastTimestamp) {
+		r.LastTimestamp = other.LastTimestamp
+		r.ReportingComponent = other.ReportingComponent
+	}
+}
+
+// involvedKey returns key of involved object of record
+func (r *eventRecord) involvedKey() string {
+	return objectKey(r.InvolvedObject.Kind, r.InvolvedObject.Namespace, r.InvolvedObject.Name)
+}
+
+// objectKey returns key identifying object of 'kind' by its namespace and name
+func objectKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// findEventResource returns events resource of the most preferred group version served by cluster
+func findEventResource(resourceGroup map[string][]apiv1.APIResource) (groupVersionResource, bool) {
+	for _, groupVersion := range eventGroupVersions {
+		for idx := range resourceGroup[groupVersion] {
+			if resourceGroup[groupVersion][idx].Name == Events {
+				return groupVersionResource{groupVersion: groupVersion, resource: resourceGroup[groupVersion][idx]}, true
+			}
+		}
+	}
+	return groupVersionResource{}, false
+}
+
+// collectEvents writes events of collected objects, Trilio resources and persistent volumes of collected PVCs,
+// deduplicated by involved object, type, reason and message, along with a timeline of all of them
+func (l *LogCollector) collectEvents(resourceGroup map[string][]apiv1.APIResource) error {
+	eventResource, ok := findEventResource(resourceGroup)
+	if !ok {
+		l.logger().Warn("Events resource not found in the cluster")
+		return nil
+	}
+	if l.selection.isExcluded(eventResource.resource.Kind) {
+		l.logger().Infof("Skipping excluded '%s' Resource", eventResource.resource.Kind)
+		return nil
+	}
+
+	l.logger().Infof("Fetching '%s' Resource of %s", eventResource.resource.Kind, eventResource.groupVersion)
+	eventObjects, err := l.getEventObjects(&eventResource)
+	if err != nil {
+		l.logger().Errorf("Unable to Write Events : %s", err.Error())
+		return l.handleError(eventResource.resource.Name, "", err)
+	}
+	l.filterNewEvents(&eventObjects)
+
+	records := l.relatedEvents(&eventObjects)
+	if err = l.writeEvents(records); err == nil {
+		err = l.writeEventsTimeline(records)
+	}
+	if err != nil {
+		l.logger().Errorf("Unable to Write Events : %s", err.Error())
+		return l.handleError(eventResource.resource.Name, "", err)
+	}
+	return nil
+}
+
+// getEventObjects lists events of collected namespaces. Events of cluster scoped objects are reported in default
+// namespace which is listed as well, failure of listing it is only warned about.
+func (l *LogCollector) getEventObjects(eventResource *groupVersionResource) (unstructured.UnstructuredList, error) {
+	resourcePath := getAPIGroupVersionResourcePath(eventResource.groupVersion)
+	objects, err := l.getResourceObjects(resourcePath, &eventResource.resource)
+	if err != nil || l.Clustered || sets.NewString(l.Namespaces...).Has(apiv1.NamespaceDefault) {
+		return objects, err
+	}
+
+	var clusterEvents unstructured.UnstructuredList
+	listPath := fmt.Sprintf("%s/namespaces/%s/%s", resourcePath, apiv1.NamespaceDefault, eventResource.resource.Name)
+	if lErr := l.disClient.RESTClient().Get().AbsPath(listPath).Do(context.TODO()).Into(&clusterEvents); lErr != nil {
+		l.logger().Warnf("Unable to list events of cluster scoped objects in namespace %s : %s", apiv1.NamespaceDefault,
+			lErr.Error())
+		return objects, nil
+	}
+	objects.Items = append(objects.Items, clusterEvents.Items...)
+	return objects, nil
+}
+
+// relatedEvents returns records of events involving Trilio resources, collected objects or persistent volumes of
+// collected PVCs, sorted by time they were last seen
+func (l *LogCollector) relatedEvents(eventObjects *unstructured.UnstructuredList) []*eventRecord {
+	collected := l.objectManifest.keys()
+	volumes := l.csi.persistentVolumes()
+
+	merged := make(map[string]*eventRecord)
+	for idx := range eventObjects.Items {
+		record := newEventRecord(&eventObjects.Items[idx])
+		if !strings.HasPrefix(record.InvolvedObject.APIVersion, internal.TriliovaultGroup) &&
+			!collected.Has(record.involvedKey()) &&
+			!(record.InvolvedObject.Kind == PersistentVolumeKind && volumes.Has(record.InvolvedObject.Name)) {
+			continue
+		}
+
+		key := strings.Join([]string{record.involvedKey(), record.Type, record.Reason, record.Message}, "/")
+		if existing, ok := merged[key]; ok {
+			existing.merge(record)
+			continue
+		}
+		merged[key] = record
+	}
+
+	records := make([]*eventRecord, 0, len(merged))
+	for _, record := range merged {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].LastTimestamp.Equal(&records[j].LastTimestamp) {
+			return records[i].LastTimestamp.Before(&records[j].LastTimestamp)
+		}
+		if records[i].involvedKey() != records[j].involvedKey() {
+			return records[i].involvedKey() < records[j].involvedKey()
+		}
+		return records[i].Reason < records[j].Reason
+	})
+	l.logger().Infof("%d events of %d are related to collected objects", len(records), len(eventObjects.Items))
+	return records
+}
+
+// writeEvents writes events of each involved object to 'Events/<namespace>/<kind>.<name>.yaml', events of cluster
+// scoped objects to 'Events/<kind>.<name>.yaml'
+func (l *LogCollector) writeEvents(records []*eventRecord) error {
+	objectEvents := make(map[string][]*eventRecord)
+	for _, record := range records {
+		eventsFile := filepath.Join(eventsDir, record.InvolvedObject.Namespace,
+			strings.ToLower(record.InvolvedObject.Kind)+"."+record.InvolvedObject.Name+".yaml")
+		objectEvents[eventsFile] = append(objectEvents[eventsFile], record)
+	}
+
+	for eventsFile, events := range objectEvents {
+		buf, err := yaml.Marshal(events)
+		if err != nil {
+			l.logger().Errorf("Unable to marshal the content : %s", err.Error())
+			return err
+		}
+		if err = l.writeFile(eventsFile, l.redactor.redactBytes(buf)); err != nil {
+			return err
+		}
+		l.summary.addObjects(EventKind, len(events))
+	}
+	return nil
+}
+
+// writeEventsTimeline writes events of all involved objects in order of time they were last seen
+func (l *LogCollector) writeEventsTimeline(records []*eventRecord) error {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "LAST SEEN\tFIRST SEEN\tCOUNT\tTYPE\tREASON\tOBJECT\tMESSAGE")
+	for _, record := range records {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n", record.LastTimestamp.UTC().Format(time.RFC3339),
+			record.FirstTimestamp.UTC().Format(time.RFC3339), record.Count, dashIfEmpty(record.Type),
+			dashIfEmpty(record.Reason), referenceName(&record.InvolvedObject),
+			strings.Join(strings.Fields(record.Message), " "))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	return l.writeFile(EventsTimelineFile, l.redactor.redactBytes(buf.Bytes()))
+}
+
+// referenceName returns kind and namespaced name of referred object
+func referenceName(ref *corev1.ObjectReference) string {
+	if ref.Namespace == "" {
+		return fmt.Sprintf("%s/%s", strings.ToLower(ref.Kind), ref.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", strings.ToLower(ref.Kind), ref.Namespace, ref.Name)
+}
+
+// parseBundleEvents parses events file of bundle at 'name', 'Events/<namespace>/<kind>.<name>.yaml' or
+// 'Events/<kind>.<name>.yaml'. Involved object is read from events, bundles of earlier versions store events without
+// it and it is derived from path with lowercase kind mapped back to kind. Nil is returned for files which are not
+// events files.
+func parseBundleEvents(name string, content []byte) ([]*unstructured.Unstructured, error) {
+	parts := strings.Split(name, "/")
+	var namespace, file string
+	switch {
+	case len(parts) >= 3 && parts[len(parts)-3] == eventsDir:
+		namespace, file = parts[len(parts)-2], parts[len(parts)-1]
+	case len(parts) >= 2 && parts[len(parts)-2] == eventsDir:
+		file = parts[len(parts)-1]
+	default:
+		return nil, nil
+	}
+	if !strings.HasSuffix(file, ".yaml") {
+		return nil, nil
+	}
+
+	var value interface{}
+	if err := yaml.Unmarshal(content, &value); err != nil {
+		return nil, err
+	}
+	// bundles of earlier versions store a single event in events file
+	items, ok := value.([]interface{})
+	if !ok {
+		items = []interface{}{value}
+	}
+
+	involved := strings.SplitN(strings.TrimSuffix(file, ".yaml"), ".", 2)
+	kind, known := eventKinds[involved[0]]
+	if !known {
+		kind = involved[0]
+	}
+	var events []*unstructured.Unstructured
+	for _, item := range items {
+		eventObject, isMap := item.(map[string]interface{})
+		if !isMap {
+			continue
+		}
+		event := &unstructured.Unstructured{Object: eventObject}
+		if involvedKind, _, _ := unstructured.NestedString(eventObject, "involvedObject", "kind"); involvedKind == "" {
+			involvedObject := map[string]interface{}{"namespace": namespace, "kind": kind}
+			if len(involved) == 2 {
+				involvedObject["name"] = involved[1]
+			}
+			event.Object["involvedObject"] = involvedObject
+		}
+		event.SetAPIVersion(CoreGv)
+		event.SetKind(EventKind)
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// nestedStringOf returns the first non empty string of 'fields' of 'obj'
+func nestedStringOf(obj *unstructured.Unstructured, fields ...[]string) string {
+	for _, field := range fields {
+		if value, _, _ := unstructured.NestedString(obj.Object, field...); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// nestedTimeOf returns the first time of 'fields' of 'obj', falling back to its creation time
+func nestedTimeOf(obj *unstructured.Unstructured, fields ...[]string) time.Time {
+	for _, field := range fields {
+		value, _, _ := unstructured.NestedString(obj.Object, field...)
+		if value == "" {
+			continue
+		}
+		if parsed, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return parsed
+		}
+	}
+	return obj.GetCreationTimestamp().Time
+}
+
+// eventTime returns last time event was seen, falling back to event time and creation time
+func eventTime(event *unstructured.Unstructured) time.Time {
+	return nestedTimeOf(event, []string{"series", "lastObservedTime"}, []string{"lastTimestamp"},
+		[]string{"deprecatedLastTimestamp"}, []string{"eventTime"})
+}
+
+// eventFirstTime returns first time event was seen, falling back to event time and creation time
+func eventFirstTime(event *unstructured.Unstructured) time.Time {
+	return nestedTimeOf(event, []string{"firstTimestamp"}, []string{"deprecatedFirstTimestamp"},
+		[]string{"eventTime"})
+}
@@ -0,0 +1,147 @@
+package logcollector
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNewEventRecord(t *testing.T) {
+	first := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	last := first.Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		event  map[string]interface{}
+		record eventRecord
+	}{
+		{
+			name: "core event",
+			event: map[string]interface{}{"type": "Warning", "reason": "BackOff", "message": "restarting", "count": int64(3),
+				"firstTimestamp": first.Format(time.RFC3339), "lastTimestamp": last.Format(time.RFC3339),
+				"source":         map[string]interface{}{"component": "kubelet"},
+				"involvedObject": map[string]interface{}{"apiVersion": "v1", "kind": Pod, "namespace": "app", "name": "web"}},
+			record: eventRecord{Type: "Warning", Reason: "BackOff", Message: "restarting", ReportingComponent: "kubelet",
+				Count: 3, FirstTimestamp: apiv1.NewTime(first), LastTimestamp: apiv1.NewTime(last),
+				InvolvedObject: corev1.ObjectReference{APIVersion: "v1", Kind: Pod, Namespace: "app", Name: "web"}},
+		},
+		{
+			name: "events.k8s.io event",
+			event: map[string]interface{}{"type": "Normal", "reason": "Provisioned", "note": "volume created",
+				"eventTime": first.Format(time.RFC3339), "reportingController": "ebs.csi.aws.com",
+				"series":    map[string]interface{}{"count": int64(2), "lastObservedTime": last.Format(time.RFC3339)},
+				"regarding": map[string]interface{}{"apiVersion": "v1", "kind": PersistentVolumeKind, "name": "pv-1"}},
+			record: eventRecord{Type: "Normal", Reason: "Provisioned", Message: "volume created",
+				ReportingComponent: "ebs.csi.aws.com", Count: 2, FirstTimestamp: apiv1.NewTime(first),
+				LastTimestamp:  apiv1.NewTime(last),
+				InvolvedObject: corev1.ObjectReference{APIVersion: "v1", Kind: PersistentVolumeKind, Name: "pv-1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := newEventRecord(&unstructured.Unstructured{Object: tt.event})
+			if !reflect.DeepEqual(*record, tt.record) {
+				t.Errorf("newEventRecord() = %+v, want %+v", *record, tt.record)
+			}
+		})
+	}
+}
+
+func TestParseBundleEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		involved []map[string]interface{}
+		wantErr  bool
+	}{
+		{
+			name: "events with involved object",
+			file: "Events/app/pod.web.yaml",
+			content: "- reason: BackOff\n  involvedObject:\n    apiVersion: v1\n    kind: Pod\n    namespace: app\n" +
+				"    name: web\n- reason: Pulled\n  involvedObject:\n    apiVersion: v1\n    kind: Pod\n" +
+				"    namespace: app\n    name: web\n",
+			involved: []map[string]interface{}{
+				{"apiVersion": "v1", "kind": Pod, "namespace": "app", "name": "web"},
+				{"apiVersion": "v1", "kind": Pod, "namespace": "app", "name": "web"},
+			},
+		},
+		{
+			name:     "events of earlier version without involved object",
+			file:     "clusters/prod/Events/app/persistentvolumeclaim.data.web-0.yaml",
+			content:  "- reason: ProvisioningSucceeded\n",
+			involved: []map[string]interface{}{{"kind": PersistentVolumeClaimKind, "namespace": "app", "name": "data.web-0"}},
+		},
+		{
+			name:     "single event of cluster scoped object",
+			file:     "Events/node.worker-1.yaml",
+			content:  "reason: NodeNotReady\n",
+			involved: []map[string]interface{}{{"kind": "Node", "namespace": "", "name": "worker-1"}},
+		},
+		{
+			name:     "unknown kind",
+			file:     "Events/app/widget.w1.yaml",
+			content:  "- reason: Synced\n",
+			involved: []map[string]interface{}{{"kind": "widget", "namespace": "app", "name": "w1"}},
+		},
+		{name: "object file", file: "Pod/app/web.yaml", content: "kind: Pod\n"},
+		{name: "events timeline", file: "Events/timeline.txt", content: "LAST SEEN"},
+		{name: "invalid events file", file: "Events/app/pod.web.yaml", content: "- [", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := parseBundleEvents(tt.file, []byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBundleEvents() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			var involved []map[string]interface{}
+			for _, event := range events {
+				if event.GetKind() != EventKind || event.GetAPIVersion() != CoreGv {
+					t.Errorf("parseBundleEvents() returned %s of %s", event.GetKind(), event.GetAPIVersion())
+				}
+				object, _, _ := unstructured.NestedMap(event.Object, "involvedObject")
+				involved = append(involved, object)
+			}
+			if !reflect.DeepEqual(involved, tt.involved) {
+				t.Errorf("parseBundleEvents() involved objects = %v, want %v", involved, tt.involved)
+			}
+		})
+	}
+}
+
+func TestWriteEventsRoundTrip(t *testing.T) {
+	redactor, err := newRedactor(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	l := &LogCollector{bundle: NewDirSink(dir), redactor: redactor}
+
+	involved := corev1.ObjectReference{APIVersion: "triliovault.trilio.io/v1", Kind: RestoreKind, Namespace: "app",
+		Name: "restore-1"}
+	err = l.writeEvents([]*eventRecord{{Type: eventTypeWarning, Reason: "Failed", Count: 1, InvolvedObject: involved}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := filepath.ToSlash(filepath.Join(eventsDir, "app", "restore.restore-1.yaml"))
+	content, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := parseBundleEvents(name, content)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("parseBundleEvents() = %v, %v, want 1 event", events, err)
+	}
+	if record := newEventRecord(events[0]); record.InvolvedObject != involved {
+		t.Errorf("involved object of written event = %+v, want %+v", record.InvolvedObject, involved)
+	}
+}
@@ -0,0 +1,279 @@
+package logcollector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apiv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+const (
+	// BundleServerPort is the port bundles are served on by helper pod started by fetch
+	BundleServerPort = 8080
+	// ServeCmdName is the command of log-collector serving bundles in helper pod
+	ServeCmdName = "serve"
+
+	fetchPodSuffix = "-fetch"
+)
+
+// bundleFile is a bundle listed by bundle server
+type bundleFile struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// ServeBundles serves bundles in 'dir' over HTTP on 'port'. Root lists bundles as JSON, bundles are served at
+// their names.
+func ServeBundles(dir string, port int) error {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Base(r.URL.Path)
+		if r.URL.Path == "/" {
+			bundles, err := listBundles(dir)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(bundles)
+			return
+		}
+		http.ServeFile(w, r, filepath.Join(dir, name))
+	})
+
+	log.Infof("Serving bundles of %s on port %d", dir, port)
+	return http.ListenAndServe(":"+strconv.Itoa(port), handler)
+}
+
+// listBundles returns zip and tar.gz archives in 'dir', latest first
+func listBundles(dir string) ([]bundleFile, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var bundles []bundleFile
+	for _, entry := range entries {
+		if entry.IsDir() || !(strings.HasSuffix(entry.Name(), "."+FormatZip) ||
+			strings.HasSuffix(entry.Name(), "."+FormatTarGz)) {
+			continue
+		}
+		bundles = append(bundles, bundleFile{Name: entry.Name(), Size: entry.Size(), ModTime: entry.ModTime()})
+	}
+	sort.Slice(bundles, func(i, j int) bool {
+		return bundles[i].ModTime.After(bundles[j].ModTime)
+	})
+	return bundles, nil
+}
+
+// BundleFetcher copies bundles of log-collector run in cluster by InClusterDeployment from its PVC
+type BundleFetcher struct {
+	Name       string
+	Namespace  string
+	KubeConfig string
+	// Bundle is the name of bundle to fetch, latest bundle is fetched if empty
+	Bundle    string
+	OutputDir string
+}
+
+// Fetch starts a pod serving bundles of PVC, copies bundle to output directory through API server proxy and
+// returns path of fetched bundle. Helper pod is deleted once done.
+func (f *BundleFetcher) Fetch() (string, error) {
+	acc, err := newAccessor(f.KubeConfig, "")
+	if err != nil {
+		return "", err
+	}
+	clientSet := acc.GetClientset()
+	ctx := context.Background()
+
+	image, err := f.collectorImage(ctx, clientSet)
+	if err != nil {
+		return "", err
+	}
+
+	deployment := InClusterDeployment{Name: f.Name, Namespace: f.Namespace}
+	pod := f.serverPod(image, deployment.claimName(), deployment.labels())
+	if err = f.startServerPod(ctx, clientSet, pod); err != nil {
+		return "", err
+	}
+	defer func() {
+		if dErr := clientSet.CoreV1().Pods(f.Namespace).Delete(ctx, pod.Name, apiv1.DeleteOptions{}); dErr != nil {
+			log.Warnf("Unable to delete pod %s : %s", pod.Name, dErr.Error())
+		}
+	}()
+
+	bundle, err := f.selectBundle(ctx, clientSet, pod.Name)
+	if err != nil {
+		return "", err
+	}
+	return f.download(ctx, clientSet, pod.Name, bundle)
+}
+
+// collectorImage returns image of Jobs of deployment, failing if any of them is still running
+func (f *BundleFetcher) collectorImage(ctx context.Context, clientSet *kubernetes.Clientset) (string, error) {
+	jobs, err := clientSet.BatchV1().Jobs(f.Namespace).List(ctx, apiv1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(map[string]string{instanceLabel: f.Name}).String(),
+	})
+	if err != nil {
+		log.Errorf("Unable to list Jobs : %s", err.Error())
+		return "", err
+	}
+	if len(jobs.Items) == 0 {
+		return "", fmt.Errorf("no log-collector Job %s found in namespace %s", f.Name, f.Namespace)
+	}
+
+	var latest *batchv1.Job
+	for idx := range jobs.Items {
+		job := &jobs.Items[idx]
+		if job.Status.Active > 0 {
+			return "", fmt.Errorf("log-collector Job %s is still running, fetch once it completes", job.Name)
+		}
+		if latest == nil || job.CreationTimestamp.After(latest.CreationTimestamp.Time) {
+			latest = job
+		}
+	}
+	return latest.Spec.Template.Spec.Containers[0].Image, nil
+}
+
+// serverPod returns pod running log-collector 'image' to serve bundles of PVC 'claimName'
+func (f *BundleFetcher) serverPod(image, claimName string, podLabels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: apiv1.ObjectMeta{Name: f.Name + fetchPodSuffix, Namespace: f.Namespace, Labels: podLabels},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{{
+				Name:  collectorContainer,
+				Image: image,
+				Args:  []string{ServeCmdName, "--dir", BundlesMountPath, "--port", strconv.Itoa(BundleServerPort)},
+				Ports: []corev1.ContainerPort{{ContainerPort: BundleServerPort}},
+				ReadinessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						HTTPGet: &corev1.HTTPGetAction{Path: "/", Port: intstr.FromInt(BundleServerPort)},
+					},
+				},
+				VolumeMounts: []corev1.VolumeMount{{Name: bundlesVolume, MountPath: BundlesMountPath, ReadOnly: true}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: bundlesVolume,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName, ReadOnly: true},
+				},
+			}},
+		},
+	}
+}
+
+// startServerPod creates 'pod', replacing one left by an earlier fetch, and waits till it is ready
+func (f *BundleFetcher) startServerPod(ctx context.Context, clientSet *kubernetes.Clientset, pod *corev1.Pod) error {
+	pods := clientSet.CoreV1().Pods(f.Namespace)
+	err := pods.Delete(ctx, pod.Name, apiv1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	log.Infof("Starting pod %s to serve bundles", pod.Name)
+	err = waitFor(func() (bool, error) {
+		_, cErr := pods.Create(ctx, pod, apiv1.CreateOptions{})
+		if apierrors.IsAlreadyExists(cErr) {
+			return false, nil
+		}
+		return true, cErr
+	})
+	if err != nil {
+		log.Errorf("Unable to create pod %s : %s", pod.Name, err.Error())
+		return err
+	}
+
+	return waitFor(func() (bool, error) {
+		current, gErr := pods.Get(ctx, pod.Name, apiv1.GetOptions{})
+		if gErr != nil {
+			return false, gErr
+		}
+		if current.Status.Phase == corev1.PodFailed || current.Status.Phase == corev1.PodSucceeded {
+			return false, fmt.Errorf("pod %s serving bundles exited with phase %s", pod.Name, current.Status.Phase)
+		}
+		for idx := range current.Status.Conditions {
+			if current.Status.Conditions[idx].Type == corev1.PodReady {
+				return current.Status.Conditions[idx].Status == corev1.ConditionTrue, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// selectBundle returns bundle to fetch, latest bundle if none is given
+func (f *BundleFetcher) selectBundle(ctx context.Context, clientSet *kubernetes.Clientset, podName string) (string,
+	error) {
+	content, err := clientSet.CoreV1().Pods(f.Namespace).ProxyGet(internal.HTTPscheme, podName,
+		strconv.Itoa(BundleServerPort), "/", nil).DoRaw(ctx)
+	if err != nil {
+		log.Errorf("Unable to list bundles : %s", err.Error())
+		return "", err
+	}
+	var bundles []bundleFile
+	if err = json.Unmarshal(content, &bundles); err != nil {
+		return "", err
+	}
+	if len(bundles) == 0 {
+		return "", fmt.Errorf("no bundles found on PVC of %s", f.Name)
+	}
+
+	if f.Bundle == "" {
+		return bundles[0].Name, nil
+	}
+	for idx := range bundles {
+		if bundles[idx].Name == f.Bundle {
+			return f.Bundle, nil
+		}
+	}
+	return "", fmt.Errorf("bundle %s not found on PVC of %s", f.Bundle, f.Name)
+}
+
+// download copies 'bundle' served by pod to output directory
+func (f *BundleFetcher) download(ctx context.Context, clientSet *kubernetes.Clientset, podName, bundle string) (string,
+	error) {
+	reader, err := clientSet.CoreV1().Pods(f.Namespace).ProxyGet(internal.HTTPscheme, podName,
+		strconv.Itoa(BundleServerPort), bundle, nil).Stream(ctx)
+	if err != nil {
+		log.Errorf("Unable to fetch bundle %s : %s", bundle, err.Error())
+		return "", err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	outputPath := filepath.Join(f.OutputDir, bundle)
+	file, err := os.Create(outputPath)
+	if err != nil {
+		log.Errorf("Error Creating bundle File : %s", err.Error())
+		return "", err
+	}
+	if _, err = io.Copy(file, reader); err != nil {
+		_ = file.Close()
+		log.Errorf("Unable to fetch bundle %s : %s", bundle, err.Error())
+		return "", err
+	}
+	if err = file.Close(); err != nil {
+		return "", err
+	}
+	return outputPath, nil
+}
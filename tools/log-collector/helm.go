@@ -0,0 +1,198 @@
+package logcollector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apiv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	HelmReleaseKind = "HelmRelease"
+
+	// helmReleasesDir is separate from HelmRelease kind directory so that it doesn't mix with HelmRelease CRs
+	helmReleasesDir       = "HelmReleases"
+	helmReleaseSecretType = "helm.sh/release.v1"
+	helmOwnerSelector     = "owner=helm"
+	helmReleaseKey        = "release"
+	helmHistoryFile       = "history.yaml"
+	helmValuesFile        = "values.yaml"
+
+	// tvkChartName is part of name of TVK operator and TVK charts
+	tvkChartName = "triliovault"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b, 0x08}
+
+// helmRelease is a revision of helm release decoded from its secret
+type helmRelease struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Info      struct {
+		FirstDeployed string `json:"first_deployed"`
+		LastDeployed  string `json:"last_deployed"`
+		Description   string `json:"description"`
+		Status        string `json:"status"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+		} `json:"metadata"`
+	} `json:"chart"`
+	// Config is the user supplied values of revision
+	Config map[string]interface{} `json:"config"`
+}
+
+// helmRevision is a revision of release written to history
+type helmRevision struct {
+	Revision     int    `json:"revision"`
+	Status       string `json:"status"`
+	Chart        string `json:"chart"`
+	ChartVersion string `json:"chartVersion"`
+	AppVersion   string `json:"appVersion"`
+	Updated      string `json:"updated"`
+	Description  string `json:"description"`
+}
+
+// collectHelmReleases decodes helm release secrets of TVK charts and writes history and user supplied values of
+// latest revision of each release. Secrets are not written to bundle.
+func (l *LogCollector) collectHelmReleases() error {
+	if l.selection.isExcluded(HelmReleaseKind) {
+		l.logger().Infof("Skipping excluded '%s' Resource", HelmReleaseKind)
+		return nil
+	}
+	l.logger().Infof("Fetching '%s' Resource", HelmReleaseKind)
+
+	namespaces := l.Namespaces
+	if l.Clustered {
+		namespaces = []string{apiv1.NamespaceAll}
+	}
+
+	releases := make(map[string][]*helmRelease)
+	for _, namespace := range namespaces {
+		secrets, err := l.k8sClientSet.CoreV1().Secrets(namespace).List(context.Background(),
+			apiv1.ListOptions{LabelSelector: helmOwnerSelector})
+		if err != nil {
+			l.logger().Errorf("Unable to list helm release secrets : %s", err.Error())
+			if hErr := l.handleError(HelmReleaseKind, namespace, err); hErr != nil {
+				return hErr
+			}
+			continue
+		}
+
+		for idx := range secrets.Items {
+			release, dErr := decodeHelmRelease(&secrets.Items[idx])
+			if dErr != nil {
+				l.logger().Warnf("Unable to decode helm release secret %s/%s : %s", secrets.Items[idx].Namespace,
+					secrets.Items[idx].Name, dErr.Error())
+				continue
+			}
+			if release == nil || !strings.Contains(release.Chart.Metadata.Name, tvkChartName) {
+				continue
+			}
+			key := path.Join(release.Namespace, release.Name)
+			releases[key] = append(releases[key], release)
+		}
+	}
+
+	for key, revisions := range releases {
+		if err := l.writeHelmRelease(key, revisions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeHelmRelease writes history of all 'revisions' of release and user supplied values of latest revision
+func (l *LogCollector) writeHelmRelease(key string, revisions []*helmRelease) error {
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Version < revisions[j].Version
+	})
+
+	history := make([]helmRevision, 0, len(revisions))
+	for _, release := range revisions {
+		history = append(history, helmRevision{
+			Revision:     release.Version,
+			Status:       release.Info.Status,
+			Chart:        release.Chart.Metadata.Name,
+			ChartVersion: release.Chart.Metadata.Version,
+			AppVersion:   release.Chart.Metadata.AppVersion,
+			Updated:      release.Info.LastDeployed,
+			Description:  release.Info.Description,
+		})
+	}
+	buf, err := yaml.Marshal(history)
+	if err != nil {
+		l.logger().Errorf("Unable to marshal the content : %s", err.Error())
+		return err
+	}
+	releaseDir := path.Join(helmReleasesDir, key)
+	if err = l.writeFile(path.Join(releaseDir, helmHistoryFile), buf); err != nil {
+		return err
+	}
+
+	latest := revisions[len(revisions)-1]
+	values := latest.Config
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	redactSensitiveValues(values)
+	buf, err = yaml.Marshal(values)
+	if err != nil {
+		l.logger().Errorf("Unable to marshal the content : %s", err.Error())
+		return err
+	}
+	if err = l.writeFile(path.Join(releaseDir, helmValuesFile), l.redactor.redactBytes(buf)); err != nil {
+		return err
+	}
+
+	l.logger().Infof("Collected helm release %s of chart %s-%s", key, latest.Chart.Metadata.Name,
+		latest.Chart.Metadata.Version)
+	l.summary.addObjects(HelmReleaseKind, 1)
+	return nil
+}
+
+// decodeHelmRelease decodes release stored in helm secret as base64 encoded, gzipped JSON. Nil is returned if
+// 'secret' is not a helm release.
+func decodeHelmRelease(secret *corev1.Secret) (*helmRelease, error) {
+	data, ok := secret.Data[helmReleaseKey]
+	if secret.Type != helmReleaseSecretType || !ok {
+		return nil, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(decoded, gzipMagic) {
+		reader, gErr := gzip.NewReader(bytes.NewReader(decoded))
+		if gErr != nil {
+			return nil, gErr
+		}
+		defer reader.Close()
+		if decoded, err = ioutil.ReadAll(reader); err != nil {
+			return nil, err
+		}
+	}
+
+	var release helmRelease
+	if err = json.Unmarshal(decoded, &release); err != nil {
+		return nil, err
+	}
+	if release.Namespace == "" {
+		release.Namespace = secret.Namespace
+	}
+	return &release, nil
+}
@@ -0,0 +1,132 @@
+package logcollector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apiv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const testRelease = `{"name":"tvk","version":2,"info":{"status":"deployed","last_deployed":"2021-06-01T10:00:00Z"},` +
+	`"chart":{"metadata":{"name":"k8s-triliovault-operator","version":"2.1.0","appVersion":"2.1.0"}},` +
+	`"config":{"installTVK":{"enabled":true}}}`
+
+func helmSecret(release string, compress bool) *corev1.Secret {
+	data := []byte(release)
+	if compress {
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		_, _ = writer.Write(data)
+		_ = writer.Close()
+		data = buf.Bytes()
+	}
+	return &corev1.Secret{
+		ObjectMeta: apiv1.ObjectMeta{Namespace: "tvk", Name: "sh.helm.release.v1.tvk.v2"},
+		Type:       helmReleaseSecretType,
+		Data:       map[string][]byte{helmReleaseKey: []byte(base64.StdEncoding.EncodeToString(data))},
+	}
+}
+
+func TestDecodeHelmRelease(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  *corev1.Secret
+		release bool
+		wantErr bool
+	}{
+		{name: "gzipped release", secret: helmSecret(testRelease, true), release: true},
+		{name: "uncompressed release", secret: helmSecret(testRelease, false), release: true},
+		{name: "other secret type", secret: &corev1.Secret{Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{helmReleaseKey: []byte("value")}}},
+		{name: "release key missing", secret: &corev1.Secret{Type: helmReleaseSecretType}},
+		{name: "invalid base64", secret: &corev1.Secret{Type: helmReleaseSecretType,
+			Data: map[string][]byte{helmReleaseKey: []byte("%%")}}, wantErr: true},
+		{name: "invalid release", secret: helmSecret("{", true), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release, err := decodeHelmRelease(tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeHelmRelease() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if (release != nil) != tt.release {
+				t.Fatalf("decodeHelmRelease() = %+v, want release %t", release, tt.release)
+			}
+			if release == nil {
+				return
+			}
+			if release.Name != "tvk" || release.Namespace != "tvk" || release.Version != 2 ||
+				release.Info.Status != "deployed" || release.Chart.Metadata.Version != "2.1.0" {
+				t.Errorf("decodeHelmRelease() = %+v", release)
+			}
+		})
+	}
+}
+
+func TestWriteHelmRelease(t *testing.T) {
+	first, err := decodeHelmRelease(helmSecret(strings.Replace(testRelease, `"version":2`, `"version":1`, 1), true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := decodeHelmRelease(helmSecret(strings.Replace(testRelease, `{"installTVK":{"enabled":true}}`,
+		`{"password":"hunter2","db":{"dbPassword":"hunter2","host":"db"},"s3":[{"secretKey":"abcd1234",`+
+			`"bucket":"b"}],"oauth":{"clientSecret":{"value":"abc"},"tokenSecret":"oauth-token"}}`, 1), true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	redactor, err := newRedactor(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := &LogCollector{bundle: NewDirSink(dir), redactor: redactor}
+	if err = l.writeHelmRelease("tvk/tvk", []*helmRelease{latest, first}); err != nil {
+		t.Fatal(err)
+	}
+
+	releaseDir := filepath.Join(dir, helmReleasesDir, "tvk", "tvk")
+	content, err := ioutil.ReadFile(filepath.Join(releaseDir, helmHistoryFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var history []helmRevision
+	if err = yaml.Unmarshal(content, &history); err != nil {
+		t.Fatal(err)
+	}
+	var revisions []int
+	for idx := range history {
+		revisions = append(revisions, history[idx].Revision)
+	}
+	if !reflect.DeepEqual(revisions, []int{1, 2}) {
+		t.Errorf("%s revisions = %v, want [1 2]", helmHistoryFile, revisions)
+	}
+
+	values, err := ioutil.ReadFile(filepath.Join(releaseDir, helmValuesFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `db:
+  dbPassword: REDACTED
+  host: db
+oauth:
+  clientSecret: REDACTED
+  tokenSecret: oauth-token
+password: REDACTED
+s3:
+- bucket: b
+  secretKey: REDACTED
+`
+	if string(values) != want {
+		t.Errorf("%s = %q, want %q", helmValuesFile, values, want)
+	}
+}
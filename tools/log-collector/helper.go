@@ -1,16 +1,18 @@
 package logcollector
 
 import (
-	"fmt"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/google/go-cmp/cmp"
 	log "github.com/sirupsen/logrus"
 
 	corev1 "k8s.io/api/core/v1"
+	apiv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/trilioData/tvk-plugins/internal"
@@ -18,6 +20,7 @@ import (
 
 const (
 	CsiStorageGroup           = "csi.storage.k8s.io"
+	StorageGroup              = "storage.k8s.io"
 	SnapshotStorageGroup      = "snapshot.storage.k8s.io"
 	ClusterServiceVersion     = "clusterserviceversions"
 	ClusterServiceVersionKind = "ClusterServiceVersion"
@@ -25,9 +28,11 @@ const (
 
 	CoreGv           = "v1"
 	Events           = "events"
+	podLogResource   = "pods/log"
 	CRD              = "customresourcedefinitions"
 	Namespaces       = "namespaces"
 	Pod              = "Pod"
+	EventKind        = "Event"
 	SubscriptionKind = "Subscription"
 	InstallPlanKind  = "InstallPlan"
 
@@ -35,10 +40,15 @@ const (
 	Verblist    = "list"
 
 	TrilioPrefix = "k8s-triliovault"
+
+	containerTypeApp       = ""
+	containerTypeInit      = "init"
+	containerTypeEphemeral = "ephemeral"
 )
 
 var (
-	scheme = runtime.NewScheme()
+	scheme          = runtime.NewScheme()
+	registerSchemes sync.Once
 
 	K8STrilioVaultLabel = map[string]string{"app.kubernetes.io/part-of": TrilioPrefix}
 	nonLabeledResources = sets.NewString("ResourceQuota", "LimitRange", "VolumeSnapshot", "ClusterServiceVersion")
@@ -47,85 +57,25 @@ var (
 	excludeResources = sets.NewString("Secret", "PackageManifest")
 )
 
-type containerStat struct {
-	prev bool
-	curr bool
+// kindGroupVersionResources contains API resources of a kind served by different group versions
+type kindGroupVersionResources struct {
+	kind      string
+	resources []groupVersionResource
 }
 
-// aggregateEvents aggregates events based on involved objects
-func aggregateEvents(eventObjects unstructured.UnstructuredList,
-	resourceMap map[string][]types.NamespacedName) (map[string][]map[string]interface{}, error) {
-
-	eventsData := make(map[string][]map[string]interface{})
-	for _, eve := range eventObjects.Items {
-		apiVersion, _, aErr := unstructured.NestedString(eve.Object, "involvedObject", "apiVersion")
-		if aErr != nil {
-			log.Errorf("Unable to get event data of Object : %s", aErr.Error())
-			return nil, aErr
-		}
-
-		namespace, _, nErr := unstructured.NestedString(eve.Object, "involvedObject", "namespace")
-		if nErr != nil {
-			log.Errorf("Unable to get event data of Object : %s", nErr.Error())
-			return nil, nErr
-		}
-		if namespace == "" {
-			namespace = "default"
-		}
-
-		kind, _, gErr := unstructured.NestedString(eve.Object, "involvedObject", "kind")
-		if gErr != nil {
-			log.Errorf("Unable to get event data of Object : %s", gErr.Error())
-			return nil, gErr
-		}
-
-		name, _, naErr := unstructured.NestedString(eve.Object, "involvedObject", "name")
-		if naErr != nil {
-			log.Errorf("Unable to get event data of Object : %s", naErr.Error())
-			return nil, naErr
-		}
-		namespacedName := getNamespacedName(namespace, name)
-
-		// checking if kind and Namespaced Name exist in resourceMap
-		kindExist := false
-		nameNsExist := false
-		if value, ok := resourceMap[kind]; ok {
-			kindExist = true
-			for _, nameNs := range value {
-				if cmp.Equal(namespacedName, nameNs) {
-					nameNsExist = true
-				}
-			}
-		}
-		if strings.HasPrefix(apiVersion, internal.TriliovaultGroup) || (kindExist && nameNsExist) {
-
-			_, ok := eve.Object["metadata"]
-			if ok {
-				delete(eve.Object, "metadata")
-			}
-			_, ok = eve.Object["involvedObject"]
-
-			if ok {
-				delete(eve.Object, "involvedObject")
-			}
-
-			kindNameKey := fmt.Sprintf("%s/%s", strings.ToLower(kind), name)
-
-			tempMap := make(map[string]interface{})
-			tempMap[kindNameKey] = eve.Object
-			eventsData[namespace] = append(eventsData[namespace], tempMap)
-		}
-	}
-	return eventsData, nil
+type groupVersionResource struct {
+	groupVersion string
+	resource     apiv1.APIResource
 }
 
-// getNamespacedName returns namespaced name representation of a resource
-func getNamespacedName(namespace, name string) types.NamespacedName {
-
-	return types.NamespacedName{
-		Namespace: namespace,
-		Name:      name,
-	}
+type containerStat struct {
+	prev bool
+	curr bool
+	// prevStart and currStart are start times of previous and current instances of container, zero if unknown
+	prevStart time.Time
+	currStart time.Time
+	// containerType is containerTypeInit or containerTypeEphemeral, empty for app containers
+	containerType string
 }
 
 // filterTvkCSV returns list of openshift csv created by triliovault
@@ -180,6 +130,7 @@ func getContainerStatusValue(containerStatus *corev1.ContainerStatus) (conStatOb
 	if lastState.Waiting == nil {
 		if lastState.Terminated != nil || lastState.Running != nil {
 			conStatObj.prev = true
+			conStatObj.prevStart = stateStartTime(&lastState)
 		}
 	} else {
 		log.Errorf("Container %s Previous State is in Waiting", containerStatus.Name)
@@ -188,6 +139,7 @@ func getContainerStatusValue(containerStatus *corev1.ContainerStatus) (conStatOb
 	if currentState.Waiting == nil {
 		if currentState.Terminated != nil || currentState.Running != nil {
 			conStatObj.curr = true
+			conStatObj.currStart = stateStartTime(&currentState)
 		}
 	} else {
 		log.Errorf("Container %s Current State is in Waiting", containerStatus.Name)
@@ -196,6 +148,18 @@ func getContainerStatusValue(containerStatus *corev1.ContainerStatus) (conStatOb
 	return conStatObj
 }
 
+// stateStartTime returns start time of running or terminated container 'state', zero if not known
+func stateStartTime(state *corev1.ContainerState) time.Time {
+	switch {
+	case state.Running != nil:
+		return state.Running.StartedAt.Time
+	case state.Terminated != nil:
+		return state.Terminated.StartedAt.Time
+	default:
+		return time.Time{}
+	}
+}
+
 // getAPIGroupVersionResourcePath returns api resource path for given groupVersion
 func getAPIGroupVersionResourcePath(apiGroupVersion string) string {
 	if apiGroupVersion == "v1" {
@@ -205,21 +169,20 @@ func getAPIGroupVersionResourcePath(apiGroupVersion string) string {
 
 }
 
-// getContainers returns containers of a pod with their current and previous statuses
+// getContainers returns init, app and ephemeral containers of a pod with their current and previous statuses
 func getContainers(podObject *corev1.Pod) map[string]containerStat {
 	containers := make(map[string]containerStat)
-	containerStatuses := podObject.Status.ContainerStatuses
-	for index := range containerStatuses {
-		status := getContainerStatusValue(&containerStatuses[index])
-		if status.curr || status.prev {
-			containers[containerStatuses[index].Name] = status
-		}
-	}
-	containerStatuses = podObject.Status.InitContainerStatuses
-	for index := range containerStatuses {
-		status := getContainerStatusValue(&containerStatuses[index])
-		if status.curr || status.prev {
-			containers[containerStatuses[index].Name] = status
+	for containerType, containerStatuses := range map[string][]corev1.ContainerStatus{
+		containerTypeInit:      podObject.Status.InitContainerStatuses,
+		containerTypeApp:       podObject.Status.ContainerStatuses,
+		containerTypeEphemeral: podObject.Status.EphemeralContainerStatuses,
+	} {
+		for index := range containerStatuses {
+			status := getContainerStatusValue(&containerStatuses[index])
+			if status.curr || status.prev {
+				status.containerType = containerType
+				containers[containerStatuses[index].Name] = status
+			}
 		}
 	}
 	return containers
@@ -240,15 +203,105 @@ func checkLabelExist(givenLabel, toCheckInLabel map[string]string) (exist bool)
 	return exist
 }
 
-// filterTvkResourcesByLabel filter objects on the basis of Labels
-func filterTvkResourcesByLabel(allObjects *unstructured.UnstructuredList) {
+// filterTvkResourcesByLabel filter objects on the basis of Labels. Objects having TVK label or matching any
+// user given label selector of 'selection' are kept.
+func filterTvkResourcesByLabel(allObjects *unstructured.UnstructuredList, selection *resourceSelection) {
 	var objects unstructured.UnstructuredList
 
-	for _, object := range allObjects.Items {
-		objectLabel := object.GetLabels()
-		if len(objectLabel) != 0 && checkLabelExist(objectLabel, K8STrilioVaultLabel) {
-			objects.Items = append(objects.Items, object)
+	for index := range allObjects.Items {
+		objectLabel := allObjects.Items[index].GetLabels()
+		if (len(objectLabel) != 0 && checkLabelExist(objectLabel, K8STrilioVaultLabel)) ||
+			selection.matchesSelectors(&allObjects.Items[index]) {
+			objects.Items = append(objects.Items, allObjects.Items[index])
 		}
 	}
 	allObjects.Items = objects.Items
 }
+
+// groupResourcesByKind groups resources of all group versions by kind in sorted order of kind & group version,
+// events are collected separately by collectEvents
+func groupResourcesByKind(resourceGroup map[string][]apiv1.APIResource) (kindResources []kindGroupVersionResources) {
+
+	groupVersions := make([]string, 0, len(resourceGroup))
+	for groupVersion := range resourceGroup {
+		groupVersions = append(groupVersions, groupVersion)
+	}
+	sort.Strings(groupVersions)
+
+	kindIndex := make(map[string]int)
+	for _, groupVersion := range groupVersions {
+		resources := resourceGroup[groupVersion]
+		for index := range resources {
+			if groupVersion != TriliovaultGroupVersion && resources[index].Name == Events {
+				continue
+			}
+
+			kind := resources[index].Kind
+			if _, ok := kindIndex[kind]; !ok {
+				kindIndex[kind] = len(kindResources)
+				kindResources = append(kindResources, kindGroupVersionResources{kind: kind})
+			}
+			kindResources[kindIndex[kind]].resources = append(kindResources[kindIndex[kind]].resources,
+				groupVersionResource{groupVersion: groupVersion, resource: resources[index]})
+		}
+	}
+
+	sort.SliceStable(kindResources, func(i, j int) bool {
+		return kindResources[i].kind < kindResources[j].kind
+	})
+	return kindResources
+}
+
+// workerPool bounds number of resources fetched and pod logs streamed concurrently across nested runParallel calls
+// and clusters of a collection
+type workerPool chan struct{}
+
+func newWorkerPool(size int) workerPool {
+	if size < 1 {
+		size = 1
+	}
+	return make(workerPool, size)
+}
+
+// run calls 'fn' once a worker of pool is available, nil pool calls 'fn' right away
+func (p workerPool) run(fn func() error) error {
+	if p == nil {
+		return fn()
+	}
+	p <- struct{}{}
+	defer func() {
+		<-p
+	}()
+	return fn()
+}
+
+// runParallel calls 'fn' for indexes [0, n) using at most 'parallelism' workers. Error of one call doesn't stop
+// other calls, errors of all calls are aggregated in order of indexes.
+func runParallel(parallelism, n int, fn func(index int) error) error {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	if parallelism > n {
+		parallelism = n
+	}
+
+	errs := make([]error, n)
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < parallelism; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				errs[index] = fn(index)
+			}
+		}()
+	}
+	for index := 0; index < n; index++ {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	return utilerrors.NewAggregate(errs)
+}
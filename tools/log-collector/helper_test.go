@@ -0,0 +1,184 @@
+package logcollector
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRunParallel(t *testing.T) {
+	tests := []struct {
+		name        string
+		parallelism int
+		n           int
+		failing     map[int]bool
+		wantErr     string
+	}{
+		{name: "no calls", parallelism: 3, n: 0},
+		{name: "more workers than calls", parallelism: 10, n: 3},
+		{name: "invalid parallelism runs sequentially", parallelism: 0, n: 4},
+		{name: "errors are aggregated in order of indexes", parallelism: 2, n: 5, failing: map[int]bool{3: true, 1: true},
+			wantErr: "[call 1 failed, call 3 failed]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mutex          sync.Mutex
+				called         = make(map[int]int)
+				running, limit int
+			)
+			err := runParallel(tt.parallelism, tt.n, func(index int) error {
+				mutex.Lock()
+				called[index]++
+				running++
+				if running > limit {
+					limit = running
+				}
+				mutex.Unlock()
+
+				defer func() {
+					mutex.Lock()
+					running--
+					mutex.Unlock()
+				}()
+				if tt.failing[index] {
+					return fmt.Errorf("call %d failed", index)
+				}
+				return nil
+			})
+
+			if (err == nil && tt.wantErr != "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Errorf("runParallel() error = %v, want %q", err, tt.wantErr)
+			}
+			for index := 0; index < tt.n; index++ {
+				if called[index] != 1 {
+					t.Errorf("fn called %d times for index %d, want 1", called[index], index)
+				}
+			}
+			workers := tt.parallelism
+			if workers < 1 {
+				workers = 1
+			}
+			if limit > workers {
+				t.Errorf("%d calls ran concurrently, want at most %d", limit, workers)
+			}
+		})
+	}
+}
+
+func TestWorkerPool(t *testing.T) {
+	tests := []struct {
+		name  string
+		size  int
+		limit int
+	}{
+		{name: "nested parallel calls share workers", size: 3, limit: 3},
+		{name: "invalid size has single worker", size: 0, limit: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mutex            sync.Mutex
+				calls            int
+				running, maximum int
+			)
+			workers := newWorkerPool(tt.size)
+			// outer calls don't hold a worker while their nested calls run, like resources and their pod logs
+			err := runParallel(4, 4, func(int) error {
+				return runParallel(4, 4, func(int) error {
+					return workers.run(func() error {
+						mutex.Lock()
+						calls++
+						running++
+						if running > maximum {
+							maximum = running
+						}
+						mutex.Unlock()
+
+						time.Sleep(time.Millisecond)
+						mutex.Lock()
+						running--
+						mutex.Unlock()
+						return nil
+					})
+				})
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if calls != 16 || maximum > tt.limit {
+				t.Errorf("%d calls ran with at most %d concurrently, want 16 calls with at most %d", calls, maximum,
+					tt.limit)
+			}
+		})
+	}
+}
+
+func TestGetContainerStatusValue(t *testing.T) {
+	prevStart := time.Date(2021, 6, 8, 10, 0, 0, 0, time.UTC)
+	currStart := prevStart.Add(time.Hour)
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: apiv1.NewTime(currStart)}}
+	terminated := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{StartedAt: apiv1.NewTime(prevStart)}}
+	waiting := corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: crashLoopBackOff}}
+
+	tests := []struct {
+		name   string
+		status corev1.ContainerStatus
+		want   containerStat
+	}{
+		{name: "running without restarts", status: corev1.ContainerStatus{State: running},
+			want: containerStat{curr: true, currStart: currStart}},
+		{name: "running after restart", status: corev1.ContainerStatus{State: running, LastTerminationState: terminated},
+			want: containerStat{prev: true, prevStart: prevStart, curr: true, currStart: currStart}},
+		{name: "waiting after restart", status: corev1.ContainerStatus{State: waiting, LastTerminationState: terminated},
+			want: containerStat{prev: true, prevStart: prevStart}},
+		{name: "terminated", status: corev1.ContainerStatus{State: terminated},
+			want: containerStat{curr: true, currStart: prevStart}},
+		{name: "waiting to start", status: corev1.ContainerStatus{State: waiting}},
+		{name: "unknown state"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getContainerStatusValue(&tt.status); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getContainerStatusValue() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetContainers(t *testing.T) {
+	started := time.Date(2021, 6, 8, 10, 0, 0, 0, time.UTC)
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: apiv1.NewTime(started)}}
+	terminated := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{StartedAt: apiv1.NewTime(started)}}
+	waiting := corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}}
+
+	pod := &corev1.Pod{Status: corev1.PodStatus{
+		InitContainerStatuses: []corev1.ContainerStatus{
+			{Name: "setup", State: terminated},
+			{Name: "migrate", State: running, LastTerminationState: terminated},
+		},
+		ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "server", State: running},
+			{Name: "sidecar", State: waiting},
+		},
+		EphemeralContainerStatuses: []corev1.ContainerStatus{{Name: "debug", State: running}},
+	}}
+
+	want := map[string]containerStat{
+		"setup":   {curr: true, currStart: started, containerType: containerTypeInit},
+		"migrate": {prev: true, prevStart: started, curr: true, currStart: started, containerType: containerTypeInit},
+		"server":  {curr: true, currStart: started, containerType: containerTypeApp},
+		"debug":   {curr: true, currStart: started, containerType: containerTypeEphemeral},
+	}
+	if got := getContainers(pod); !reflect.DeepEqual(got, want) {
+		t.Errorf("getContainers() = %+v, want %+v", got, want)
+	}
+}
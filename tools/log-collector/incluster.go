@@ -0,0 +1,288 @@
+package logcollector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	apiv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+const (
+	DefaultDeploymentName = "tvk-log-collector"
+	DefaultStorageSize    = "5Gi"
+
+	// BundlesMountPath is the directory of collector pod where PVC storing bundles is mounted
+	BundlesMountPath = "/bundles"
+
+	instanceLabel      = "app.kubernetes.io/instance"
+	nameLabel          = "app.kubernetes.io/name"
+	bundlesVolume      = "bundles"
+	collectorContainer = "log-collector"
+	jobBackoffLimit    = int32(1)
+	cronJobHistory     = int32(3)
+	pollInterval       = 2 * time.Second
+	pollTimeout        = 5 * time.Minute
+)
+
+var cronJobV1GVK = schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "CronJob"}
+
+// InClusterDeployment runs log-collector in cluster as a Job, or as a CronJob if Schedule is set, with a service
+// account bound to a read only ClusterRole. Bundles are written to a PVC unless S3Secret is set, in which case Args
+// should upload bundle with an s3:// output.
+type InClusterDeployment struct {
+	Name      string
+	Namespace string
+	Image     string
+	// Args are arguments of log-collector run by Job, e.g. --clustered
+	Args []string
+	// Rules of ClusterRole of collector, e.g. LogCollector.ClusterRoleRules of collector configured like Args
+	Rules []rbacv1.PolicyRule
+	// Schedule is a cron schedule of CronJob, a Job running once is created if empty
+	Schedule string
+	// StorageClass and StorageSize of PVC storing bundles, default storage class is used if StorageClass is empty
+	StorageClass string
+	StorageSize  string
+	// S3Secret is a secret with AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and optionally AWS_SESSION_TOKEN keys set
+	// as environment of collector
+	S3Secret string
+}
+
+// claimName returns name of PVC storing bundles, empty if bundles are uploaded instead
+func (d *InClusterDeployment) claimName() string {
+	if d.S3Secret != "" {
+		return ""
+	}
+	return d.Name + "-" + bundlesVolume
+}
+
+func (d *InClusterDeployment) labels() map[string]string {
+	return map[string]string{nameLabel: DefaultDeploymentName, instanceLabel: d.Name}
+}
+
+func (d *InClusterDeployment) objectMeta(name string) apiv1.ObjectMeta {
+	return apiv1.ObjectMeta{Name: name, Namespace: d.Namespace, Labels: d.labels()}
+}
+
+// Objects returns objects to be applied for running log-collector in cluster. CronJob of batch/v1beta1 is returned
+// if 'cronJobV1' is false.
+func (d *InClusterDeployment) Objects(cronJobV1 bool) ([]client.Object, error) {
+	if d.Name == "" || d.Namespace == "" || d.Image == "" {
+		return nil, fmt.Errorf("name, namespace and image are required to run log-collector in cluster")
+	}
+	if len(d.Rules) == 0 {
+		return nil, fmt.Errorf("rules of ClusterRole are required to run log-collector in cluster")
+	}
+
+	objects := []client.Object{
+		&corev1.ServiceAccount{
+			TypeMeta:   apiv1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "ServiceAccount"},
+			ObjectMeta: d.objectMeta(d.Name),
+		},
+		&rbacv1.ClusterRole{
+			TypeMeta:   apiv1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: apiv1.ObjectMeta{Name: d.Name, Labels: d.labels()},
+			Rules:      d.Rules,
+		},
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta:   apiv1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+			ObjectMeta: apiv1.ObjectMeta{Name: d.Name, Labels: d.labels()},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: d.Name},
+			Subjects: []rbacv1.Subject{
+				{Kind: rbacv1.ServiceAccountKind, Name: d.Name, Namespace: d.Namespace},
+			},
+		},
+	}
+
+	if claimName := d.claimName(); claimName != "" {
+		claim, err := d.persistentVolumeClaim(claimName)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, claim)
+	}
+
+	jobSpec := d.jobSpec()
+	switch {
+	case d.Schedule == "":
+		objects = append(objects, &batchv1.Job{
+			TypeMeta:   apiv1.TypeMeta{APIVersion: batchv1.SchemeGroupVersion.String(), Kind: "Job"},
+			ObjectMeta: d.objectMeta(d.Name),
+			Spec:       jobSpec,
+		})
+	case cronJobV1:
+		history := cronJobHistory
+		objects = append(objects, &batchv1.CronJob{
+			TypeMeta:   apiv1.TypeMeta{APIVersion: batchv1.SchemeGroupVersion.String(), Kind: "CronJob"},
+			ObjectMeta: d.objectMeta(d.Name),
+			Spec: batchv1.CronJobSpec{
+				Schedule:                   d.Schedule,
+				ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+				SuccessfulJobsHistoryLimit: &history,
+				FailedJobsHistoryLimit:     &history,
+				JobTemplate:                batchv1.JobTemplateSpec{ObjectMeta: apiv1.ObjectMeta{Labels: d.labels()}, Spec: jobSpec},
+			},
+		})
+	default:
+		history := cronJobHistory
+		objects = append(objects, &batchv1beta1.CronJob{
+			TypeMeta:   apiv1.TypeMeta{APIVersion: batchv1beta1.SchemeGroupVersion.String(), Kind: "CronJob"},
+			ObjectMeta: d.objectMeta(d.Name),
+			Spec: batchv1beta1.CronJobSpec{
+				Schedule:                   d.Schedule,
+				ConcurrencyPolicy:          batchv1beta1.ForbidConcurrent,
+				SuccessfulJobsHistoryLimit: &history,
+				FailedJobsHistoryLimit:     &history,
+				JobTemplate:                batchv1beta1.JobTemplateSpec{ObjectMeta: apiv1.ObjectMeta{Labels: d.labels()}, Spec: jobSpec},
+			},
+		})
+	}
+	return objects, nil
+}
+
+func (d *InClusterDeployment) persistentVolumeClaim(claimName string) (*corev1.PersistentVolumeClaim, error) {
+	size := d.StorageSize
+	if size == "" {
+		size = DefaultStorageSize
+	}
+	quantity, err := resource.ParseQuantity(size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage size %s : %s", size, err.Error())
+	}
+
+	claim := &corev1.PersistentVolumeClaim{
+		TypeMeta:   apiv1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: PersistentVolumeClaimKind},
+		ObjectMeta: d.objectMeta(claimName),
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: quantity},
+			},
+		},
+	}
+	if d.StorageClass != "" {
+		claim.Spec.StorageClassName = &d.StorageClass
+	}
+	return claim, nil
+}
+
+// jobSpec returns spec of Job running log-collector with 'Args', in bundles directory if bundles are stored on PVC
+func (d *InClusterDeployment) jobSpec() batchv1.JobSpec {
+	backoffLimit := jobBackoffLimit
+	container := corev1.Container{
+		Name:  collectorContainer,
+		Image: d.Image,
+		Args:  d.Args,
+	}
+	podSpec := corev1.PodSpec{
+		ServiceAccountName: d.Name,
+		RestartPolicy:      corev1.RestartPolicyNever,
+	}
+
+	if claimName := d.claimName(); claimName != "" {
+		container.WorkingDir = BundlesMountPath
+		container.VolumeMounts = []corev1.VolumeMount{{Name: bundlesVolume, MountPath: BundlesMountPath}}
+		podSpec.Volumes = []corev1.Volume{{
+			Name: bundlesVolume,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+			},
+		}}
+	} else {
+		container.EnvFrom = []corev1.EnvFromSource{{
+			SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: d.S3Secret}},
+		}}
+	}
+	podSpec.Containers = []corev1.Container{container}
+
+	return batchv1.JobSpec{
+		BackoffLimit: &backoffLimit,
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: apiv1.ObjectMeta{Labels: d.labels()},
+			Spec:       podSpec,
+		},
+	}
+}
+
+// Apply creates objects of deployment, updating existing ones. Existing Job is replaced as its pod template is
+// immutable.
+func (d *InClusterDeployment) Apply(kubeConfig string) error {
+	acc, err := newAccessor(kubeConfig, "")
+	if err != nil {
+		return err
+	}
+	k8sClient := acc.GetRuntimeClient()
+
+	objects, err := d.Objects(internal.CheckIfAPIVersionKindAvailable(acc.GetDiscoveryClient(), cronJobV1GVK))
+	if err != nil {
+		return err
+	}
+	for _, obj := range objects {
+		if err = applyObject(k8sClient, obj); err != nil {
+			log.Errorf("Unable to apply %s %s : %s", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(),
+				err.Error())
+			return err
+		}
+		log.Infof("Applied %s %s", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName())
+	}
+	return nil
+}
+
+func applyObject(k8sClient client.Client, obj client.Object) error {
+	ctx := context.Background()
+	err := k8sClient.Create(ctx, obj)
+	if err == nil || !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	existing, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return fmt.Errorf("unable to copy %s", obj.GetName())
+	}
+	if err = k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), existing); err != nil {
+		return err
+	}
+
+	switch obj.(type) {
+	case *batchv1.Job:
+		propagation := apiv1.DeletePropagationBackground
+		if err = k8sClient.Delete(ctx, existing, &client.DeleteOptions{PropagationPolicy: &propagation}); err != nil {
+			return err
+		}
+		return createAfterDeletion(ctx, k8sClient, obj)
+	case *corev1.PersistentVolumeClaim:
+		// spec of bound claim can't be changed, bundles on it are kept
+		return nil
+	default:
+		obj.SetResourceVersion(existing.GetResourceVersion())
+		return k8sClient.Update(ctx, obj)
+	}
+}
+
+// waitFor polls 'condition' until it is done, fails or pollTimeout elapses
+func waitFor(condition wait.ConditionFunc) error {
+	return wait.PollImmediate(pollInterval, pollTimeout, condition)
+}
+
+// createAfterDeletion creates 'obj' once its previous instance being deleted is gone
+func createAfterDeletion(ctx context.Context, k8sClient client.Client, obj client.Object) error {
+	return waitFor(func() (bool, error) {
+		err := k8sClient.Create(ctx, obj)
+		if apierrors.IsAlreadyExists(err) {
+			return false, nil
+		}
+		return true, err
+	})
+}
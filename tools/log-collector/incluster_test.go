@@ -0,0 +1,97 @@
+package logcollector
+
+import (
+	"reflect"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestInClusterDeploymentObjects(t *testing.T) {
+	rules := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"pods/log"}, Verbs: []string{verbGet}},
+		{APIGroups: []string{""}, Resources: []string{"pods", "secrets"}, Verbs: []string{Verblist}},
+	}
+
+	tests := []struct {
+		name       string
+		deployment InClusterDeployment
+		cronJobV1  bool
+		kinds      []string
+		wantErr    bool
+	}{
+		{
+			name:       "job storing bundles on pvc",
+			deployment: InClusterDeployment{Name: "collector", Namespace: "tvk", Image: "collector:latest", Rules: rules},
+			kinds: []string{"*v1.ServiceAccount", "*v1.ClusterRole", "*v1.ClusterRoleBinding",
+				"*v1.PersistentVolumeClaim", "*v1.Job"},
+		},
+		{
+			name: "cronjob uploading bundles",
+			deployment: InClusterDeployment{Name: "collector", Namespace: "tvk", Image: "collector:latest", Rules: rules,
+				Schedule: "0 2 * * *", S3Secret: "minio"},
+			cronJobV1: true,
+			kinds:     []string{"*v1.ServiceAccount", "*v1.ClusterRole", "*v1.ClusterRoleBinding", "*v1.CronJob"},
+		},
+		{
+			name: "v1beta1 cronjob",
+			deployment: InClusterDeployment{Name: "collector", Namespace: "tvk", Image: "collector:latest", Rules: rules,
+				Schedule: "0 2 * * *"},
+			kinds: []string{"*v1.ServiceAccount", "*v1.ClusterRole", "*v1.ClusterRoleBinding",
+				"*v1.PersistentVolumeClaim", "*v1beta1.CronJob"},
+		},
+		{
+			name:       "missing rules",
+			deployment: InClusterDeployment{Name: "collector", Namespace: "tvk", Image: "collector:latest"},
+			wantErr:    true,
+		},
+		{
+			name:       "missing image",
+			deployment: InClusterDeployment{Name: "collector", Namespace: "tvk", Rules: rules},
+			wantErr:    true,
+		},
+		{
+			name: "invalid storage size",
+			deployment: InClusterDeployment{Name: "collector", Namespace: "tvk", Image: "collector:latest", Rules: rules,
+				StorageSize: "large"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := tt.deployment.Objects(tt.cronJobV1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Objects() error = %v, wantErr %t", err, tt.wantErr)
+			}
+
+			var kinds []string
+			for _, obj := range objects {
+				kinds = append(kinds, reflect.TypeOf(obj).String())
+				var podSpec *corev1.PodSpec
+				switch typed := obj.(type) {
+				case *rbacv1.ClusterRole:
+					if !reflect.DeepEqual(typed.Rules, rules) {
+						t.Errorf("ClusterRole rules = %v, want %v", typed.Rules, rules)
+					}
+				case *batchv1.Job:
+					podSpec = &typed.Spec.Template.Spec
+				case *batchv1.CronJob:
+					podSpec = &typed.Spec.JobTemplate.Spec.Template.Spec
+				case *batchv1beta1.CronJob:
+					podSpec = &typed.Spec.JobTemplate.Spec.Template.Spec
+				}
+				if podSpec != nil && podSpec.ServiceAccountName != tt.deployment.Name {
+					t.Errorf("collector runs with service account %s, want %s", podSpec.ServiceAccountName,
+						tt.deployment.Name)
+				}
+			}
+			if !reflect.DeepEqual(kinds, tt.kinds) {
+				t.Errorf("Objects() = %v, want %v", kinds, tt.kinds)
+			}
+		})
+	}
+}
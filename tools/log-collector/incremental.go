@@ -0,0 +1,266 @@
+package logcollector
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+const ObjectManifestFile = "object-manifest.yaml"
+
+// objectManifest records objects collected with their resource versions, so that a later run can collect only
+// objects changed since this bundle
+type objectManifest struct {
+	mutex   sync.Mutex
+	objects []objectManifestEntry
+}
+
+type objectManifestEntry struct {
+	Path            string `json:"path"`
+	Kind            string `json:"kind"`
+	Namespace       string `json:"namespace,omitempty"`
+	Name            string `json:"name"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	// Unchanged is true when object is not written to bundle as it is unchanged since base bundle
+	Unchanged bool `json:"unchanged,omitempty"`
+}
+
+type objectManifestFile struct {
+	StartTime time.Time `json:"startTime"`
+	// BaseBundle is the bundle this bundle is collected incrementally to
+	BaseBundle string                `json:"baseBundle,omitempty"`
+	Objects    []objectManifestEntry `json:"objects"`
+	// Deleted are objects of base bundle which are not found anymore
+	Deleted []objectManifestEntry `json:"deleted,omitempty"`
+}
+
+// add records entry of object, it is safe to be called concurrently
+func (m *objectManifest) add(entry objectManifestEntry) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.objects = append(m.objects, entry)
+}
+
+// keys returns keys of recorded objects by kind, namespace and name
+func (m *objectManifest) keys() sets.String {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	keys := sets.NewString()
+	for idx := range m.objects {
+		keys.Insert(objectKey(m.objects[idx].Kind, m.objects[idx].Namespace, m.objects[idx].Name))
+	}
+	return keys
+}
+
+// baseBundle is a previous bundle which objects, events and logs are collected incrementally to
+type baseBundle struct {
+	path      string
+	startTime time.Time
+	objects   map[string]objectManifestEntry
+}
+
+// unchanged returns true if object 'entry' has the same resource version in base bundle
+func (b *baseBundle) unchanged(entry *objectManifestEntry) bool {
+	if b == nil || entry.ResourceVersion == "" {
+		return false
+	}
+	baseEntry, ok := b.objects[entry.Path]
+	return ok && baseEntry.ResourceVersion == entry.ResourceVersion
+}
+
+// loadBaseBundle reads objects and start time of bundle at 'bundlePath' from its object manifest. Objects of bundles
+// without manifest are read from their YAMLs and start time from summary.
+func loadBaseBundle(bundlePath string) (*baseBundle, error) {
+	objects, manifest, err := readBundleObjects(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+
+	base := &baseBundle{path: bundlePath, objects: make(map[string]objectManifestEntry, len(objects))}
+	for name := range objects {
+		if objects[name].cluster != "" {
+			mErr := fmt.Errorf("bundle %s is a multi-cluster bundle, changes can be collected only since bundle of a "+
+				"single cluster", bundlePath)
+			log.Error(mErr.Error())
+			return nil, mErr
+		}
+		base.objects[name] = objects[name].entry
+	}
+	if manifest != nil {
+		base.startTime = manifest.StartTime
+	}
+
+	if base.startTime.IsZero() {
+		var summary bundleSummary
+		err = readBundle(bundlePath, func(name string, content []byte) error {
+			if name == SummaryJSONFile {
+				return json.Unmarshal(content, &summary)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Errorf("Unable to read %s of bundle %s : %s", SummaryJSONFile, bundlePath, err.Error())
+			return nil, err
+		}
+		base.startTime = summary.StartTime
+	}
+	if base.startTime.IsZero() {
+		log.Warnf("Start time of bundle %s not found, complete logs and events are collected", bundlePath)
+	}
+
+	log.Infof("Collecting changes since bundle %s of %s with %d objects", bundlePath,
+		base.startTime.Format(time.RFC3339), len(base.objects))
+	return base, nil
+}
+
+// bundleObject is an object of bundle, its content is nil if bundle records it as unchanged since its base bundle.
+// Cluster is the directory of cluster of object in a multi-cluster bundle, path of entry is relative to it.
+type bundleObject struct {
+	cluster string
+	entry   objectManifestEntry
+	content *unstructured.Unstructured
+}
+
+// readBundleObjects returns objects of bundle keyed by their path in bundle, along with object manifest of bundle if
+// any. Objects of clusters of a multi-cluster bundle are read along with object manifests of clusters.
+func readBundleObjects(bundlePath string) (map[string]*bundleObject, *objectManifestFile, error) {
+	objects := make(map[string]*bundleObject)
+	// manifests are object manifests keyed by cluster directory, manifest of single cluster bundle has empty key
+	manifests := make(map[string]*objectManifestFile)
+	err := readBundle(bundlePath, func(name string, content []byte) error {
+		cluster, objPath := splitClusterPath(name)
+		if objPath == ObjectManifestFile {
+			manifest := &objectManifestFile{}
+			manifests[cluster] = manifest
+			return yaml.Unmarshal(content, manifest)
+		}
+		if strings.HasPrefix(objPath, eventsDir+"/") || strings.HasPrefix(objPath, helmReleasesDir+"/") {
+			return nil
+		}
+
+		obj, pErr := parseBundleObject(objPath, content)
+		if pErr != nil {
+			log.Warnf("Skipping %s : %s", name, pErr.Error())
+			return nil
+		}
+		if obj == nil {
+			return nil
+		}
+		objects[name] = &bundleObject{
+			cluster: cluster,
+			entry: objectManifestEntry{Path: objPath, Kind: strings.SplitN(objPath, "/", 2)[0], Namespace: obj.GetNamespace(),
+				Name: obj.GetName(), ResourceVersion: obj.GetResourceVersion()},
+			content: obj,
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("Unable to read bundle %s : %s", bundlePath, err.Error())
+		return nil, nil, err
+	}
+
+	for cluster, manifest := range manifests {
+		for idx := range manifest.Objects {
+			entry := manifest.Objects[idx]
+			name := entry.Path
+			if cluster != "" {
+				name = path.Join(ClustersDir, cluster, entry.Path)
+			}
+			if existing, ok := objects[name]; ok {
+				existing.entry = entry
+				continue
+			}
+			objects[name] = &bundleObject{cluster: cluster, entry: entry}
+		}
+	}
+	return objects, manifests[""], nil
+}
+
+// splitClusterPath returns directory of cluster and path relative to it of file 'name' of a multi-cluster bundle,
+// cluster is empty for files outside of clusters
+func splitClusterPath(name string) (cluster, relPath string) {
+	parts := strings.SplitN(name, "/", 3)
+	if len(parts) == 3 && parts[0] == ClustersDir && parts[1] != "" {
+		return parts[1], parts[2]
+	}
+	return "", name
+}
+
+// recordObject records 'obj' written at 'objPath' in object manifest and returns true if it is to be written, false
+// if it is unchanged since base bundle
+func (l *LogCollector) recordObject(kind, objPath string, obj *unstructured.Unstructured) bool {
+	entry := objectManifestEntry{Path: objPath, Kind: kind, Namespace: obj.GetNamespace(), Name: obj.GetName(),
+		ResourceVersion: obj.GetResourceVersion()}
+	entry.Unchanged = l.base.unchanged(&entry)
+	l.objectManifest.add(entry)
+	return !entry.Unchanged
+}
+
+// writeObjectManifest writes manifest of collected objects sorted by path, along with objects of base bundle which
+// are deleted
+func (l *LogCollector) writeObjectManifest() error {
+	l.objectManifest.mutex.Lock()
+	objects := append([]objectManifestEntry{}, l.objectManifest.objects...)
+	l.objectManifest.mutex.Unlock()
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Path < objects[j].Path
+	})
+	manifest := objectManifestFile{StartTime: l.summary.startTime, Objects: objects}
+
+	if l.base != nil {
+		manifest.BaseBundle = l.base.path
+		collected := sets.NewString()
+		unchanged := 0
+		for idx := range objects {
+			collected.Insert(objects[idx].Path)
+			if objects[idx].Unchanged {
+				unchanged++
+			}
+		}
+		for objPath := range l.base.objects {
+			if !collected.Has(objPath) {
+				deleted := l.base.objects[objPath]
+				deleted.Unchanged = false
+				manifest.Deleted = append(manifest.Deleted, deleted)
+			}
+		}
+		sort.Slice(manifest.Deleted, func(i, j int) bool {
+			return manifest.Deleted[i].Path < manifest.Deleted[j].Path
+		})
+		l.logger().Infof("%d objects changed, %d unchanged and %d deleted since bundle %s", len(objects)-unchanged,
+			unchanged, len(manifest.Deleted), l.base.path)
+	}
+
+	buf, err := yaml.Marshal(manifest)
+	if err != nil {
+		l.logger().Errorf("Unable to marshal the content : %s", err.Error())
+		return err
+	}
+	return l.writeFile(ObjectManifestFile, buf)
+}
+
+// filterNewEvents keeps events last seen after start of base bundle
+func (l *LogCollector) filterNewEvents(events *unstructured.UnstructuredList) {
+	if l.base == nil || l.base.startTime.IsZero() {
+		return
+	}
+
+	var newEvents []unstructured.Unstructured
+	for idx := range events.Items {
+		if eventTime(&events.Items[idx]).After(l.base.startTime) {
+			newEvents = append(newEvents, events.Items[idx])
+		}
+	}
+	l.logger().Infof("%d of %d events are new since bundle %s", len(newEvents), len(events.Items), l.base.path)
+	events.Items = newEvents
+}
@@ -1,14 +1,13 @@
 package logcollector
 
 import (
-	"archive/zip"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
-	"io/ioutil"
-	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
@@ -31,8 +30,15 @@ import (
 )
 
 type LogCollector struct {
-	OutputDir    string
-	CleanOutput  bool
+	// OutputDir is the directory bundle is written to when CleanOutput is false, it also names default archive
+	OutputDir   string
+	CleanOutput bool
+	// Format is the archive format, FormatZip or FormatTarGz. Output is the archive path, StdoutOutput writes
+	// archive to stdout
+	Format string
+	Output string
+	// Sink stores bundle instead of archive at Output, e.g. NewS3Sink to upload bundle to an object store
+	Sink         Sink
 	Clustered    bool
 	Namespaces   []string
 	Loglevel     string
@@ -40,14 +46,70 @@ type LogCollector struct {
 	disClient    *discovery.DiscoveryClient
 	k8sClientSet *kubernetes.Clientset
 	KubeConfig   string
+	// Context is the kubeconfig context collected from, current context is used if empty
+	Context string
+	// Contexts are kubeconfig contexts of clusters collected concurrently, each into its own directory of bundle
+	// under clusters, Context is ignored if set
+	Contexts []string
+	// Parallelism is the maximum number of resources and pod logs collected concurrently
+	Parallelism int
+	// LogSince, LogSinceTime, LogTail and LogLimitBytes bound pod logs collected for each container, zero values
+	// collect complete logs
+	LogSince      time.Duration
+	LogSinceTime  *time.Time
+	LogTail       int64
+	LogLimitBytes int64
+	// Backup and Restore are given as namespace/name, application backed up or restored by them is collected
+	// along with TVK resources
+	Backup  string
+	Restore string
+	// ContinueOnError skips resources which could not be collected instead of aborting, Strict fails collection
+	// if any failure is recorded
+	ContinueOnError bool
+	Strict          bool
+	// SinceBundle is a previous bundle, only objects changed since it, new events and logs after its start are
+	// collected
+	SinceBundle string
+	// Config customizes collection, e.g. user supplied redaction rules and resources to include or exclude
+	Config      Config
+	isOpenshift bool
+	logManifest logManifest
+	redactor    *redactor
+	selection   *resourceSelection
+	bundle      Sink
+	// base is the bundle loaded from SinceBundle
+	base           *baseBundle
+	objectManifest objectManifest
+	// csi records PVCs and pods collected for collecting diagnostics of their CSI drivers
+	csi csiRecorder
+	// collectionErrors are failures of collection written to errors.yaml
+	collectionErrors collectionErrors
+	summary          summaryRecorder
+	// bundleSummary is the summary written to bundle
+	bundleSummary *bundleSummary
+	// cluster is the directory of bundle collector writes to in multi-cluster collection
+	cluster string
+	// collectionLogger logs collection and records its warnings and errors in summary
+	collectionLogger *log.Logger
+	// workers limits resources and pod logs collected concurrently to Parallelism, shared by clusters of multi-cluster
+	// collection
+	workers workerPool
+}
+
+// newAccessor returns accessor of kubernetes environment of 'kubeContext' of 'kubeConfig' with schemes of collector
+// registered, current context is used if 'kubeContext' is empty
+func newAccessor(kubeConfig, kubeContext string) (*internal.Accessor, error) {
+	// schemes are registered once as accessors of clusters are created concurrently in multi-cluster collection
+	registerSchemes.Do(func() {
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+		utilruntime.Must(v1beta1.AddToScheme(scheme))
+	})
+	return internal.NewEnvForContext(kubeConfig, kubeContext, scheme)
 }
 
 // initializeKubeClients initialize clients for kubernetes environment
 func (l *LogCollector) initializeKubeClients() error {
-	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
-	utilruntime.Must(v1beta1.AddToScheme(scheme))
-
-	acc, err := internal.NewEnv(l.KubeConfig, scheme)
+	acc, err := newAccessor(l.KubeConfig, l.Context)
 	if err != nil {
 		return err
 	}
@@ -57,127 +119,165 @@ func (l *LogCollector) initializeKubeClients() error {
 	return nil
 }
 
+// logger returns logger of collection, standard logger is returned if collection is not started
+func (l *LogCollector) logger() *log.Logger {
+	if l.collectionLogger == nil {
+		return log.StandardLogger()
+	}
+	return l.collectionLogger
+}
+
 // CollectLogsAndDump collects call all the related resources of triliovault
 func (l *LogCollector) CollectLogsAndDump() error {
+	if l.workers == nil {
+		l.workers = newWorkerPool(l.Parallelism)
+	}
+	if len(l.Contexts) != 0 {
+		return l.collectClusters()
+	}
+	l.collectionLogger = l.summary.start()
 
 	if err := l.initializeKubeClients(); err != nil {
 		return err
 	}
 
+	rdr, rErr := newRedactor(l.Config.Redaction.Rules)
+	if rErr != nil {
+		return rErr
+	}
+	l.redactor = rdr
+	l.logger().Debugf("Redaction rules : %s", rdr.redactionRuleNames())
+
+	selection, sErr := newResourceSelection(&l.Config.Resources)
+	if sErr != nil {
+		return sErr
+	}
+	l.selection = selection
+
+	appScope, aErr := l.resolveApplicationScope()
+	if aErr != nil {
+		return aErr
+	}
+	if appScope != nil {
+		l.selection.application = appScope
+		if !l.Clustered {
+			l.Namespaces = appScope.namespaces.Union(sets.NewString(l.Namespaces...)).List()
+		}
+	}
+
 	nsErr := l.checkIfNamespacesExist()
 	if nsErr != nil {
 		return nsErr
 	}
 
+	if l.SinceBundle != "" {
+		base, bErr := loadBaseBundle(l.SinceBundle)
+		if bErr != nil {
+			return bErr
+		}
+		l.base = base
+		if l.LogSince == 0 && l.LogSinceTime == nil && !base.startTime.IsZero() {
+			l.LogSinceTime = &base.startTime
+		}
+	}
+
 	resourceMapList, apiErr := l.getAPIResourceList()
 	if apiErr != nil {
 		return apiErr
 	}
 
-	fErr := l.filteringResources(resourceMapList)
-	if fErr != nil {
-		return fErr
+	bErr := l.openBundle()
+	if bErr != nil {
+		return bErr
 	}
 
-	// Zip Directory
-	zErr := l.zipDir()
-	if zErr != nil {
-		return zErr
+	cErr := l.filteringResources(resourceMapList)
+	if cErr == nil {
+		cErr = l.collectCSIDiagnostics()
+	}
+	if cErr == nil {
+		cErr = l.collectEvents(resourceMapList)
+	}
+	if cErr == nil {
+		cErr = l.collectMetrics()
+	}
+	if cErr == nil {
+		cErr = l.collectHelmReleases()
+	}
+	if cErr == nil {
+		cErr = l.writeLogManifest()
+	}
+	if cErr == nil {
+		cErr = l.writeObjectManifest()
 	}
 
-	// check for clean output flag. if true, clean.
-	if l.CleanOutput {
-		err := os.RemoveAll(l.OutputDir)
-		if err != nil {
-			return err
-		}
+	// errors and summary are written even if collection failed
+	if wErr := l.writeCollectionErrors(); wErr != nil && cErr == nil {
+		cErr = wErr
 	}
-	return nil
+	if sErr := l.writeSummary(); sErr != nil && cErr == nil {
+		cErr = sErr
+	}
+	if cErr == nil {
+		cErr = l.checkCollectionErrors()
+	}
+	return l.closeBundle(cErr)
+}
+
+// closeBundle closes bundle and returns 'err' of collection or error of closing bundle
+func (l *LogCollector) closeBundle(err error) error {
+	if cErr := l.bundle.Close(); cErr != nil && err == nil {
+		err = cErr
+	}
+	return err
 }
 
-// getResourceObjects returns list of objects for given resourcePath
+// getResourceObjects returns list for objects for given resourcePath. Failures of listing are recorded, objects
+// of namespaces which could be listed are returned in continue-on-error mode.
 func (l *LogCollector) getResourceObjects(resourcePath string, resource *apiv1.APIResource) (objects unstructured.UnstructuredList,
 	err error) {
 
 	if resource.Namespaced && !l.Clustered {
-		for index := range l.Namespaces {
-			var obj unstructured.UnstructuredList
-			listPath := fmt.Sprintf("%s/namespaces/%s/%s", resourcePath, l.Namespaces[index], resource.Name)
-			err = l.disClient.RESTClient().Get().AbsPath(listPath).Do(context.TODO()).Into(&obj)
-			if err != nil {
-				if !discovery.IsGroupDiscoveryFailedError(err) {
-					log.Errorf("%s", err.Error())
-					return objects, err
-				}
-				if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
-					log.Warnf("%s", err.Error())
-					return objects, nil
-				}
-				/* TODO() Currently error is ignore here, as we do not want to halt the log-collection utility because of
-				single resources GET err. In future, if we add --continue-on-error type flag then, we'll update it and return
-				error depending on --continue-on-error flag value */
-				log.Warnf("%s", err.Error())
-				return unstructured.UnstructuredList{}, nil
-			}
-			objects.Items = append(objects.Items, obj.Items...)
-		}
-		return objects, nil
+		return l.getNamespacesObjects(resourcePath, resource, l.Namespaces)
 	}
 	listPath := fmt.Sprintf("%s/%s", resourcePath, resource.Name)
 	err = l.disClient.RESTClient().Get().AbsPath(listPath).Do(context.TODO()).Into(&objects)
 	if err != nil {
-		if !discovery.IsGroupDiscoveryFailedError(err) {
-			log.Errorf("%s", err.Error())
-			return objects, err
-		}
-		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
-			log.Warnf("%s", err.Error())
-			return objects, nil
-		}
-		/* TODO() Currently error is ignore here, as we do not want to halt the log-collection utility because of
-		single resources GET err. In future, if we add --continue-on-error type flag then, we'll update it and return
-		error depending on --continue-on-error flag value */
-		log.Warnf("%s", err.Error())
-		return unstructured.UnstructuredList{}, nil
+		return unstructured.UnstructuredList{}, l.handleListError(resource, "", err)
 	}
 	return objects, nil
 }
 
-// writeEventsToFile writes events to the file
-func (l *LogCollector) writeEventsToFile(events map[string][]map[string]interface{}) error {
+// getNamespacesObjects returns objects of namespaced resource in 'namespaces'
+func (l *LogCollector) getNamespacesObjects(resourcePath string, resource *apiv1.APIResource,
+	namespaces []string) (objects unstructured.UnstructuredList, err error) {
 
-	for k, v := range events {
-		resourceDir := filepath.Join(l.OutputDir, "Events", k)
-		if _, err := os.Stat(resourceDir); os.IsNotExist(err) {
-			mErr := os.MkdirAll(resourceDir, 0755)
-			if mErr != nil {
-				log.Errorf("Unable to create the directory : %s", mErr.Error())
-				return mErr
+	for index := range namespaces {
+		var obj unstructured.UnstructuredList
+		listPath := fmt.Sprintf("%s/namespaces/%s/%s", resourcePath, namespaces[index], resource.Name)
+		err = l.disClient.RESTClient().Get().AbsPath(listPath).Do(context.TODO()).Into(&obj)
+		if err != nil {
+			if lErr := l.handleListError(resource, namespaces[index], err); lErr != nil {
+				return objects, lErr
 			}
+			continue
 		}
+		objects.Items = append(objects.Items, obj.Items...)
+	}
+	return objects, nil
+}
 
-		for _, obj := range v {
-			for key, value := range obj {
-				key = strings.Replace(key, "/", ".", 1)
-				objectFilePath := filepath.Join(resourceDir, key)
-				fp, err := os.Create(objectFilePath + ".yaml")
-				if err != nil {
-					log.Errorf("Unable to create the file : %s", err.Error())
-					return err
-				}
-				buf, bErr := yaml.Marshal(value)
-				if bErr != nil {
-					log.Errorf("Unable to marshal the content : %s", bErr.Error())
-					return bErr
-				}
-				_, fErr := fp.Write(buf)
-				if fErr != nil {
-					log.Errorf("Unable to write the contents : %s", fErr.Error())
-					return fErr
-				}
-			}
-		}
+// handleListError records error of listing resource and returns it if collection should be aborted
+func (l *LogCollector) handleListError(resource *apiv1.APIResource, namespace string, err error) error {
+	l.collectionErrors.add(resource.Name, namespace, err)
+	if apierrors.IsForbidden(err) {
+		l.logger().Warnf("Missing permission to list %s, run with --dry-run to check all permissions needed", resource.Name)
 	}
+	if !l.ContinueOnError && !discovery.IsGroupDiscoveryFailedError(err) {
+		l.logger().Errorf("%s", err.Error())
+		return err
+	}
+	l.logger().Warnf("%s", err.Error())
 	return nil
 }
 
@@ -186,29 +286,20 @@ func (l *LogCollector) writeYaml(resourceDir string, obj unstructured.Unstructur
 
 	objNs := obj.GetNamespace()
 	objName := obj.GetName()
-	resourcePath := filepath.Join(l.OutputDir, resourceDir, objNs)
-	err := os.MkdirAll(resourcePath, 0755)
-	if err != nil {
-		log.Errorf("Unable to create the directory : %s", err.Error())
-		return err
-	}
-	objFilepath := filepath.Join(resourcePath, objName)
-	fp, fErr := os.Create(objFilepath + ".yaml")
-	if fErr != nil {
-		log.Errorf("Unable to create the file : %s", fErr.Error())
-		return fErr
+	objFilepath := filepath.Join(resourceDir, objNs, objName)
+	if !l.recordObject(resourceDir, filepath.ToSlash(objFilepath)+".yaml", &obj) {
+		l.summary.addObjects(resourceDir, 1)
+		return nil
 	}
-	defer fp.Close()
-	buf, mErr := yaml.Marshal(obj.Object)
+	buf, mErr := yaml.Marshal(l.redactor.redactObject(&obj).Object)
 	if mErr != nil {
-		log.Errorf("Unable to marshal the content : %s", mErr.Error())
+		l.logger().Errorf("Unable to marshal the content : %s", mErr.Error())
 		return mErr
 	}
-	_, bErr := fp.Write(buf)
-	if bErr != nil {
-		log.Errorf("Unable to write the content : %s", bErr.Error())
-		return bErr
+	if err := l.writeFile(objFilepath+".yaml", l.redactor.redactBytes(buf)); err != nil {
+		return err
 	}
+	l.summary.addObjects(resourceDir, 1)
 	return nil
 }
 
@@ -217,36 +308,29 @@ func (l *LogCollector) writeLogs(resourceDir string, obj unstructured.Unstructur
 
 	objNs := obj.GetNamespace()
 	objName := obj.GetName()
-	resourcePath := filepath.Join(l.OutputDir, resourceDir, objNs)
-	if _, err := os.Stat(resourcePath); os.IsNotExist(err) {
-		mErr := os.MkdirAll(resourcePath, 0755)
-		if mErr != nil {
-			log.Errorf("Unable to Create the Directory : %s", mErr.Error())
-			return mErr
-		}
-	}
+	resourcePath := filepath.Join(resourceDir, objNs)
 
 	var podObj corev1.Pod
 	err := l.k8sClient.Get(context.Background(), types.NamespacedName{Name: objName, Namespace: objNs}, &podObj)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			log.Warnf("%s", err.Error())
+			l.logger().Warnf("%s", err.Error())
 			return nil
 		}
-		log.Errorf("Unable to get the object : %s", err.Error())
+		l.logger().Errorf("Unable to get the object : %s", err.Error())
 		return err
 	}
 	containers := getContainers(&podObj)
 
 	for name, statuses := range containers {
 		if statuses.curr {
-			eLrr := l.writeLog(resourcePath, objNs, objName, name, false)
+			eLrr := l.writeLog(resourcePath, objNs, objName, name, statuses.containerType, statuses.currStart, false)
 			if eLrr != nil {
 				return eLrr
 			}
 		}
 		if statuses.prev {
-			eLrr := l.writeLog(resourcePath, objNs, objName, name, true)
+			eLrr := l.writeLog(resourcePath, objNs, objName, name, statuses.containerType, statuses.prevStart, true)
 			if eLrr != nil {
 				return eLrr
 			}
@@ -255,104 +339,141 @@ func (l *LogCollector) writeLogs(resourceDir string, obj unstructured.Unstructur
 	return nil
 }
 
-// writeLog writes logs of a pod object
-func (l *LogCollector) writeLog(resourceDir, objNs, objName, container string, isPrevious bool) error {
+// writeLog streams logs of a container of pod object to file, bounded by since, tail and limit-bytes options. Logs
+// are written to '<pod>.<container>.<current|previous>.log', logs of init and ephemeral containers to
+// '<pod>.<container>.<init|ephemeral>.<current|previous>.log'. 'started' is start time of container instance, used
+// to find if log is truncated by since bounds.
+func (l *LogCollector) writeLog(resourceDir, objNs, objName, container, containerType string, started time.Time,
+	isPrevious bool) error {
 
-	logOption := corev1.PodLogOptions{
-		Container: container,
-		Previous:  isPrevious,
-	}
+	logOption := l.getPodLogOptions(container, isPrevious)
 
-	req := l.k8sClientSet.CoreV1().Pods(objNs).GetLogs(objName, &logOption)
+	req := l.k8sClientSet.CoreV1().Pods(objNs).GetLogs(objName, logOption)
 	podLogs, err := req.Stream(context.TODO())
 	if err != nil {
-		log.Errorf("Unable to get Logs for container %s : %s", container, err.Error())
+		l.logger().Errorf("Unable to get Logs for container %s : %s", container, err.Error())
+		l.collectionErrors.add(podLogResource, objNs, errors.Wrapf(err, "pod %s container %s", objName, container))
 		return nil
 	}
 	defer podLogs.Close()
 
-	buf, err := ioutil.ReadAll(podLogs)
-	if err != nil {
-		log.Errorf("Error in copy information from podLogs to buffer : %s", err.Error())
-		return err
-	}
-
 	var subPath string
 	if isPrevious {
 		subPath = "previous"
 	} else {
 		subPath = "current"
 	}
+	if containerType != containerTypeApp {
+		subPath = containerType + "." + subPath
+	}
 	objectFilepath := fmt.Sprintf("%s.%s.%s.log", filepath.Join(resourceDir, objName), container, subPath)
-	outFile, err := os.Create(objectFilepath)
+	outFile, err := l.bundle.Create(objectFilepath)
 	if err != nil {
-		log.Errorf("Error Creating Log File : %s", err.Error())
+		l.logger().Errorf("Error Creating Log File : %s", err.Error())
 		return err
 	}
-	defer outFile.Close()
-	_, err = outFile.Write(buf)
+
+	lines := &lineCountWriter{Writer: outFile}
+	written, err := l.redactor.redactStream(lines, podLogs)
 	if err != nil {
-		log.Errorf("Unable to Write Pod Logs to the File : %s", err.Error())
+		_ = outFile.Close()
+		l.logger().Errorf("Unable to Write Pod Logs to the File : %s", err.Error())
+		return err
+	}
+	if err = outFile.Close(); err != nil {
+		l.logger().Errorf("Unable to Write Pod Logs to the File : %s", err.Error())
 		return err
 	}
 
+	l.logManifest.add(logManifestEntry{
+		Path:          filepath.ToSlash(objectFilepath),
+		Namespace:     objNs,
+		Pod:           objName,
+		Container:     container,
+		ContainerType: containerType,
+		Previous:      isPrevious,
+		Bytes:         written,
+		TruncatedBy:   l.logTruncation(written, lines.count(), started, time.Now()),
+	})
+
 	return nil
 }
 
-// zipDir creates zip directory of collected info
-func (l *LogCollector) zipDir() error {
-
-	file, err := os.Create(l.OutputDir + ".zip")
-	log.Infof("Creating Zip : %s.zip\n", l.OutputDir)
+// logTruncation returns bounds which cut a log of 'written' bytes and 'lines' lines of container instance started
+// at 'started', logs are bounded at 'now'. Log is cut by since bounds if container started before them.
+func (l *LogCollector) logTruncation(written, lines int64, started, now time.Time) []string {
+	var truncatedBy []string
 
-	if err != nil {
-		log.Errorf("Error Creating zip File : %s", err.Error())
-		return err
+	var since time.Time
+	if l.LogSince > 0 {
+		since = now.Add(-l.LogSince)
+	}
+	if l.LogSinceTime != nil && l.LogSinceTime.After(since) {
+		since = *l.LogSinceTime
+	}
+	if !since.IsZero() && !started.IsZero() && started.Before(since) {
+		truncatedBy = append(truncatedBy, logBoundSince)
 	}
-	defer file.Close()
+	if l.LogTail > 0 && lines >= l.LogTail {
+		truncatedBy = append(truncatedBy, logBoundTail)
+	}
+	if l.LogLimitBytes > 0 && written >= l.LogLimitBytes {
+		truncatedBy = append(truncatedBy, logBoundLimitBytes)
+	}
+	return truncatedBy
+}
 
-	w := zip.NewWriter(file)
-	defer w.Close()
+// lineCountWriter counts lines written to underlying writer, last line without newline is counted as well
+type lineCountWriter struct {
+	io.Writer
+	lines   int64
+	partial bool
+}
 
-	walker := func(path string, info os.FileInfo, err error) error {
-		log.Debugf("Crawling: %#v\n", path)
-		if err != nil {
-			return err
-		}
-		if info.IsDir() {
-			return nil
-		}
-		file, err := os.Open(path)
-		if err != nil {
-			return err
-		}
-		defer file.Close()
+func (w *lineCountWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.lines += int64(bytes.Count(p[:n], []byte{'\n'}))
+	if n > 0 {
+		w.partial = p[n-1] != '\n'
+	}
+	return n, err
+}
 
-		f, err := w.Create(path)
-		if err != nil {
-			return err
-		}
+func (w *lineCountWriter) count() int64 {
+	if w.partial {
+		return w.lines + 1
+	}
+	return w.lines
+}
 
-		_, err = io.Copy(f, file)
-		if err != nil {
-			return err
-		}
+// getPodLogOptions returns log options of container mapped from since, since-time, tail and limit-bytes values
+func (l *LogCollector) getPodLogOptions(container string, isPrevious bool) *corev1.PodLogOptions {
+	logOption := &corev1.PodLogOptions{
+		Container: container,
+		Previous:  isPrevious,
+	}
 
-		return nil
+	if l.LogSince > 0 {
+		sinceSeconds := int64(l.LogSince.Round(time.Second).Seconds())
+		if sinceSeconds == 0 {
+			sinceSeconds = 1
+		}
+		logOption.SinceSeconds = &sinceSeconds
 	}
-	err = filepath.Walk(l.OutputDir, walker)
-	if err != nil {
-		log.Errorf("Unable to walk thorugh directory : %s", err.Error())
-		return err
+	if l.LogSinceTime != nil {
+		sinceTime := apiv1.NewTime(*l.LogSinceTime)
+		logOption.SinceTime = &sinceTime
 	}
-	if l.CleanOutput {
-		err = os.RemoveAll(l.OutputDir)
-		if err != nil {
-			log.Errorf("Unable to remove directory : %s", err.Error())
-			return err
-		}
+	if l.LogTail > 0 {
+		tail := l.LogTail
+		logOption.TailLines = &tail
 	}
-	return nil
+	if l.LogLimitBytes > 0 {
+		limitBytes := l.LogLimitBytes
+		logOption.LimitBytes = &limitBytes
+	}
+
+	return logOption
 }
 
 // filterResourceObjects filter objects on the basis of resource Type.
@@ -361,7 +482,7 @@ func (l *LogCollector) filterResourceObjects(resourcePath string,
 
 	if (!resource.Namespaced && clusteredResources.Has(resource.Kind)) ||
 		(resource.Namespaced && !excludeResources.Has(resource.Kind)) {
-		log.Infof("Fetching '%s' Resource", resource.Kind)
+		l.logger().Infof("Fetching '%s' Resource", resource.Kind)
 		allObjects, err = l.getResourceObjects(resourcePath, resource)
 		if err != nil {
 			return allObjects, err
@@ -385,7 +506,7 @@ func (l *LogCollector) filterResourceObjects(resourcePath string,
 
 	if !nonLabeledResources.Has(resource.Kind) &&
 		!clusteredResources.Has(resource.Kind) {
-		filterTvkResourcesByLabel(&allObjects)
+		filterTvkResourcesByLabel(&allObjects, l.selection)
 	}
 	return allObjects, nil
 }
@@ -393,102 +514,141 @@ func (l *LogCollector) filterResourceObjects(resourcePath string,
 func (l *LogCollector) filteringResources(resourceGroup map[string][]apiv1.APIResource) error {
 	// These operations are performed in the following lines:
 	// 1. Iterating through all the resources.
-	// 2. Filtering only those resources that we need from all the available resources obtained above.
-	// 3. Iterating through the filtered resources to fetch their respective objects based on the group from which
-	//    they belong and labels
+	// 2. Grouping resources by kind so that same kind served by multiple group versions is written by a single worker.
+	// 3. Filtering only those resources that we need from all the available resources obtained above.
+	// 4. Iterating through the filtered resources in parallel to fetch their respective objects based on the group
+	//    from which they belong and labels
 	//    e.g. fetching all pods from core group with the label 'app.kubernetes.io/part-of':'k8s-triliovault'
-	// 4. Collecting pod names specifically that is later required by events
 	// 5. Collecting list of all resource objects and printing their YAML's in their respective resource folder under
 	//    their respective namespaces. In case of pods, logs are also collected
 
-	log.Info("Filtering Resources")
-
-	resourceMapList := make(map[string][]types.NamespacedName)
-	var eventResource apiv1.APIResource
+	l.logger().Info("Filtering Resources")
 
-	for groupVersion, resources := range resourceGroup {
+	l.isOpenshift = l.CheckIsOpenshift()
+	kindResources := groupResourcesByKind(resourceGroup)
 
-		if groupVersion == TriliovaultGroupVersion {
-			err := l.getTrilioGroupResources(resources, groupVersion)
-			if err != nil {
-				return err
-			}
-			continue
+	return runParallel(l.Parallelism, len(kindResources), func(index int) error {
+		if rErr := l.collectKindResources(kindResources[index]); rErr != nil {
+			l.logger().Errorf("Unable to collect '%s' Resource : %s", kindResources[index].kind, rErr.Error())
+			return l.handleError(kindResources[index].kind, "", rErr)
 		}
+		return nil
+	})
+}
 
-		for index := range resources {
+// collectKindResources fetches objects of all group versions of a kind and writes their YAML and logs
+func (l *LogCollector) collectKindResources(kindResource kindGroupVersionResources) error {
 
-			if resources[index].Name == Events {
-				eventResource = resources[index]
-				continue
-			}
+	for index := range kindResource.resources {
+		groupVersion, resource := kindResource.resources[index].groupVersion, &kindResource.resources[index].resource
 
-			var resObjects unstructured.UnstructuredList
-			resObject, err := l.filterResourceObjects(getAPIGroupVersionResourcePath(groupVersion), &resources[index])
-			if err != nil {
-				return err
-			}
-			resObjects.Items = append(resObjects.Items, resObject.Items...)
-
-			if l.CheckIsOpenshift() {
-				ocpObj, oErr := l.getOcpResourcesByOwnerRef(getAPIGroupVersionResourcePath(groupVersion), &resources[index])
-				if oErr != nil {
-					return oErr
-				}
-				resObjects.Items = append(resObjects.Items, ocpObj.Items...)
-			}
+		if l.selection.isExcluded(resource.Kind) {
+			l.logger().Infof("Skipping excluded '%s' Resource", resource.Kind)
+			continue
+		}
 
-			resourceMap, err := l.writeObjectsAndLogs(resObjects, resources[index].Kind)
+		if groupVersion == TriliovaultGroupVersion {
+			err := l.workers.run(func() error {
+				return l.getTrilioGroupResources([]apiv1.APIResource{*resource}, groupVersion)
+			})
 			if err != nil {
 				return err
 			}
+			continue
+		}
 
-			for kind, NsName := range resourceMap {
-				resourceMapList[kind] = NsName
-			}
+		var resObjects unstructured.UnstructuredList
+		err := l.workers.run(func() error {
+			var fErr error
+			resObjects, fErr = l.fetchResourceObjects(groupVersion, resource)
+			return fErr
+		})
+		if err != nil {
+			return err
 		}
-	}
 
-	err := l.getResourceEvents(&eventResource, resourceMapList)
-	if err != nil {
-		return err
+		if err = l.writeObjectsAndLogs(resObjects, resource.Kind); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// writeObjectsAndLogs writes objects YAML and logs to file
-func (l *LogCollector) writeObjectsAndLogs(objects unstructured.UnstructuredList, kind string) (map[string][]types.NamespacedName, error) {
+// fetchResourceObjects fetches objects of 'resource' of 'groupVersion' which are included or filtered, along with
+// objects owned by them on OpenShift
+func (l *LogCollector) fetchResourceObjects(groupVersion string, resource *apiv1.APIResource) (
+	unstructured.UnstructuredList, error) {
+
+	var (
+		resObjects unstructured.UnstructuredList
+		resObject  unstructured.UnstructuredList
+		err        error
+	)
+	included := l.selection.isIncluded(groupVersion, resource.Kind)
+	if included {
+		l.logger().Infof("Fetching included '%s' Resource", resource.Kind)
+		resObject, err = l.getResourceObjects(getAPIGroupVersionResourcePath(groupVersion), resource)
+	} else {
+		resObject, err = l.filterResourceObjects(getAPIGroupVersionResourcePath(groupVersion), resource)
+	}
+	if err != nil {
+		return resObjects, err
+	}
+	resObjects.Items = append(resObjects.Items, resObject.Items...)
 
-	var nsName []types.NamespacedName
-	resourceMap := make(map[string][]types.NamespacedName)
+	if l.isOpenshift && !included {
+		ocpObj, oErr := l.getOcpResourcesByOwnerRef(getAPIGroupVersionResourcePath(groupVersion), resource)
+		if oErr != nil {
+			return resObjects, oErr
+		}
+		resObjects.Items = append(resObjects.Items, ocpObj.Items...)
+	}
+	return resObjects, nil
+}
 
-	for _, obj := range objects.Items {
-		oName := obj.GetName()
-		oNs := obj.GetNamespace()
+// writeObjectsAndLogs writes objects YAML and logs to file. Pod logs are collected in parallel using workers of
+// collector.
+func (l *LogCollector) writeObjectsAndLogs(objects unstructured.UnstructuredList, kind string) error {
 
-		nsName = append(nsName, types.NamespacedName{Name: oName, Namespace: oNs})
-		resourceMap[kind] = nsName
+	for index := range objects.Items {
+		l.csi.record(&objects.Items[index])
+	}
 
-		resourceDir := filepath.Join(kind)
-		if kind == Pod {
-			eLrr := l.writeLogs(resourceDir, obj)
-			if eLrr != nil {
-				return resourceMap, eLrr
+	resourceDir := filepath.Join(kind)
+	if kind == Pod {
+		for index := range objects.Items {
+			l.summary.addPod(&objects.Items[index])
+		}
+	}
+	if kind == Pod && !l.selection.skipLogs {
+		err := runParallel(l.Parallelism, len(objects.Items), func(index int) error {
+			lErr := l.workers.run(func() error {
+				return l.writeLogs(resourceDir, objects.Items[index])
+			})
+			if lErr != nil {
+				return l.handleError(podLogResource, objects.Items[index].GetNamespace(), lErr)
 			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
+	}
+
+	for _, obj := range objects.Items {
 		eLrr := l.writeYaml(resourceDir, obj)
 		if eLrr != nil {
-			return resourceMap, eLrr
+			return eLrr
 		}
 	}
 
-	return resourceMap, nil
+	return nil
 }
 
 // getTrilioGroupResources collects all the resources related to trilio and writes the YAML
 func (l *LogCollector) getTrilioGroupResources(trilioGVResources []apiv1.APIResource, groupVersion string) error {
-	log.Info("Checking Trilio Group")
+	l.logger().Info("Checking Trilio Group")
 	for index := range trilioGVResources {
 		objectList, err := l.getResourceObjects(getAPIGroupVersionResourcePath(groupVersion), &trilioGVResources[index])
 		if err != nil {
@@ -513,15 +673,15 @@ func (l *LogCollector) getTrilioGroupResources(trilioGVResources []apiv1.APIReso
 func (l *LogCollector) getAPIResourceList() (map[string][]apiv1.APIResource, error) {
 
 	resourceMapList := make(map[string][]apiv1.APIResource)
-	log.Info("Fetching API Group version list")
+	l.logger().Info("Fetching API Group version list")
 	_, resourceList, err := l.disClient.ServerGroupsAndResources()
 	if err != nil {
 		if !discovery.IsGroupDiscoveryFailedError(err) {
-			log.Error(err, "Error while getting the resource list from discovery client")
+			l.logger().Error(err, "Error while getting the resource list from discovery client")
 			return resourceMapList, err
 		}
-		log.Warnf("The Kubernetes server has an orphaned API service. Server reports: %s", err.Error())
-		log.Warn("To fix this, kubectl delete apiservice <service-name>")
+		l.logger().Warnf("The Kubernetes server has an orphaned API service. Server reports: %s", err.Error())
+		l.logger().Warn("To fix this, kubectl delete apiservice <service-name>")
 	}
 
 	for _, resources := range resourceList {
@@ -563,12 +723,12 @@ func (l *LogCollector) getOcpResourcesByOwnerRef(resourcePath string,
 		if object.GetKind() == SubscriptionKind {
 			startingCSV, _, err := unstructured.NestedString(object.Object, "spec", "startingCSV")
 			if err != nil {
-				log.Errorf("Unable to get startingCSV : %s", err.Error())
+				l.logger().Errorf("Unable to get startingCSV : %s", err.Error())
 				return objects, err
 			}
 			name, _, nErr := unstructured.NestedString(object.Object, "spec", "name")
 			if nErr != nil {
-				log.Errorf("Unable to get name : %s", nErr.Error())
+				l.logger().Errorf("Unable to get name : %s", nErr.Error())
 				return objects, err
 			}
 
@@ -597,14 +757,14 @@ func (l *LogCollector) getOcpResourcesByOwnerRef(resourcePath string,
 // checkIfNamespacesExist take all given namespaces from user and checks the same in cluster if it exist
 func (l *LogCollector) checkIfNamespacesExist() (err error) {
 
-	log.Info("Checking if given namespaces are valid")
+	l.logger().Info("Checking if given namespaces are valid")
 	set := make(sets.String)
 	var nonExistNs []string
 
 	var namespaces corev1.NamespaceList
 	err = l.k8sClient.List(context.Background(), &namespaces)
 	if err != nil {
-		log.Errorf("%s", err.Error())
+		l.logger().Errorf("%s", err.Error())
 		return err
 	}
 
@@ -625,25 +785,3 @@ func (l *LogCollector) checkIfNamespacesExist() (err error) {
 
 	return nil
 }
-
-// getResourceEvents write YAML's for all events of resources related to trilio
-func (l *LogCollector) getResourceEvents(eventResource *apiv1.APIResource, resourceMap map[string][]types.NamespacedName) error {
-
-	eventObjects, err := l.getResourceObjects(getAPIGroupVersionResourcePath(CoreGv), eventResource)
-	if err != nil {
-		log.Errorf("Unable to Write Events : %s", err.Error())
-		return err
-	}
-	events, aErr := aggregateEvents(eventObjects, resourceMap)
-	if aErr != nil {
-		log.Errorf("Unable to process Events : %s", aErr.Error())
-		return aErr
-	}
-
-	eErr := l.writeEventsToFile(events)
-	if eErr != nil {
-		log.Errorf("Unable to Write Events : %s", eErr.Error())
-		return eErr
-	}
-	return nil
-}
@@ -0,0 +1,76 @@
+package logcollector
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLogTruncation(t *testing.T) {
+	now := time.Date(2021, 6, 8, 12, 0, 0, 0, time.UTC)
+	sinceTime := now.Add(-2 * time.Hour)
+
+	tests := []struct {
+		name      string
+		collector *LogCollector
+		written   int64
+		lines     int64
+		started   time.Time
+		want      []string
+	}{
+		{name: "no bounds", collector: &LogCollector{}, written: 1 << 20, lines: 1000, started: now.Add(-24 * time.Hour)},
+		{name: "container started before since", collector: &LogCollector{LogSince: time.Hour}, written: 10, lines: 1,
+			started: now.Add(-3 * time.Hour), want: []string{logBoundSince}},
+		{name: "container started after since", collector: &LogCollector{LogSince: time.Hour}, written: 10, lines: 1,
+			started: now.Add(-30 * time.Minute)},
+		{name: "container started before since time", collector: &LogCollector{LogSinceTime: &sinceTime}, written: 10,
+			lines: 1, started: now.Add(-3 * time.Hour), want: []string{logBoundSince}},
+		{name: "unknown start time", collector: &LogCollector{LogSince: time.Hour}, written: 10, lines: 1},
+		{name: "tail lines reached", collector: &LogCollector{LogTail: 100}, written: 1000, lines: 100,
+			want: []string{logBoundTail}},
+		{name: "less than tail lines", collector: &LogCollector{LogTail: 100}, written: 1000, lines: 99},
+		{name: "limit bytes reached", collector: &LogCollector{LogLimitBytes: 1024}, written: 1024, lines: 10,
+			want: []string{logBoundLimitBytes}},
+		{name: "all bounds", collector: &LogCollector{LogSince: time.Hour, LogTail: 10, LogLimitBytes: 1024},
+			written: 2048, lines: 10, started: now.Add(-2 * time.Hour),
+			want: []string{logBoundSince, logBoundTail, logBoundLimitBytes}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.collector.logTruncation(tt.written, tt.lines, tt.started, now)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("logTruncation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineCountWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   int64
+	}{
+		{name: "empty log", want: 0},
+		{name: "complete lines", writes: []string{"a\n", "b\n"}, want: 2},
+		{name: "last line without newline", writes: []string{"a\nb"}, want: 2},
+		{name: "line split across writes", writes: []string{"a", "b\n", "c"}, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := &lineCountWriter{Writer: &buf}
+			for _, write := range tt.writes {
+				if _, err := w.Write([]byte(write)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := w.count(); got != tt.want {
+				t.Errorf("count() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
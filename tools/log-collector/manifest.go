@@ -0,0 +1,96 @@
+package logcollector
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	LogManifestFile = "log-manifest.yaml"
+
+	logBoundSince      = "since"
+	logBoundTail       = "tail"
+	logBoundLimitBytes = "limitBytes"
+)
+
+// logManifest records pod logs collected along with the bounds applied, so that truncated logs can be identified
+type logManifest struct {
+	mutex sync.Mutex
+	logs  []logManifestEntry
+}
+
+type logManifestEntry struct {
+	Path      string `json:"path"`
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	// ContainerType is init or ephemeral for logs of init and ephemeral containers
+	ContainerType string `json:"containerType,omitempty"`
+	Previous      bool   `json:"previous"`
+	Bytes         int64  `json:"bytes"`
+	// TruncatedBy are bounds which cut the log, any of since, tail or limitBytes. Log is cut by since bounds if
+	// container started before them, by tail if it has tail lines and by limit-bytes if it reached limit-bytes.
+	TruncatedBy []string `json:"truncatedBy,omitempty"`
+}
+
+type logManifestFile struct {
+	Since      string             `json:"since,omitempty"`
+	SinceTime  string             `json:"sinceTime,omitempty"`
+	Tail       *int64             `json:"tail,omitempty"`
+	LimitBytes *int64             `json:"limitBytes,omitempty"`
+	Truncated  int                `json:"truncated"`
+	Logs       []logManifestEntry `json:"logs"`
+}
+
+// add records log entry, it is safe to be called concurrently
+func (m *logManifest) add(entry logManifestEntry) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.logs = append(m.logs, entry)
+}
+
+// writeLogManifest writes manifest of collected pod logs sorted by path
+func (l *LogCollector) writeLogManifest() error {
+	l.logManifest.mutex.Lock()
+	logs := append([]logManifestEntry{}, l.logManifest.logs...)
+	l.logManifest.mutex.Unlock()
+
+	sort.Slice(logs, func(i, j int) bool {
+		return logs[i].Path < logs[j].Path
+	})
+
+	manifest := logManifestFile{Logs: logs}
+	if l.LogSince > 0 {
+		manifest.Since = l.LogSince.String()
+	}
+	if l.LogSinceTime != nil {
+		manifest.SinceTime = l.LogSinceTime.Format(time.RFC3339)
+	}
+	if l.LogTail > 0 {
+		tail := l.LogTail
+		manifest.Tail = &tail
+	}
+	if l.LogLimitBytes > 0 {
+		limitBytes := l.LogLimitBytes
+		manifest.LimitBytes = &limitBytes
+	}
+	for idx := range logs {
+		if len(logs[idx].TruncatedBy) != 0 {
+			manifest.Truncated++
+		}
+	}
+	if manifest.Truncated > 0 {
+		l.logger().Warnf("%d pod logs are truncated by since, tail or limit-bytes bounds, refer %s", manifest.Truncated,
+			LogManifestFile)
+	}
+
+	buf, err := yaml.Marshal(manifest)
+	if err != nil {
+		l.logger().Errorf("Unable to marshal the content : %s", err.Error())
+		return err
+	}
+	return l.writeFile(LogManifestFile, buf)
+}
@@ -0,0 +1,204 @@
+package logcollector
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+const (
+	MetricsGroup    = "metrics.k8s.io"
+	PodMetricsKind  = "PodMetrics"
+	NodeMetricsKind = "NodeMetrics"
+
+	oomKilled = "OOMKilled"
+)
+
+var (
+	podMetricsGVK  = schema.GroupVersionKind{Group: MetricsGroup, Version: "v1beta1", Kind: PodMetricsKind}
+	nodeMetricsGVK = schema.GroupVersionKind{Group: MetricsGroup, Version: "v1beta1", Kind: NodeMetricsKind}
+)
+
+// containerSummary is resource requests, limits and usage of a container of pod
+type containerSummary struct {
+	Name          string `json:"name"`
+	CPURequest    string `json:"cpuRequest,omitempty"`
+	CPULimit      string `json:"cpuLimit,omitempty"`
+	CPUUsage      string `json:"cpuUsage,omitempty"`
+	MemoryRequest string `json:"memoryRequest,omitempty"`
+	MemoryLimit   string `json:"memoryLimit,omitempty"`
+	MemoryUsage   string `json:"memoryUsage,omitempty"`
+	OOMKilled     bool   `json:"oomKilled,omitempty"`
+}
+
+// containerUsage is usage of a container reported by metrics API
+type containerUsage struct {
+	cpu    string
+	memory string
+}
+
+// getContainerSummaries returns requests, limits and OOMKilled termination of containers of 'pod'
+func getContainerSummaries(pod *corev1.Pod) []containerSummary {
+	oomKilledContainers := sets.NewString()
+	for idx := range pod.Status.ContainerStatuses {
+		status := &pod.Status.ContainerStatuses[idx]
+		if (status.State.Terminated != nil && status.State.Terminated.Reason == oomKilled) ||
+			(status.LastTerminationState.Terminated != nil && status.LastTerminationState.Terminated.Reason == oomKilled) {
+			oomKilledContainers.Insert(status.Name)
+		}
+	}
+
+	containers := make([]containerSummary, 0, len(pod.Spec.Containers))
+	for idx := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[idx]
+		containers = append(containers, containerSummary{
+			Name:          container.Name,
+			CPURequest:    formatCPU(container.Resources.Requests.Cpu()),
+			CPULimit:      formatCPU(container.Resources.Limits.Cpu()),
+			MemoryRequest: formatMemory(container.Resources.Requests.Memory()),
+			MemoryLimit:   formatMemory(container.Resources.Limits.Memory()),
+			OOMKilled:     oomKilledContainers.Has(container.Name),
+		})
+	}
+	return containers
+}
+
+// collectMetrics collects PodMetrics of namespaces of collected pods and NodeMetrics of nodes running them, when
+// metrics API is available. Usage of collected pods is recorded for summary.
+func (l *LogCollector) collectMetrics() error {
+	if !internal.CheckIfAPIVersionKindAvailable(l.disClient, podMetricsGVK) {
+		l.logger().Info("Metrics API not available, skipping metrics collection")
+		return nil
+	}
+
+	namespaces, nodes := l.summary.podNamespacesAndNodes()
+	if namespaces.Len() == 0 {
+		return nil
+	}
+
+	if !l.selection.isExcluded(PodMetricsKind) {
+		l.logger().Infof("Fetching '%s' Resource", PodMetricsKind)
+		for _, namespace := range namespaces.List() {
+			podMetrics, err := l.listMetrics(podMetricsGVK, namespace)
+			if err != nil {
+				if hErr := l.handleError(PodMetricsKind, namespace, err); hErr != nil {
+					return hErr
+				}
+				continue
+			}
+			for idx := range podMetrics {
+				l.summary.addPodUsage(&podMetrics[idx])
+				if wErr := l.writeYaml(PodMetricsKind, podMetrics[idx]); wErr != nil {
+					return wErr
+				}
+			}
+		}
+	}
+
+	if l.selection.isExcluded(NodeMetricsKind) || nodes.Len() == 0 ||
+		!internal.CheckIfAPIVersionKindAvailable(l.disClient, nodeMetricsGVK) {
+		return nil
+	}
+	l.logger().Infof("Fetching '%s' Resource", NodeMetricsKind)
+	nodeMetrics, err := l.listMetrics(nodeMetricsGVK, "")
+	if err != nil {
+		return l.handleError(NodeMetricsKind, "", err)
+	}
+	for idx := range nodeMetrics {
+		if !nodes.Has(nodeMetrics[idx].GetName()) {
+			continue
+		}
+		if wErr := l.writeYaml(NodeMetricsKind, nodeMetrics[idx]); wErr != nil {
+			return wErr
+		}
+	}
+	return nil
+}
+
+// listMetrics lists metrics of 'gvk' in 'namespace', all namespaces or cluster scoped metrics if 'namespace' is empty
+func (l *LogCollector) listMetrics(gvk schema.GroupVersionKind, namespace string) ([]unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err := l.k8sClient.List(context.Background(), list, client.InNamespace(namespace)); err != nil {
+		l.logger().Errorf("Unable to list %s : %s", gvk.Kind, err.Error())
+		return nil, err
+	}
+	for idx := range list.Items {
+		list.Items[idx].SetGroupVersionKind(gvk)
+	}
+	return list.Items, nil
+}
+
+// podNamespacesAndNodes returns namespaces and nodes of recorded pods
+func (s *summaryRecorder) podNamespacesAndNodes() (namespaces, nodes sets.String) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	namespaces, nodes = sets.NewString(), sets.NewString()
+	for idx := range s.pods {
+		namespaces.Insert(s.pods[idx].Namespace)
+		if s.pods[idx].Node != "" {
+			nodes.Insert(s.pods[idx].Node)
+		}
+	}
+	return namespaces, nodes
+}
+
+// addPodUsage records container usage of PodMetrics 'obj'
+func (s *summaryRecorder) addPodUsage(obj *unstructured.Unstructured) {
+	containers, _, _ := unstructured.NestedSlice(obj.Object, "containers")
+	usage := make(map[string]containerUsage, len(containers))
+	for _, container := range containers {
+		containerMap, ok := container.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(containerMap, "name")
+		cpu, _, _ := unstructured.NestedString(containerMap, "usage", "cpu")
+		memory, _, _ := unstructured.NestedString(containerMap, "usage", "memory")
+		usage[name] = containerUsage{cpu: formatQuantity(cpu, formatCPU), memory: formatQuantity(memory, formatMemory)}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.usage == nil {
+		s.usage = make(map[string]map[string]containerUsage)
+	}
+	s.usage[types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}.String()] = usage
+}
+
+func formatQuantity(value string, format func(*resource.Quantity) string) string {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return value
+	}
+	return format(&quantity)
+}
+
+// formatCPU formats CPU quantity in millicores, empty for zero quantity
+func formatCPU(quantity *resource.Quantity) string {
+	if quantity.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%dm", quantity.MilliValue())
+}
+
+// formatMemory formats memory quantity in mebibytes, quantity below a mebibyte is rounded up in kibibytes, empty for
+// zero quantity
+func formatMemory(quantity *resource.Quantity) string {
+	if quantity.IsZero() {
+		return ""
+	}
+	if value := quantity.Value(); value < 1<<20 {
+		return fmt.Sprintf("%dKi", (value+(1<<10)-1)/(1<<10))
+	}
+	return fmt.Sprintf("%dMi", quantity.Value()/(1<<20))
+}
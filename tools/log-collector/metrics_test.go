@@ -0,0 +1,87 @@
+package logcollector
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGetContainerSummaries(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "app", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("0.25"),
+					corev1.ResourceMemory: resource.MustParse("128Mi")},
+				Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("1Gi")},
+			}},
+			{Name: "sidecar"},
+			{Name: "restarted"},
+		}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "app", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: oomKilled}}},
+			{Name: "sidecar", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}}},
+			{Name: "restarted", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: oomKilled}}},
+		}},
+	}
+
+	want := []containerSummary{
+		{Name: "app", CPURequest: "250m", CPULimit: "1000m", MemoryRequest: "128Mi", MemoryLimit: "1024Mi", OOMKilled: true},
+		{Name: "sidecar"},
+		{Name: "restarted", OOMKilled: true},
+	}
+	if got := getContainerSummaries(pod); !reflect.DeepEqual(got, want) {
+		t.Errorf("getContainerSummaries() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFormatQuantity(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		format func(*resource.Quantity) string
+		want   string
+	}{
+		{name: "cpu in nanocores", value: "1500000n", format: formatCPU, want: "2m"},
+		{name: "cpu in cores", value: "2", format: formatCPU, want: "2000m"},
+		{name: "memory in kibibytes", value: "262144Ki", format: formatMemory, want: "256Mi"},
+		{name: "memory below a mebibyte", value: "1000000", format: formatMemory, want: "977Ki"},
+		{name: "memory below a kibibyte", value: "100", format: formatMemory, want: "1Ki"},
+		{name: "memory of a mebibyte", value: "1048576", format: formatMemory, want: "1Mi"},
+		{name: "zero quantity", value: "0", format: formatMemory, want: ""},
+		{name: "invalid quantity", value: "many", format: formatCPU, want: "many"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatQuantity(tt.value, tt.format); got != tt.want {
+				t.Errorf("formatQuantity(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddPodUsage(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":     PodMetricsKind,
+		"metadata": map[string]interface{}{"namespace": "app", "name": "web"},
+		"containers": []interface{}{
+			map[string]interface{}{"name": "web", "usage": map[string]interface{}{"cpu": "12500000n", "memory": "65536Ki"}},
+			map[string]interface{}{"name": "idle", "usage": map[string]interface{}{}},
+		},
+	}}
+
+	var s summaryRecorder
+	s.addPodUsage(obj)
+	want := map[string]map[string]containerUsage{"app/web": {
+		"web":  {cpu: "13m", memory: "64Mi"},
+		"idle": {},
+	}}
+	if !reflect.DeepEqual(s.usage, want) {
+		t.Errorf("addPodUsage() recorded %+v, want %+v", s.usage, want)
+	}
+}
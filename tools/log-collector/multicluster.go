@@ -0,0 +1,255 @@
+package logcollector
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	ClustersDir                 = "clusters"
+	ClustersSummaryJSONFile     = "clusters-summary.json"
+	ClustersSummaryMarkdownFile = "CLUSTERS.md"
+)
+
+// clusterDirChars matches characters of context names which are replaced in names of cluster directories
+var clusterDirChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// clustersSummary is the index of a multi-cluster bundle written at its root
+type clustersSummary struct {
+	StartTime time.Time `json:"startTime"`
+	Duration  string    `json:"duration"`
+	// TVKVersions are distinct TVK versions found across clusters
+	TVKVersions []string         `json:"tvkVersions"`
+	Clusters    []clusterSummary `json:"clusters"`
+	Warnings    []string         `json:"warnings"`
+	Errors      []string         `json:"errors"`
+}
+
+// clusterSummary is version and health of a cluster collected in multi-cluster collection
+type clusterSummary struct {
+	Context string `json:"context"`
+	// Directory is the directory of bundle cluster is collected into
+	Directory        string `json:"directory"`
+	Collected        bool   `json:"collected"`
+	Error            string `json:"error,omitempty"`
+	ClusterVersion   string `json:"clusterVersion"`
+	Openshift        bool   `json:"openshift"`
+	TVKVersion       string `json:"tvkVersion"`
+	Objects          int    `json:"objects"`
+	Pods             int    `json:"pods"`
+	NotReadyPods     int    `json:"notReadyPods"`
+	Restarts         int32  `json:"restarts"`
+	CollectionErrors int    `json:"collectionErrors"`
+	Duration         string `json:"duration"`
+}
+
+// collectClusters collects clusters of Contexts concurrently, each into its own directory under clusters, and
+// writes summary comparing them at bundle root
+func (l *LogCollector) collectClusters() error {
+	l.collectionLogger = l.summary.start()
+
+	if err := l.openBundle(); err != nil {
+		return err
+	}
+
+	dirs := clusterDirs(l.Contexts)
+	clusters := make([]clusterSummary, len(l.Contexts))
+	_ = runParallel(len(l.Contexts), len(l.Contexts), func(index int) error {
+		clusters[index] = l.collectCluster(l.Contexts[index], dirs[index])
+		return nil
+	})
+
+	cErr := l.writeClustersSummary(clusters)
+	if cErr == nil {
+		cErr = l.checkClusterErrors(clusters)
+	}
+	return l.closeBundle(cErr)
+}
+
+// collectCluster collects cluster of 'kubeContext' into 'dir' of bundle and returns its summary
+func (l *LogCollector) collectCluster(kubeContext, dir string) clusterSummary {
+	cluster := &LogCollector{
+		CleanOutput:     true,
+		Format:          l.Format,
+		Sink:            newPrefixSink(l.bundle, path.Join(ClustersDir, dir)),
+		Clustered:       l.Clustered,
+		Namespaces:      append([]string{}, l.Namespaces...),
+		Loglevel:        l.Loglevel,
+		KubeConfig:      l.KubeConfig,
+		Context:         kubeContext,
+		Parallelism:     l.Parallelism,
+		LogSince:        l.LogSince,
+		LogSinceTime:    l.LogSinceTime,
+		LogTail:         l.LogTail,
+		LogLimitBytes:   l.LogLimitBytes,
+		Backup:          l.Backup,
+		Restore:         l.Restore,
+		ContinueOnError: l.ContinueOnError,
+		Strict:          l.Strict,
+		Config:          l.Config,
+		cluster:         dir,
+		workers:         l.workers,
+	}
+
+	l.logger().Infof("Collecting cluster of context %s into %s", kubeContext, path.Join(ClustersDir, dir))
+	startTime := time.Now()
+	err := cluster.CollectLogsAndDump()
+	l.summary.addClusterMessages(kubeContext, &cluster.summary)
+
+	summary := cluster.clusterSummary(kubeContext, dir)
+	summary.Duration = time.Since(startTime).Round(time.Millisecond).String()
+	if err != nil {
+		l.logger().Errorf("Collection of cluster of context %s failed : %s", kubeContext, err.Error())
+		summary.Error = err.Error()
+		return summary
+	}
+	summary.Collected = true
+	l.logger().Infof("Collected cluster of context %s", kubeContext)
+	return summary
+}
+
+// clusterSummary returns version and health of cluster recorded during collection of 'kubeContext' into 'dir'
+func (l *LogCollector) clusterSummary(kubeContext, dir string) clusterSummary {
+	summary := clusterSummary{
+		Context:          kubeContext,
+		Directory:        path.Join(ClustersDir, dir),
+		Objects:          l.summary.collected(),
+		CollectionErrors: len(l.collectionErrors.list()),
+	}
+	if l.bundleSummary == nil {
+		return summary
+	}
+
+	summary.ClusterVersion = l.bundleSummary.ClusterVersion
+	summary.Openshift = l.bundleSummary.Openshift
+	summary.TVKVersion = l.bundleSummary.TVKVersion
+	summary.Pods = len(l.bundleSummary.Pods)
+	for idx := range l.bundleSummary.Pods {
+		pod := &l.bundleSummary.Pods[idx]
+		summary.Restarts += pod.Restarts
+		if pod.Phase != string(corev1.PodSucceeded) &&
+			(pod.Phase != string(corev1.PodRunning) || len(pod.NotReadyContainers) != 0) {
+			summary.NotReadyPods++
+		}
+	}
+	return summary
+}
+
+// writeClustersSummary writes clusters-summary.json and CLUSTERS.md at bundle root
+func (l *LogCollector) writeClustersSummary(clusters []clusterSummary) error {
+	tvkVersions := sets.NewString()
+	for idx := range clusters {
+		if clusters[idx].TVKVersion != "" {
+			tvkVersions.Insert(clusters[idx].TVKVersion)
+		}
+	}
+
+	l.summary.mutex.Lock()
+	summary := &clustersSummary{
+		StartTime:   l.summary.startTime,
+		Duration:    time.Since(l.summary.startTime).Round(time.Millisecond).String(),
+		TVKVersions: tvkVersions.List(),
+		Clusters:    clusters,
+		Warnings:    append([]string{}, l.summary.warnings...),
+		Errors:      append([]string{}, l.summary.errors...),
+	}
+	l.summary.mutex.Unlock()
+
+	buf, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		l.logger().Errorf("Unable to marshal the content : %s", err.Error())
+		return err
+	}
+	if err = l.writeFile(ClustersSummaryJSONFile, buf); err != nil {
+		return err
+	}
+
+	return l.writeFile(ClustersSummaryMarkdownFile, []byte(summary.markdown()))
+}
+
+// checkClusterErrors returns error if collection of any cluster failed, in continue-on-error mode failed clusters
+// are only warned unless collection of all clusters failed or strict mode is enabled
+func (l *LogCollector) checkClusterErrors(clusters []clusterSummary) error {
+	var failed []string
+	for idx := range clusters {
+		if !clusters[idx].Collected {
+			failed = append(failed, clusters[idx].Context)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	if !l.ContinueOnError || l.Strict || len(failed) == len(clusters) {
+		return errors.Errorf("collection of %d clusters of %d failed %v, refer %s in bundle", len(failed),
+			len(clusters), failed, ClustersSummaryMarkdownFile)
+	}
+	l.logger().Warnf("Collection of %d clusters of %d failed %v, refer %s in bundle", len(failed), len(clusters), failed,
+		ClustersSummaryMarkdownFile)
+	return nil
+}
+
+// clusterDirs returns distinct directory names of 'contexts', characters other than alphanumerics, '.', '_' and '-'
+// are replaced by '-'
+func clusterDirs(contexts []string) []string {
+	dirs := make([]string, len(contexts))
+	used := sets.NewString()
+	for idx, kubeContext := range contexts {
+		dir := strings.Trim(clusterDirChars.ReplaceAllString(kubeContext, "-"), "-.")
+		if dir == "" {
+			dir = "cluster"
+		}
+		unique := dir
+		for suffix := 2; used.Has(unique); suffix++ {
+			unique = fmt.Sprintf("%s-%d", dir, suffix)
+		}
+		used.Insert(unique)
+		dirs[idx] = unique
+	}
+	return dirs
+}
+
+// markdown renders summary of clusters as human readable markdown
+func (s *clustersSummary) markdown() string {
+	var b strings.Builder
+
+	b.WriteString("# Log Collector Multi-Cluster Summary\n\n")
+	fmt.Fprintf(&b, "| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Started At | %s |\n", s.StartTime.Format(time.RFC3339))
+	fmt.Fprintf(&b, "| Duration | %s |\n", s.Duration)
+	fmt.Fprintf(&b, "| Clusters | %d |\n", len(s.Clusters))
+	tvkVersions := valueOrUnknown(strings.Join(s.TVKVersions, ", "))
+	if len(s.TVKVersions) > 1 {
+		tvkVersions += " (differ across clusters)"
+	}
+	fmt.Fprintf(&b, "| TVK Versions | %s |\n", tvkVersions)
+
+	b.WriteString("\n## Clusters\n\n| Context | Directory | Collected | Cluster Version | OpenShift | TVK Version | Objects " +
+		"| Pods | Not Ready Pods | Restarts | Collection Errors | Duration |\n|---|---|---|---|---|---|---|---|---|---|---|---|\n")
+	for idx := range s.Clusters {
+		cluster := &s.Clusters[idx]
+		fmt.Fprintf(&b, "| %s | %s | %t | %s | %t | %s | %d | %d | %d | %d | %d | %s |\n", cluster.Context,
+			cluster.Directory, cluster.Collected, valueOrUnknown(cluster.ClusterVersion), cluster.Openshift,
+			valueOrUnknown(cluster.TVKVersion), cluster.Objects, cluster.Pods, cluster.NotReadyPods, cluster.Restarts,
+			cluster.CollectionErrors, cluster.Duration)
+	}
+
+	var failures []string
+	for idx := range s.Clusters {
+		if s.Clusters[idx].Error != "" {
+			failures = append(failures, fmt.Sprintf("%s : %s", s.Clusters[idx].Context, s.Clusters[idx].Error))
+		}
+	}
+	writeMarkdownList(&b, "Failed Clusters", failures)
+	writeMarkdownList(&b, "Warnings", s.Warnings)
+	writeMarkdownList(&b, "Errors", s.Errors)
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package logcollector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClusterDirs(t *testing.T) {
+	tests := []struct {
+		name     string
+		contexts []string
+		dirs     []string
+	}{
+		{name: "plain contexts", contexts: []string{"prod", "stage_1.eu"}, dirs: []string{"prod", "stage_1.eu"}},
+		{name: "contexts with special characters", contexts: []string{"arn:aws:eks:us-east-1:123:cluster/prod", "admin@kind"},
+			dirs: []string{"arn-aws-eks-us-east-1-123-cluster-prod", "admin-kind"}},
+		{name: "contexts without allowed characters", contexts: []string{"", "@@", ".-"},
+			dirs: []string{"cluster", "cluster-2", "cluster-3"}},
+		{name: "contexts mapped to same directory", contexts: []string{"a/b", "a:b", "a-b"},
+			dirs: []string{"a-b", "a-b-2", "a-b-3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clusterDirs(tt.contexts); !reflect.DeepEqual(got, tt.dirs) {
+				t.Errorf("clusterDirs(%v) = %v, want %v", tt.contexts, got, tt.dirs)
+			}
+		})
+	}
+}
+
+func TestCheckClusterErrors(t *testing.T) {
+	clusters := []clusterSummary{{Context: "prod", Collected: true}, {Context: "stage", Error: "unreachable"}}
+	failedAll := []clusterSummary{{Context: "prod", Error: "unreachable"}, {Context: "stage", Error: "unreachable"}}
+
+	tests := []struct {
+		name      string
+		collector *LogCollector
+		clusters  []clusterSummary
+		wantErr   bool
+	}{
+		{name: "all clusters collected", collector: &LogCollector{}, clusters: clusters[:1]},
+		{name: "failed cluster", collector: &LogCollector{}, clusters: clusters, wantErr: true},
+		{name: "failed cluster in continue-on-error mode", collector: &LogCollector{ContinueOnError: true},
+			clusters: clusters},
+		{name: "failed cluster in strict mode", collector: &LogCollector{ContinueOnError: true, Strict: true},
+			clusters: clusters, wantErr: true},
+		{name: "all clusters failed in continue-on-error mode", collector: &LogCollector{ContinueOnError: true},
+			clusters: failedAll, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.collector.checkClusterErrors(tt.clusters); (err != nil) != tt.wantErr {
+				t.Errorf("checkClusterErrors() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClustersSummaryMarkdown(t *testing.T) {
+	summary := &clustersSummary{
+		TVKVersions: []string{"2.0.0", "2.1.0"},
+		Clusters: []clusterSummary{
+			{Context: "prod", Directory: "clusters/prod", Collected: true, ClusterVersion: "v1.20.2", TVKVersion: "2.1.0",
+				Objects: 10, Pods: 3, NotReadyPods: 1, Restarts: 2, Duration: "1s"},
+			{Context: "stage", Directory: "clusters/stage", Error: "unreachable"},
+		},
+	}
+
+	markdown := summary.markdown()
+	for _, want := range []string{
+		"| TVK Versions | 2.0.0, 2.1.0 (differ across clusters) |",
+		"| prod | clusters/prod | true | v1.20.2 | false | 2.1.0 | 10 | 3 | 1 | 2 | 0 | 1s |",
+		"stage : unreachable",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("markdown() does not contain %q:\n%s", want, markdown)
+		}
+	}
+}
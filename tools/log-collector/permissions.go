@@ -0,0 +1,378 @@
+package logcollector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+const (
+	verbGet           = "get"
+	logSubresource    = "log"
+	allNamespaces     = "*"
+	allowedMark       = "yes"
+	deniedMark        = "NO"
+	notApplicableMark = "-"
+)
+
+// PermissionCheck is access of current user to a verb on resource in namespace, checked by SelfSubjectAccessReview.
+// Namespace is empty for cluster scoped resources and namespaced resources listed in all namespaces.
+type PermissionCheck struct {
+	Group       string `json:"group"`
+	Resource    string `json:"resource"`
+	Subresource string `json:"subresource,omitempty"`
+	Verb        string `json:"verb"`
+	Namespace   string `json:"namespace,omitempty"`
+	Allowed     bool   `json:"allowed"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// resourceName returns resource with subresource of check, e.g. pods/log
+func (p *PermissionCheck) resourceName() string {
+	if p.Subresource != "" {
+		return p.Resource + "/" + p.Subresource
+	}
+	return p.Resource
+}
+
+// PermissionReport is the result of dry run, access checks of everything collection would read and the minimal
+// ClusterRole granting them
+type PermissionReport struct {
+	Checks      []PermissionCheck   `json:"checks"`
+	ClusterRole *rbacv1.ClusterRole `json:"clusterRole"`
+}
+
+// Denied returns checks which aren't allowed
+func (r *PermissionReport) Denied() []PermissionCheck {
+	var denied []PermissionCheck
+	for idx := range r.Checks {
+		if !r.Checks[idx].Allowed {
+			denied = append(denied, r.Checks[idx])
+		}
+	}
+	return denied
+}
+
+// DryRun enumerates resources collection would read using discovery, without collecting them, and checks access of
+// current user to list, get and pod log verbs on them with SelfSubjectAccessReviews
+func (l *LogCollector) DryRun() (*PermissionReport, error) {
+	checks, err := l.planChecks()
+	if err != nil {
+		return nil, err
+	}
+
+	l.logger().Infof("Checking access to %d resource verbs", len(checks))
+	err = runParallel(l.Parallelism, len(checks), func(index int) error {
+		return l.reviewAccess(&checks[index])
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PermissionReport{Checks: checks, ClusterRole: minimalClusterRole(checks)}, nil
+}
+
+// ClusterRoleRules returns rules of minimal ClusterRole granting everything collection would read, planned like
+// DryRun without checking access of current user
+func (l *LogCollector) ClusterRoleRules() ([]rbacv1.PolicyRule, error) {
+	checks, err := l.planChecks()
+	if err != nil {
+		return nil, err
+	}
+	return minimalClusterRole(checks).Rules, nil
+}
+
+// planChecks enumerates resources of cluster collection would read and returns access checks of them
+func (l *LogCollector) planChecks() ([]PermissionCheck, error) {
+	if err := l.initializeKubeClients(); err != nil {
+		return nil, err
+	}
+	selection, err := newResourceSelection(&l.Config.Resources)
+	if err != nil {
+		return nil, err
+	}
+	l.selection = selection
+
+	resourceGroup, err := l.getAPIResourceList()
+	if err != nil {
+		return nil, err
+	}
+	return l.plannedChecks(resourceGroup), nil
+}
+
+// plannedChecks returns access checks of resources read by collection, in order of group, resource and namespace
+func (l *LogCollector) plannedChecks(resourceGroup map[string][]apiv1.APIResource) []PermissionCheck {
+	namespaces := l.dryRunNamespaces()
+	planned := make(map[PermissionCheck]bool)
+	add := func(group string, resource *apiv1.APIResource, subresource, verb string) {
+		if !resource.Namespaced {
+			planned[PermissionCheck{Group: group, Resource: resource.Name, Subresource: subresource, Verb: verb}] = true
+			return
+		}
+		for _, namespace := range namespaces {
+			planned[PermissionCheck{Group: group, Resource: resource.Name, Subresource: subresource, Verb: verb,
+				Namespace: namespace}] = true
+		}
+	}
+	addKind := func(group, kind, subresource, verb string) {
+		if resource, ok := findResource(resourceGroup, group, kind); ok {
+			add(group, resource, subresource, verb)
+		}
+	}
+
+	// namespaces are listed to validate given namespaces
+	addKind("", "Namespace", "", Verblist)
+
+	isOpenshift := l.CheckIsOpenshift()
+	kindResources := groupResourcesByKind(resourceGroup)
+	for _, kindResource := range kindResources {
+		for idx := range kindResource.resources {
+			groupVersion, resource := kindResource.resources[idx].groupVersion, &kindResource.resources[idx].resource
+			if l.selection.isExcluded(resource.Kind) || !l.isListed(groupVersion, resource, isOpenshift) {
+				continue
+			}
+			add(schema.FromAPIVersionAndKind(groupVersion, resource.Kind).Group, resource, "", Verblist)
+		}
+	}
+
+	if eventResource, ok := findEventResource(resourceGroup); ok && !l.selection.isExcluded(eventResource.resource.Kind) {
+		group := schema.FromAPIVersionAndKind(eventResource.groupVersion, eventResource.resource.Kind).Group
+		add(group, &eventResource.resource, "", Verblist)
+		// events of cluster scoped objects are reported in default namespace
+		if !l.Clustered {
+			planned[PermissionCheck{Group: group, Resource: Events, Verb: Verblist, Namespace: apiv1.NamespaceDefault}] = true
+		}
+	}
+	if !l.selection.isExcluded(Pod) && !l.Config.Resources.SkipLogs {
+		addKind("", Pod, logSubresource, verbGet)
+	}
+	if !l.selection.isExcluded(HelmReleaseKind) {
+		addKind("", "Secret", "", Verblist)
+	}
+	if l.Backup != "" || l.Restore != "" {
+		for _, kind := range []string{internal.BackupKind, internal.BackupPlanKind, RestoreKind} {
+			addKind(internal.TriliovaultGroup, kind, "", verbGet)
+		}
+	}
+
+	// CSI diagnostics list storage classes, CSI objects, workloads of CSI plugins and their pods in namespaces of
+	// plugins which aren't known before collection
+	addKind(StorageGroup, StorageClassKind, "", Verblist)
+	for _, gvks := range csiKinds {
+		if !l.selection.isExcluded(gvks[0].Kind) {
+			addKind(gvks[0].Group, gvks[0].Kind, "", Verblist)
+		}
+	}
+	if resource, ok := findResource(resourceGroup, "", Pod); ok && !l.selection.isExcluded(Pod) {
+		planned[PermissionCheck{Resource: resource.Name, Verb: Verblist}] = true
+		for _, kind := range csiWorkloadKinds {
+			if workload, found := findResource(resourceGroup, appsv1.GroupName, kind); found {
+				planned[PermissionCheck{Group: appsv1.GroupName, Resource: workload.Name, Verb: Verblist}] = true
+			}
+		}
+	}
+	for _, kind := range []string{PodMetricsKind, NodeMetricsKind} {
+		if !l.selection.isExcluded(kind) {
+			addKind(MetricsGroup, kind, "", Verblist)
+		}
+	}
+
+	checks := make([]PermissionCheck, 0, len(planned))
+	for check := range planned {
+		checks = append(checks, check)
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		if checks[i].Group != checks[j].Group {
+			return checks[i].Group < checks[j].Group
+		}
+		if checks[i].resourceName() != checks[j].resourceName() {
+			return checks[i].resourceName() < checks[j].resourceName()
+		}
+		if checks[i].Verb != checks[j].Verb {
+			return checks[i].Verb < checks[j].Verb
+		}
+		return checks[i].Namespace < checks[j].Namespace
+	})
+	return checks
+}
+
+// dryRunNamespaces returns namespaces collection would read, along with namespaces of application of Backup or
+// Restore if they can be resolved. Empty namespace means all namespaces.
+func (l *LogCollector) dryRunNamespaces() []string {
+	if l.Clustered {
+		return []string{""}
+	}
+
+	namespaces := sets.NewString(l.Namespaces...)
+	if l.Backup != "" || l.Restore != "" {
+		appScope, err := l.resolveApplicationScope()
+		if err != nil {
+			l.logger().Warnf("Unable to resolve application namespaces, only given namespaces are checked : %s", err.Error())
+		} else if appScope != nil {
+			namespaces = namespaces.Union(appScope.namespaces)
+		}
+	}
+	return namespaces.List()
+}
+
+// isListed returns true if collection lists objects of 'resource', following filtering of collectKindResources
+func (l *LogCollector) isListed(groupVersion string, resource *apiv1.APIResource, isOpenshift bool) bool {
+	return groupVersion == TriliovaultGroupVersion || isOpenshift ||
+		l.selection.isIncluded(groupVersion, resource.Kind) ||
+		(!resource.Namespaced && clusteredResources.Has(resource.Kind)) ||
+		(resource.Namespaced && !excludeResources.Has(resource.Kind))
+}
+
+// findResource returns API resource of 'kind' in 'group' served by any version
+func findResource(resourceGroup map[string][]apiv1.APIResource, group, kind string) (*apiv1.APIResource, bool) {
+	for groupVersion, resources := range resourceGroup {
+		if schema.FromAPIVersionAndKind(groupVersion, kind).Group != group {
+			continue
+		}
+		for idx := range resources {
+			if resources[idx].Kind == kind && !strings.Contains(resources[idx].Name, "/") {
+				return &resources[idx], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// reviewAccess sets whether current user is allowed access of 'check' using a SelfSubjectAccessReview
+func (l *LogCollector) reviewAccess(check *PermissionCheck) error {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   check.Namespace,
+				Verb:        check.Verb,
+				Group:       check.Group,
+				Resource:    check.Resource,
+				Subresource: check.Subresource,
+			},
+		},
+	}
+	result, err := l.k8sClientSet.AuthorizationV1().SelfSubjectAccessReviews().Create(context.Background(), review,
+		apiv1.CreateOptions{})
+	if err != nil {
+		l.logger().Errorf("Unable to review access to %s %s : %s", check.Verb, check.resourceName(), err.Error())
+		return err
+	}
+	check.Allowed = result.Status.Allowed
+	check.Reason = result.Status.Reason
+	return nil
+}
+
+// minimalClusterRole returns ClusterRole granting all 'checks', resources of a group needing same verbs are
+// combined in a rule
+func minimalClusterRole(checks []PermissionCheck) *rbacv1.ClusterRole {
+	type groupResource struct{ group, resource string }
+	verbs := make(map[groupResource]sets.String)
+	for idx := range checks {
+		key := groupResource{group: checks[idx].Group, resource: checks[idx].resourceName()}
+		if verbs[key] == nil {
+			verbs[key] = sets.NewString()
+		}
+		verbs[key].Insert(checks[idx].Verb)
+	}
+
+	type groupVerbs struct{ group, verbs string }
+	rules := make(map[groupVerbs]*rbacv1.PolicyRule)
+	var keys []groupVerbs
+	for key, resourceVerbs := range verbs {
+		ruleKey := groupVerbs{group: key.group, verbs: strings.Join(resourceVerbs.List(), ",")}
+		if rules[ruleKey] == nil {
+			rules[ruleKey] = &rbacv1.PolicyRule{APIGroups: []string{key.group}, Verbs: resourceVerbs.List()}
+			keys = append(keys, ruleKey)
+		}
+		rules[ruleKey].Resources = append(rules[ruleKey].Resources, key.resource)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].group != keys[j].group {
+			return keys[i].group < keys[j].group
+		}
+		return keys[i].verbs < keys[j].verbs
+	})
+
+	clusterRole := &rbacv1.ClusterRole{
+		TypeMeta:   apiv1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+		ObjectMeta: apiv1.ObjectMeta{Name: DefaultDeploymentName},
+	}
+	for _, key := range keys {
+		sort.Strings(rules[key].Resources)
+		clusterRole.Rules = append(clusterRole.Rules, *rules[key])
+	}
+	return clusterRole
+}
+
+// PrintPermissionReport prints permission matrix of resource verbs by namespace followed by minimal ClusterRole
+func PrintPermissionReport(w io.Writer, report *PermissionReport) error {
+	namespaceSet := sets.NewString()
+	type row struct{ group, resource, verb string }
+	var rows []row
+	cells := make(map[row]map[string]bool)
+	for idx := range report.Checks {
+		check := &report.Checks[idx]
+		namespace := check.Namespace
+		if namespace == "" {
+			namespace = allNamespaces
+		}
+		namespaceSet.Insert(namespace)
+
+		key := row{group: check.Group, resource: check.resourceName(), verb: check.Verb}
+		if cells[key] == nil {
+			cells[key] = make(map[string]bool)
+			rows = append(rows, key)
+		}
+		cells[key][namespace] = check.Allowed
+	}
+	namespaces := namespaceSet.List()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "GROUP\tRESOURCE\tVERB\t%s\n", strings.Join(namespaces, "\t"))
+	for _, key := range rows {
+		group := key.group
+		if group == "" {
+			group = "core"
+		}
+		marks := make([]string, 0, len(namespaces))
+		for _, namespace := range namespaces {
+			allowed, ok := cells[key][namespace]
+			switch {
+			case !ok:
+				marks = append(marks, notApplicableMark)
+			case allowed:
+				marks = append(marks, allowedMark)
+			default:
+				marks = append(marks, deniedMark)
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", group, key.resource, key.verb, strings.Join(marks, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	denied := report.Denied()
+	fmt.Fprintf(w, "\n%d of %d checks denied, namespace %s is cluster scope or all namespaces\n", len(denied),
+		len(report.Checks), allNamespaces)
+
+	buf, err := yaml.Marshal(report.ClusterRole)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\nMinimal ClusterRole for collection:\n---\n%s", buf)
+	return nil
+}
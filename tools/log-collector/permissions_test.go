@@ -0,0 +1,204 @@
+package logcollector
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+func TestPlannedChecks(t *testing.T) {
+	// discovery serves no openshift group
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	disClient := discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: server.URL})
+
+	resourceGroup := map[string][]apiv1.APIResource{
+		"v1": {
+			{Name: "namespaces", Kind: "Namespace"},
+			{Name: "pods", Kind: Pod, Namespaced: true},
+			{Name: "pods/log", Kind: Pod, Namespaced: true},
+			{Name: "secrets", Kind: "Secret", Namespaced: true},
+			{Name: "events", Kind: EventKind, Namespaced: true},
+		},
+		"apps/v1": {
+			{Name: "daemonsets", Kind: "DaemonSet", Namespaced: true},
+			{Name: "deployments", Kind: "Deployment", Namespaced: true},
+			{Name: "statefulsets", Kind: "StatefulSet", Namespaced: true},
+		},
+		TriliovaultGroupVersion: {
+			{Name: "backups", Kind: internal.BackupKind, Namespaced: true},
+			{Name: "targets", Kind: internal.TargetKind, Namespaced: true},
+		},
+		"storage.k8s.io/v1": {{Name: "storageclasses", Kind: StorageClassKind}},
+	}
+
+	tests := []struct {
+		name       string
+		collector  *LogCollector
+		resources  ResourcesConfig
+		planned    []PermissionCheck
+		notPlanned []PermissionCheck
+	}{
+		{
+			name:      "clustered collection",
+			collector: &LogCollector{Clustered: true},
+			planned: []PermissionCheck{
+				{Resource: "namespaces", Verb: Verblist},
+				{Resource: "pods", Verb: Verblist},
+				{Resource: "pods", Subresource: logSubresource, Verb: verbGet},
+				{Resource: "secrets", Verb: Verblist},
+				{Resource: "events", Verb: Verblist},
+				{Group: "apps", Resource: "daemonsets", Verb: Verblist},
+				{Group: "apps", Resource: "statefulsets", Verb: Verblist},
+				{Group: internal.TriliovaultGroup, Resource: "backups", Verb: Verblist},
+				{Group: internal.TriliovaultGroup, Resource: "targets", Verb: Verblist},
+				{Group: StorageGroup, Resource: "storageclasses", Verb: Verblist},
+			},
+		},
+		{
+			name:      "namespaced collection",
+			collector: &LogCollector{Namespaces: []string{"tvk"}},
+			planned: []PermissionCheck{
+				{Resource: "pods", Verb: Verblist, Namespace: "tvk"},
+				{Resource: "pods", Verb: Verblist},
+				{Resource: "pods", Subresource: logSubresource, Verb: verbGet, Namespace: "tvk"},
+				{Resource: "secrets", Verb: Verblist, Namespace: "tvk"},
+				{Resource: "events", Verb: Verblist, Namespace: apiv1.NamespaceDefault},
+				{Group: internal.TriliovaultGroup, Resource: "backups", Verb: Verblist, Namespace: "tvk"},
+			},
+			notPlanned: []PermissionCheck{{Resource: "secrets", Verb: Verblist}},
+		},
+		{
+			name:      "excluded pods and helm releases",
+			collector: &LogCollector{Namespaces: []string{"tvk"}},
+			resources: ResourcesConfig{ExcludeKinds: []string{Pod, HelmReleaseKind}},
+			notPlanned: []PermissionCheck{
+				{Resource: "pods", Verb: Verblist},
+				{Resource: "pods", Subresource: logSubresource, Verb: verbGet, Namespace: "tvk"},
+				{Resource: "secrets", Verb: Verblist, Namespace: "tvk"},
+				{Group: "apps", Resource: "daemonsets", Verb: Verblist},
+			},
+		},
+		{
+			name:       "skipped logs",
+			collector:  &LogCollector{Clustered: true},
+			resources:  ResourcesConfig{SkipLogs: true},
+			planned:    []PermissionCheck{{Resource: "pods", Verb: Verblist}},
+			notPlanned: []PermissionCheck{{Resource: "pods", Subresource: logSubresource, Verb: verbGet}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := tt.collector
+			l.disClient = disClient
+			l.Config.Resources = tt.resources
+			selection, err := newResourceSelection(&l.Config.Resources)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.selection = selection
+
+			planned := make(map[PermissionCheck]bool)
+			for _, check := range l.plannedChecks(resourceGroup) {
+				planned[check] = true
+			}
+			for _, check := range tt.planned {
+				if !planned[check] {
+					t.Errorf("plannedChecks() doesn't contain %+v", check)
+				}
+			}
+			for _, check := range tt.notPlanned {
+				if planned[check] {
+					t.Errorf("plannedChecks() contains %+v", check)
+				}
+			}
+		})
+	}
+}
+
+func TestMinimalClusterRole(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []PermissionCheck
+		rules  []rbacv1.PolicyRule
+	}{
+		{
+			name: "resources of a group needing same verbs are combined",
+			checks: []PermissionCheck{
+				{Resource: "pods", Verb: Verblist, Namespace: "app"},
+				{Resource: "pods", Verb: Verblist, Namespace: "tvk"},
+				{Resource: "secrets", Verb: Verblist, Namespace: "tvk"},
+				{Resource: "pods", Subresource: logSubresource, Verb: verbGet, Namespace: "app"},
+				{Resource: "namespaces", Verb: Verblist},
+				{Group: "apps", Resource: "daemonsets", Verb: Verblist},
+				{Group: "triliovault.trilio.io", Resource: "backups", Verb: Verblist, Namespace: "app"},
+				{Group: "triliovault.trilio.io", Resource: "backups", Verb: verbGet, Namespace: "app"},
+				{Group: "triliovault.trilio.io", Resource: "targets", Verb: Verblist, Namespace: "app"},
+			},
+			rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods/log"}, Verbs: []string{verbGet}},
+				{APIGroups: []string{""}, Resources: []string{"namespaces", "pods", "secrets"}, Verbs: []string{Verblist}},
+				{APIGroups: []string{"apps"}, Resources: []string{"daemonsets"}, Verbs: []string{Verblist}},
+				{APIGroups: []string{"triliovault.trilio.io"}, Resources: []string{"backups"},
+					Verbs: []string{verbGet, Verblist}},
+				{APIGroups: []string{"triliovault.trilio.io"}, Resources: []string{"targets"}, Verbs: []string{Verblist}},
+			},
+		},
+		{name: "no checks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterRole := minimalClusterRole(tt.checks)
+			if clusterRole.Name != DefaultDeploymentName || clusterRole.Kind != "ClusterRole" {
+				t.Errorf("minimalClusterRole() = %s %s, want ClusterRole %s", clusterRole.Kind, clusterRole.Name,
+					DefaultDeploymentName)
+			}
+			if !reflect.DeepEqual(clusterRole.Rules, tt.rules) {
+				t.Errorf("minimalClusterRole() rules = %v, want %v", clusterRole.Rules, tt.rules)
+			}
+		})
+	}
+}
+
+func TestPrintPermissionReport(t *testing.T) {
+	checks := []PermissionCheck{
+		{Resource: "namespaces", Verb: Verblist, Allowed: true},
+		{Resource: "pods", Verb: Verblist, Namespace: "app", Allowed: true},
+		{Resource: "pods", Verb: Verblist, Namespace: "tvk", Reason: "forbidden"},
+		{Resource: "pods", Subresource: logSubresource, Verb: verbGet, Namespace: "app", Allowed: true},
+	}
+	report := &PermissionReport{Checks: checks, ClusterRole: minimalClusterRole(checks)}
+
+	if denied := report.Denied(); !reflect.DeepEqual(denied, checks[2:3]) {
+		t.Errorf("Denied() = %v, want %v", denied, checks[2:3])
+	}
+
+	var out bytes.Buffer
+	if err := PrintPermissionReport(&out, report); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"GROUP  RESOURCE    VERB  *    app  tvk",
+		"core   namespaces  list  yes  -    -",
+		"core   pods        list  -    yes  NO",
+		"core   pods/log    get   -    yes  -",
+		"1 of 4 checks denied",
+		"kind: ClusterRole",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("PrintPermissionReport() output doesn't contain %q:\n%s", want, out.String())
+		}
+	}
+}
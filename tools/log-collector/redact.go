@@ -0,0 +1,245 @@
+package logcollector
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+const (
+	redactedValue = "REDACTED"
+
+	secretKind                  = "Secret"
+	lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+var (
+	// builtinRedactionRules are applied on every YAML and log written by log-collector
+	builtinRedactionRules = []RedactionRule{
+		{
+			Name:        "bearer-token",
+			Pattern:     `(?i)(bearer[ \t]+)[a-z0-9\-._~+/]+=*`,
+			Replacement: "${1}" + redactedValue,
+		},
+		{
+			Name:    "aws-access-key-id",
+			Pattern: `\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA)[0-9A-Z]{16}\b`,
+		},
+		{
+			Name:        "aws-secret-access-key",
+			Pattern:     `(?i)(aws_?secret_?access_?key|secret_?key)(["']?[ \t]*[:=][ \t]*["']?)[a-z0-9/+=]{40}`,
+			Replacement: "${1}${2}" + redactedValue,
+		},
+		{
+			// key is matched as a whole, optionally prefixed by segments separated by '_', '-' or '.' e.g. DB_PASSWORD
+			// or --api-key, so that keys like automountServiceAccountToken and tokenSecret are not redacted
+			Name: "credential-assignment",
+			Pattern: `(?i)((?:^|[\s"'{,])(?:[a-z0-9]*[_.-])*(?:password|passwd|pwd|token|secret|api[_-]?key|` +
+				`access[_-]?key)["']?[ \t]*[:=][ \t]*["']?)[^\s"',}]+`,
+			Replacement: "${1}" + redactedValue,
+		},
+		{
+			// camelCase key ending with a credential word e.g. dbPassword, clientSecret or secretKey, keys in
+			// excludedCredentialKeys are not redacted
+			Name: "camel-case-credential-assignment",
+			Pattern: `((?:^|[\s"'{,])(?P<key>(?:[a-z][a-z0-9]*(?:[A-Z][a-z0-9]*)*)?(?:Password|Passwd|Pwd|Secret|Token|` +
+				`(?:[Aa]pi|[Aa]ccess|[Ss]ecret|[Pp]rivate)Key))["']?[ \t]*[:=][ \t]*["']?)[^\s"',}]+`,
+			Replacement: "${1}" + redactedValue,
+		},
+	}
+
+	// excludedCredentialKeys are keys looking like credentials which hold references or flags instead of credentials
+	excludedCredentialKeys = sets.NewString("tokenSecret", "credentialSecret", "automountServiceAccountToken")
+
+	// sensitiveName matches names whose segments separated by '_' include a credential word, camelCase names are
+	// matched after separating their words by '_'
+	sensitiveName = regexp.MustCompile(`(?i)(^|_)(password|passwd|secret|token|credentials?|api_?key|access_?key|` +
+		`private_?key)($|_)`)
+	camelCaseBoundary = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
+	// targetCredentialFields are the credential fields of Target spec which are redacted
+	targetCredentialFields = [][]string{
+		{"spec", "objectStoreCredentials", "accessKey"},
+		{"spec", "objectStoreCredentials", "secretKey"},
+	}
+)
+
+type compiledRedactionRule struct {
+	name        string
+	pattern     *regexp.Regexp
+	replacement string
+	// keyGroup is index of 'key' group of pattern, matches whose key is in excludedCredentialKeys are kept as is
+	keyGroup int
+}
+
+// redactor scrubs credentials from collected objects and logs using built-in and user supplied rules
+type redactor struct {
+	rules []compiledRedactionRule
+}
+
+// newRedactor compiles built-in rules followed by user supplied 'rules'
+func newRedactor(rules []RedactionRule) (*redactor, error) {
+	r := &redactor{}
+	for _, rule := range append(append([]RedactionRule{}, builtinRedactionRules...), rules...) {
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction rule %s : %s", rule.Name, err.Error())
+		}
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = redactedValue
+		}
+		r.rules = append(r.rules, compiledRedactionRule{name: rule.Name, pattern: pattern, replacement: replacement,
+			keyGroup: pattern.SubexpIndex("key")})
+	}
+	return r, nil
+}
+
+// redactBytes applies all rules on 'data'
+func (r *redactor) redactBytes(data []byte) []byte {
+	for idx := range r.rules {
+		rule := &r.rules[idx]
+		if rule.keyGroup < 0 {
+			data = rule.pattern.ReplaceAll(data, []byte(rule.replacement))
+			continue
+		}
+		data = rule.pattern.ReplaceAllFunc(data, func(match []byte) []byte {
+			submatch := rule.pattern.FindSubmatchIndex(match)
+			if submatch == nil {
+				return match
+			}
+			if key := match[submatch[2*rule.keyGroup]:submatch[2*rule.keyGroup+1]]; excludedCredentialKeys.Has(string(key)) {
+				return match
+			}
+			return rule.pattern.Expand(nil, []byte(rule.replacement), match, submatch)
+		})
+	}
+	return data
+}
+
+// redactObject redacts credential fields of Target, values of Secret and literal values of sensitive env variables
+// of containers from copy of 'obj'
+func (r *redactor) redactObject(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	obj = obj.DeepCopy()
+	if obj.GetKind() == secretKind && obj.GroupVersionKind().Group == "" {
+		redactSecret(obj)
+	}
+	if obj.GetKind() == internal.TargetKind {
+		for _, fields := range targetCredentialFields {
+			if _, found, _ := unstructured.NestedFieldNoCopy(obj.Object, fields...); found {
+				_ = unstructured.SetNestedField(obj.Object, redactedValue, fields...)
+			}
+		}
+	}
+	redactEnvValues(obj.Object)
+	return obj
+}
+
+// redactSecret replaces values of 'data' and 'stringData' of Secret 'obj' keeping their keys, last applied
+// configuration annotation is redacted as it contains the values as well
+func redactSecret(obj *unstructured.Unstructured) {
+	for _, field := range []string{"data", "stringData"} {
+		values, ok := obj.Object[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key := range values {
+			values[key] = redactedValue
+		}
+	}
+
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[lastAppliedConfigAnnotation]; ok {
+		annotations[lastAppliedConfigAnnotation] = redactedValue
+		obj.SetAnnotations(annotations)
+	}
+}
+
+// redactStream copies 'src' to 'dst' line by line applying all rules and returns number of bytes read from 'src'
+func (r *redactor) redactStream(dst io.Writer, src io.Reader) (int64, error) {
+	reader := bufio.NewReader(src)
+	var read int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		read += int64(len(line))
+		if len(line) > 0 {
+			if _, wErr := dst.Write(r.redactBytes(line)); wErr != nil {
+				return read, wErr
+			}
+		}
+		if err == io.EOF {
+			return read, nil
+		}
+		if err != nil {
+			return read, err
+		}
+	}
+}
+
+// redactEnvValues walks 'data' and replaces literal values of env variables having sensitive names
+func redactEnvValues(data interface{}) {
+	switch val := data.(type) {
+	case map[string]interface{}:
+		for key, v := range val {
+			if envs, ok := v.([]interface{}); ok && key == "env" {
+				for _, env := range envs {
+					envVar, isMap := env.(map[string]interface{})
+					if !isMap {
+						continue
+					}
+					name, _ := envVar["name"].(string)
+					if _, hasValue := envVar["value"]; hasValue && isSensitiveName(name) {
+						envVar["value"] = redactedValue
+					}
+				}
+			}
+			redactEnvValues(v)
+		}
+	case []interface{}:
+		for _, v := range val {
+			redactEnvValues(v)
+		}
+	}
+}
+
+// redactSensitiveValues walks 'data' and replaces values of keys having sensitive names
+func redactSensitiveValues(data interface{}) {
+	switch val := data.(type) {
+	case map[string]interface{}:
+		for key, v := range val {
+			if v != nil && isSensitiveName(key) {
+				val[key] = redactedValue
+				continue
+			}
+			redactSensitiveValues(v)
+		}
+	case []interface{}:
+		for _, v := range val {
+			redactSensitiveValues(v)
+		}
+	}
+}
+
+// isSensitiveName returns true if value of env variable or key 'name' is a credential
+func isSensitiveName(name string) bool {
+	if excludedCredentialKeys.Has(name) {
+		return false
+	}
+	return sensitiveName.MatchString(camelCaseBoundary.ReplaceAllString(name, "${1}_${2}"))
+}
+
+// redactionRuleNames returns names of all rules applied
+func (r *redactor) redactionRuleNames() string {
+	var names []string
+	for _, rule := range r.rules {
+		names = append(names, rule.name)
+	}
+	return strings.Join(names, ", ")
+}
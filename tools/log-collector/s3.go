@@ -0,0 +1,295 @@
+package logcollector
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// S3Scheme prefixes output given as s3://bucket/key
+	S3Scheme        = "s3://"
+	DefaultS3Region = "us-east-1"
+
+	AccessKeyIDEnv     = "AWS_ACCESS_KEY_ID"
+	SecretAccessKeyEnv = "AWS_SECRET_ACCESS_KEY"
+	SessionTokenEnv    = "AWS_SESSION_TOKEN"
+
+	sigV4Algorithm = "AWS4-HMAC-SHA256"
+	amzDateFormat  = "20060102T150405Z"
+	amzDayFormat   = "20060102"
+	s3Service      = "s3"
+	maxErrorBody   = 1 << 10
+)
+
+// S3Config locates the object bundle is uploaded to in an S3 compatible object store and credentials to upload it
+type S3Config struct {
+	// Endpoint is URL of object store, e.g. http://minio.minio:9000. Endpoint of AWS S3 in Region is used if empty.
+	Endpoint string
+	Region   string
+	Bucket   string
+	Key      string
+	// PathStyle addresses bucket in path of URL instead of host name, as required by most S3 compatible stores
+	PathStyle bool
+
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// Client is the HTTP client used for upload, http.DefaultClient is used if nil
+	Client *http.Client
+}
+
+// ParseS3URL parses 'output' given as s3://bucket/key into bucket and key of object
+func ParseS3URL(output string) (bucket, key string, err error) {
+	if !strings.HasPrefix(output, S3Scheme) {
+		return "", "", fmt.Errorf("%q is not an s3 URL", output)
+	}
+	parts := strings.SplitN(strings.TrimPrefix(output, S3Scheme), "/", 2)
+	if parts[0] == "" || len(parts) != 2 || parts[1] == "" {
+		return "", "", fmt.Errorf("s3 URL %q should be of the form %sbucket/key", output, S3Scheme)
+	}
+	return parts[0], parts[1], nil
+}
+
+// objectURL returns URL of object, addressed in path or virtual host
+func (c *S3Config) objectURL() (*url.URL, error) {
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", c.Region)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("s3 endpoint %q should be a URL with scheme and host", endpoint)
+	}
+
+	objectPath := "/" + strings.TrimPrefix(c.Key, "/")
+	if c.PathStyle {
+		objectPath = "/" + c.Bucket + objectPath
+	} else {
+		u.Host = c.Bucket + "." + u.Host
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + objectPath
+	return u, nil
+}
+
+// s3Sink writes bundle archive to a temp file, created on first file of bundle, and uploads it as an object when
+// closed
+type s3Sink struct {
+	config  S3Config
+	format  string
+	once    sync.Once
+	archive *archiveSink
+	file    *os.File
+	err     error
+}
+
+// NewS3Sink returns a sink writing archive of 'format', which is uploaded to S3 compatible object store of 'config'
+// when sink is closed
+func NewS3Sink(config S3Config, format string) (Sink, error) {
+	if err := validateFormat(format); err != nil {
+		return nil, err
+	}
+	if config.Bucket == "" || config.Key == "" {
+		return nil, fmt.Errorf("s3 bucket and key are required")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("s3 credentials are required, set %s and %s", AccessKeyIDEnv, SecretAccessKeyEnv)
+	}
+	if config.Region == "" {
+		config.Region = DefaultS3Region
+	}
+	if _, err := config.objectURL(); err != nil {
+		return nil, err
+	}
+	return &s3Sink{config: config, format: format}, nil
+}
+
+func (s *s3Sink) open() {
+	file, err := ioutil.TempFile("", "log-collector-bundle-")
+	if err != nil {
+		log.Errorf("Error Creating archive File : %s", err.Error())
+		s.err = err
+		return
+	}
+	s.file = file
+	s.archive, s.err = newArchiveSink(file, s.format)
+	log.Infof("Creating archive to upload to s3 bucket %s at %s", s.config.Bucket, s.config.Key)
+}
+
+func (s *s3Sink) Create(path string) (io.WriteCloser, error) {
+	s.once.Do(s.open)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.archive.Create(path)
+}
+
+func (s *s3Sink) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	defer func() {
+		_ = os.Remove(s.file.Name())
+	}()
+	if s.archive == nil {
+		_ = s.file.Close()
+		return s.err
+	}
+	if err := s.archive.Close(); err != nil {
+		return err
+	}
+
+	file, err := os.Open(s.file.Name())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	if err = s.config.upload(file); err != nil {
+		log.Errorf("Unable to upload bundle to s3 : %s", err.Error())
+		return err
+	}
+	log.Infof("Uploaded bundle to s3 bucket %s at %s", s.config.Bucket, s.config.Key)
+	return nil
+}
+
+// upload puts content of 'file' as object, request is signed with AWS signature version 4
+func (c *S3Config) upload(file *os.File) error {
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	objectURL, err := c.objectURL()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, objectURL.String(), file)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	c.sign(req, hex.EncodeToString(hash.Sum(nil)), time.Now())
+
+	httpClient := c.Client
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("upload of %s failed with status %s : %s", objectURL.String(), resp.Status,
+			strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// sign adds AWS signature version 4 of 'req' with hex encoded SHA256 'payloadHash' to its Authorization header.
+// Host and all headers of 'req' are signed.
+func (c *S3Config) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format(amzDateFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if c.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{now.UTC().Format(amzDayFormat), c.Region, s3Service, "aws4_request"}, "/")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.SecretAccessKey), now.UTC().Format(amzDayFormat))
+	key = hmacSHA256(key, c.Region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, c.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery returns query parameters encoded and sorted by name as required by signature
+func canonicalQuery(query url.Values) string {
+	params := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			params = append(params, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// uriEncode percent encodes every byte of 's' other than unreserved characters and, unless 'encodeSlash', '/'
+func uriEncode(s string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for _, b := range []byte(s) {
+		switch {
+		case (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9'),
+			b == '-', b == '_', b == '.', b == '~', b == '/' && !encodeSlash:
+			encoded.WriteByte(b)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return encoded.String()
+}
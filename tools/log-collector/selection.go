@@ -0,0 +1,77 @@
+package logcollector
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// resourceSelection is compiled form of ResourcesConfig used while filtering resources
+type resourceSelection struct {
+	include      []GroupVersionKind
+	excludeKinds sets.String
+	selectors    []labels.Selector
+	skipLogs     bool
+	// application is the scope of application of Backup or Restore given by user
+	application *applicationScope
+}
+
+// newResourceSelection validates and compiles 'config'
+func newResourceSelection(config *ResourcesConfig) (*resourceSelection, error) {
+	selection := &resourceSelection{
+		include:      config.Include,
+		excludeKinds: sets.NewString(config.ExcludeKinds...),
+		skipLogs:     config.SkipLogs,
+	}
+
+	for idx := range config.Include {
+		if config.Include[idx].Kind == "" {
+			return nil, fmt.Errorf("kind is required for included resource %+v", config.Include[idx])
+		}
+	}
+
+	for _, selector := range config.LabelSelectors {
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector %q : %s", selector, err.Error())
+		}
+		selection.selectors = append(selection.selectors, parsed)
+	}
+	return selection, nil
+}
+
+// isExcluded returns true if 'kind' should not be collected
+func (s *resourceSelection) isExcluded(kind string) bool {
+	return s.excludeKinds.Has(kind)
+}
+
+// isIncluded returns true if 'kind' of 'groupVersion' should be collected completely
+func (s *resourceSelection) isIncluded(groupVersion, kind string) bool {
+	gv, err := schema.ParseGroupVersion(groupVersion)
+	if err != nil {
+		return false
+	}
+	for _, gvk := range s.include {
+		if strings.EqualFold(gvk.Kind, kind) && gvk.Group == gv.Group && (gvk.Version == "" || gvk.Version == gv.Version) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesSelectors returns true if labels of 'obj' match any of the label selectors or 'obj' belongs to application
+func (s *resourceSelection) matchesSelectors(obj *unstructured.Unstructured) bool {
+	if s.application != nil && s.application.matches(obj) {
+		return true
+	}
+	for _, selector := range s.selectors {
+		if selector.Matches(labels.Set(obj.GetLabels())) {
+			return true
+		}
+	}
+	return false
+}
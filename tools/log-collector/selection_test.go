@@ -0,0 +1,140 @@
+package logcollector
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNewResourceSelection(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  ResourcesConfig
+		wantErr bool
+	}{
+		{name: "empty config"},
+		{name: "valid config", config: ResourcesConfig{Include: []GroupVersionKind{{Group: "apps", Kind: "Deployment"}},
+			ExcludeKinds: []string{"Event"}, LabelSelectors: []string{"app=mysql", "tier in (db,cache)"}}},
+		{name: "included resource without kind", config: ResourcesConfig{Include: []GroupVersionKind{{Group: "apps"}}},
+			wantErr: true},
+		{name: "invalid label selector", config: ResourcesConfig{LabelSelectors: []string{"app in (mysql"}},
+			wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selection, err := newResourceSelection(&tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newResourceSelection() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err == nil && len(selection.selectors) != len(tt.config.LabelSelectors) {
+				t.Errorf("newResourceSelection() compiled %d selectors, want %d", len(selection.selectors),
+					len(tt.config.LabelSelectors))
+			}
+		})
+	}
+}
+
+func TestIsIncluded(t *testing.T) {
+	selection, err := newResourceSelection(&ResourcesConfig{Include: []GroupVersionKind{
+		{Kind: "ConfigMap"},
+		{Group: "apps", Kind: "Deployment"},
+		{Group: "batch", Version: "v1", Kind: "CronJob"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		groupVersion string
+		kind         string
+		want         bool
+	}{
+		{name: "core kind", groupVersion: "v1", kind: "ConfigMap", want: true},
+		{name: "kind is case insensitive", groupVersion: "v1", kind: "configmap", want: true},
+		{name: "any version of group", groupVersion: "apps/v1beta2", kind: "Deployment", want: true},
+		{name: "given version", groupVersion: "batch/v1", kind: "CronJob", want: true},
+		{name: "other version", groupVersion: "batch/v1beta1", kind: "CronJob"},
+		{name: "same kind of other group", groupVersion: "extensions/v1beta1", kind: "Deployment"},
+		{name: "core kind not included", groupVersion: "v1", kind: "Secret"},
+		{name: "invalid group version", groupVersion: "a/b/c", kind: "ConfigMap"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selection.isIncluded(tt.groupVersion, tt.kind); got != tt.want {
+				t.Errorf("isIncluded(%s, %s) = %t, want %t", tt.groupVersion, tt.kind, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsExcluded(t *testing.T) {
+	selection, err := newResourceSelection(&ResourcesConfig{ExcludeKinds: []string{"Event", "ConfigMap"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for kind, want := range map[string]bool{"Event": true, "ConfigMap": true, "Pod": false} {
+		if got := selection.isExcluded(kind); got != want {
+			t.Errorf("isExcluded(%s) = %t, want %t", kind, got, want)
+		}
+	}
+}
+
+func TestMatchesSelectors(t *testing.T) {
+	selection, err := newResourceSelection(&ResourcesConfig{LabelSelectors: []string{"app=mysql", "tier in (db,cache)"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   bool
+	}{
+		{name: "matches first selector", labels: map[string]string{"app": "mysql"}, want: true},
+		{name: "matches second selector", labels: map[string]string{"tier": "cache"}, want: true},
+		{name: "matches no selector", labels: map[string]string{"app": "nginx", "tier": "web"}},
+		{name: "no labels"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			obj.SetLabels(tt.labels)
+			if got := selection.matchesSelectors(obj); got != tt.want {
+				t.Errorf("matchesSelectors(%v) = %t, want %t", tt.labels, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGroupVersionKind(t *testing.T) {
+	tests := []struct {
+		gvk     string
+		want    GroupVersionKind
+		wantErr bool
+	}{
+		{gvk: "ConfigMap", want: GroupVersionKind{Kind: "ConfigMap"}},
+		{gvk: "/v1/ConfigMap", want: GroupVersionKind{Version: "v1", Kind: "ConfigMap"}},
+		{gvk: "apps/Deployment", want: GroupVersionKind{Group: "apps", Kind: "Deployment"}},
+		{gvk: "apps/v1/Deployment", want: GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}},
+		{gvk: "apps/v1/", wantErr: true},
+		{gvk: "a/b/c/d", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.gvk, func(t *testing.T) {
+			got, err := ParseGroupVersionKind(tt.gvk)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGroupVersionKind() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseGroupVersionKind() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
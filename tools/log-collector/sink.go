@@ -0,0 +1,168 @@
+package logcollector
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Sink stores files of bundle at slash separated paths relative to bundle root. Files can be created and written
+// concurrently, a file is stored once its writer is closed. Close is called once after all files are written and
+// completes the bundle.
+type Sink interface {
+	Create(path string) (io.WriteCloser, error)
+	Close() error
+}
+
+// writeFile writes 'content' to file at 'filePath' relative to bundle root
+func (l *LogCollector) writeFile(filePath string, content []byte) error {
+	fp, err := l.bundle.Create(filePath)
+	if err != nil {
+		l.logger().Errorf("Unable to create the file : %s", err.Error())
+		return err
+	}
+	if _, err = fp.Write(content); err != nil {
+		_ = fp.Close()
+		l.logger().Errorf("Unable to write the content : %s", err.Error())
+		return err
+	}
+	if err = fp.Close(); err != nil {
+		l.logger().Errorf("Unable to write the content : %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// dirSink writes bundle files to a directory
+type dirSink struct {
+	root string
+}
+
+// NewDirSink returns a sink writing bundle files under 'root' directory
+func NewDirSink(root string) Sink {
+	return &dirSink{root: root}
+}
+
+func (d *dirSink) Create(path string) (io.WriteCloser, error) {
+	filePath := filepath.Join(d.root, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return nil, err
+	}
+	return os.Create(filePath)
+}
+
+func (d *dirSink) Close() error {
+	return nil
+}
+
+// MemorySink writes bundle as an archive held in memory, which can be read once sink is closed
+type MemorySink struct {
+	*archiveSink
+	buf *bytes.Buffer
+}
+
+// NewMemorySink returns a sink writing archive of 'format' to memory
+func NewMemorySink(format string) (*MemorySink, error) {
+	buf := &bytes.Buffer{}
+	archive, err := newArchiveSink(nopWriteCloser{Writer: buf}, format)
+	if err != nil {
+		return nil, err
+	}
+	return &MemorySink{archiveSink: archive, buf: buf}, nil
+}
+
+// Bytes returns content of archive, it is complete only after sink is closed
+func (m *MemorySink) Bytes() []byte {
+	return m.buf.Bytes()
+}
+
+// multiSink writes every bundle file to all of its sinks
+type multiSink []Sink
+
+// NewMultiSink returns a sink writing every bundle file to all of 'sinks'
+func NewMultiSink(sinks ...Sink) Sink {
+	return multiSink(sinks)
+}
+
+func (m multiSink) Create(path string) (io.WriteCloser, error) {
+	writers := make(multiWriteCloser, 0, len(m))
+	for _, sink := range m {
+		w, err := sink.Create(path)
+		if err != nil {
+			_ = writers.Close()
+			return nil, err
+		}
+		writers = append(writers, w)
+	}
+	return writers, nil
+}
+
+func (m multiSink) Close() error {
+	var errs []string
+	for _, sink := range m {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) != 0 {
+		return errors.Errorf("unable to close bundle : %v", errs)
+	}
+	return nil
+}
+
+// prefixSink writes bundle files under a directory of another sink
+type prefixSink struct {
+	sink   Sink
+	prefix string
+}
+
+// newPrefixSink returns a sink writing bundle files under slash separated 'dir' of 'sink', closing it doesn't
+// close 'sink'
+func newPrefixSink(sink Sink, dir string) Sink {
+	return &prefixSink{sink: sink, prefix: strings.TrimSuffix(dir, "/") + "/"}
+}
+
+func (p *prefixSink) Create(path string) (io.WriteCloser, error) {
+	return p.sink.Create(p.prefix + strings.TrimPrefix(filepath.ToSlash(path), "/"))
+}
+
+func (p *prefixSink) Close() error {
+	return nil
+}
+
+type multiWriteCloser []io.WriteCloser
+
+func (m multiWriteCloser) Write(p []byte) (int, error) {
+	for _, w := range m {
+		n, err := w.Write(p)
+		if err != nil {
+			return n, err
+		}
+		if n != len(p) {
+			return n, io.ErrShortWrite
+		}
+	}
+	return len(p), nil
+}
+
+func (m multiWriteCloser) Close() error {
+	var err error
+	for _, w := range m {
+		if cErr := w.Close(); cErr != nil && err == nil {
+			err = cErr
+		}
+	}
+	return err
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
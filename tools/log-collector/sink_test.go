@@ -0,0 +1,96 @@
+package logcollector
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDirSinkRoundTrip(t *testing.T) {
+	files := map[string][]byte{
+		SummaryJSONFile:                  []byte(`{}`),
+		"Pod/tvk/web.server.current.log": []byte("line 1\n"),
+	}
+	root := t.TempDir()
+	writeBundleFiles(t, NewDirSink(root), files)
+
+	if got := readBundleFiles(t, root); !reflect.DeepEqual(got, files) {
+		t.Errorf("directory contains files %v, want %v", bundleFileNames(got), bundleFileNames(files))
+	}
+}
+
+func TestMemorySinkRoundTrip(t *testing.T) {
+	files := map[string][]byte{
+		SummaryJSONFile:                  []byte(`{}`),
+		"Pod/tvk/web.server.current.log": []byte("line 1\n"),
+	}
+
+	for _, format := range []string{FormatZip, FormatTarGz} {
+		t.Run(format, func(t *testing.T) {
+			sink, err := NewMemorySink(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeBundleFiles(t, sink, files)
+
+			output := filepath.Join(t.TempDir(), "bundle."+format)
+			if err = ioutil.WriteFile(output, sink.Bytes(), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if got := readBundleFiles(t, output); !reflect.DeepEqual(got, files) {
+				t.Errorf("archive contains files %v, want %v", bundleFileNames(got), bundleFileNames(files))
+			}
+		})
+	}
+
+	if _, err := NewMemorySink("tar"); err == nil {
+		t.Errorf("NewMemorySink() of invalid format returned no error")
+	}
+}
+
+func TestMultiSink(t *testing.T) {
+	files := map[string][]byte{
+		SummaryJSONFile:                  []byte(`{}`),
+		"Pod/tvk/web.server.current.log": []byte("line 1\n"),
+	}
+	zipPath := filepath.Join(t.TempDir(), "bundle.zip")
+	archive, err := newArchiveFileSink(zipPath, FormatZip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	writeBundleFiles(t, NewMultiSink(archive, NewDirSink(dir)), files)
+
+	for _, bundlePath := range []string{zipPath, dir} {
+		if got := readBundleFiles(t, bundlePath); !reflect.DeepEqual(got, files) {
+			t.Errorf("%s contains files %v, want %v", bundlePath, bundleFileNames(got), bundleFileNames(files))
+		}
+	}
+}
+
+func TestMultiAndPrefixSink(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "bundle.zip")
+	archive, err := newArchiveFileSink(zipPath, FormatZip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	sink := NewMultiSink(archive, NewDirSink(dir))
+
+	// closing prefix sink leaves sink it writes to open
+	writeBundleFiles(t, newPrefixSink(sink, "clusters/prod/"), map[string][]byte{SummaryMarkdownFile: []byte("# prod"),
+		"/Pod/tvk/web.yaml": []byte("kind: Pod")})
+	writeBundleFiles(t, sink, map[string][]byte{ClustersSummaryMarkdownFile: []byte("# clusters")})
+
+	want := map[string][]byte{
+		"clusters/prod/" + SummaryMarkdownFile: []byte("# prod"),
+		"clusters/prod/Pod/tvk/web.yaml":       []byte("kind: Pod"),
+		ClustersSummaryMarkdownFile:            []byte("# clusters"),
+	}
+	for _, bundlePath := range []string{zipPath, dir} {
+		if got := readBundleFiles(t, bundlePath); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s contains files %v, want %v", bundlePath, bundleFileNames(got), bundleFileNames(want))
+		}
+	}
+}
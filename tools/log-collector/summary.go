@@ -0,0 +1,330 @@
+package logcollector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apiv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+const (
+	SummaryJSONFile     = "summary.json"
+	SummaryMarkdownFile = "SUMMARY.md"
+
+	eventsDir = "Events"
+)
+
+// bundleSummary is the index of a bundle written at its root
+type bundleSummary struct {
+	ClusterVersion string        `json:"clusterVersion"`
+	Openshift      bool          `json:"openshift"`
+	TVKVersion     string        `json:"tvkVersion"`
+	StartTime      time.Time     `json:"startTime"`
+	Duration       string        `json:"duration"`
+	Kinds          []kindSummary `json:"kinds"`
+	Pods           []podSummary  `json:"pods"`
+	Warnings       []string      `json:"warnings"`
+	Errors         []string      `json:"errors"`
+}
+
+type kindSummary struct {
+	Kind  string `json:"kind"`
+	Count int    `json:"count"`
+}
+
+type podSummary struct {
+	Namespace          string             `json:"namespace"`
+	Name               string             `json:"name"`
+	Node               string             `json:"node,omitempty"`
+	Phase              string             `json:"phase"`
+	Restarts           int32              `json:"restarts"`
+	NotReadyContainers []string           `json:"notReadyContainers,omitempty"`
+	Containers         []containerSummary `json:"containers"`
+}
+
+// summaryRecorder records objects, pods, warnings and errors during collection, it is safe to be used concurrently
+type summaryRecorder struct {
+	mutex     sync.Mutex
+	startTime time.Time
+	kinds     map[string]int
+	pods      []podSummary
+	// usage is container usage of pods reported by metrics API, keyed by namespaced name of pod
+	usage    map[string]map[string]containerUsage
+	warnings []string
+	errors   []string
+}
+
+// start resets recorder and returns logger of collection which writes like standard logger and records warnings and
+// errors in recorder, so that collectors running in same process record only their own warnings and errors
+func (s *summaryRecorder) start() *log.Logger {
+	s.mutex.Lock()
+	s.startTime = time.Now()
+	s.kinds = make(map[string]int)
+	s.pods, s.usage, s.warnings, s.errors = nil, nil, nil, nil
+	s.mutex.Unlock()
+
+	standard := log.StandardLogger()
+	hooks := make(log.LevelHooks)
+	for level, levelHooks := range standard.Hooks {
+		hooks[level] = append([]log.Hook{}, levelHooks...)
+	}
+	hooks.Add(s)
+
+	logger := log.New()
+	logger.SetOutput(standard.Out)
+	logger.SetFormatter(standard.Formatter)
+	logger.SetLevel(standard.GetLevel())
+	logger.SetReportCaller(standard.ReportCaller)
+	logger.ExitFunc = standard.ExitFunc
+	logger.ReplaceHooks(hooks)
+	return logger
+}
+
+// Levels implements logrus.Hook
+func (s *summaryRecorder) Levels() []log.Level {
+	return []log.Level{log.PanicLevel, log.FatalLevel, log.ErrorLevel, log.WarnLevel}
+}
+
+// Fire implements logrus.Hook
+func (s *summaryRecorder) Fire(entry *log.Entry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if entry.Level == log.WarnLevel {
+		s.warnings = append(s.warnings, entry.Message)
+	} else {
+		s.errors = append(s.errors, entry.Message)
+	}
+	return nil
+}
+
+// addClusterMessages records warnings and errors of recorder of 'cluster' prefixed by its 'kubeContext'
+func (s *summaryRecorder) addClusterMessages(kubeContext string, cluster *summaryRecorder) {
+	cluster.mutex.Lock()
+	defer cluster.mutex.Unlock()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, warning := range cluster.warnings {
+		s.warnings = append(s.warnings, kubeContext+" : "+warning)
+	}
+	for _, err := range cluster.errors {
+		s.errors = append(s.errors, kubeContext+" : "+err)
+	}
+}
+
+// addObjects records 'count' collected objects of 'kind'
+func (s *summaryRecorder) addObjects(kind string, count int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.kinds == nil {
+		s.kinds = make(map[string]int)
+	}
+	s.kinds[kind] += count
+}
+
+// collected returns number of objects collected
+func (s *summaryRecorder) collected() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	count := 0
+	for _, kindCount := range s.kinds {
+		count += kindCount
+	}
+	return count
+}
+
+// addPod records restart count and non-ready containers of pod 'obj'
+func (s *summaryRecorder) addPod(obj *unstructured.Unstructured) {
+	var pod corev1.Pod
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pod); err != nil {
+		log.Debugf("Unable to convert pod %s/%s for summary : %s", obj.GetNamespace(), obj.GetName(), err.Error())
+		return
+	}
+
+	summary := podSummary{Namespace: pod.Namespace, Name: pod.Name, Node: pod.Spec.NodeName,
+		Phase: string(pod.Status.Phase), Containers: getContainerSummaries(&pod)}
+	for idx := range pod.Status.ContainerStatuses {
+		status := &pod.Status.ContainerStatuses[idx]
+		summary.Restarts += status.RestartCount
+		if !status.Ready {
+			summary.NotReadyContainers = append(summary.NotReadyContainers, status.Name)
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.pods = append(s.pods, summary)
+}
+
+// writeSummary writes summary.json and SUMMARY.md at bundle root
+func (l *LogCollector) writeSummary() error {
+	summary := l.buildSummary()
+	l.bundleSummary = summary
+
+	buf, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		l.logger().Errorf("Unable to marshal the content : %s", err.Error())
+		return err
+	}
+	if err = l.writeFile(SummaryJSONFile, buf); err != nil {
+		return err
+	}
+
+	return l.writeFile(SummaryMarkdownFile, []byte(summary.markdown()))
+}
+
+// buildSummary builds summary from recorded collection and cluster information
+func (l *LogCollector) buildSummary() *bundleSummary {
+	summary := &bundleSummary{
+		Openshift:  l.isOpenshift,
+		TVKVersion: l.getTVKVersion(),
+		Kinds:      []kindSummary{},
+		Pods:       []podSummary{},
+	}
+	if version, err := l.disClient.ServerVersion(); err != nil {
+		l.logger().Warnf("Unable to get cluster version : %s", err.Error())
+	} else {
+		summary.ClusterVersion = version.GitVersion
+	}
+
+	l.summary.mutex.Lock()
+	defer l.summary.mutex.Unlock()
+
+	summary.StartTime = l.summary.startTime
+	summary.Duration = time.Since(l.summary.startTime).Round(time.Millisecond).String()
+	for kind, count := range l.summary.kinds {
+		summary.Kinds = append(summary.Kinds, kindSummary{Kind: kind, Count: count})
+	}
+	sort.Slice(summary.Kinds, func(i, j int) bool {
+		return summary.Kinds[i].Kind < summary.Kinds[j].Kind
+	})
+	for idx := range l.summary.pods {
+		pod := l.summary.pods[idx]
+		usage := l.summary.usage[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}.String()]
+		pod.Containers = append([]containerSummary{}, pod.Containers...)
+		for cIdx := range pod.Containers {
+			pod.Containers[cIdx].CPUUsage = usage[pod.Containers[cIdx].Name].cpu
+			pod.Containers[cIdx].MemoryUsage = usage[pod.Containers[cIdx].Name].memory
+		}
+		summary.Pods = append(summary.Pods, pod)
+	}
+	sort.Slice(summary.Pods, func(i, j int) bool {
+		if summary.Pods[i].Namespace != summary.Pods[j].Namespace {
+			return summary.Pods[i].Namespace < summary.Pods[j].Namespace
+		}
+		return summary.Pods[i].Name < summary.Pods[j].Name
+	})
+	summary.Warnings = append([]string{}, l.summary.warnings...)
+	summary.Errors = append([]string{}, l.summary.errors...)
+
+	return summary
+}
+
+// getTVKVersion returns image tag of TVK control plane deployment, empty if TVK is not found
+func (l *LogCollector) getTVKVersion() string {
+	deployments, err := l.k8sClientSet.AppsV1().Deployments("").List(context.Background(), apiv1.ListOptions{
+		FieldSelector: "metadata.name=" + internal.TVKControlPlaneDeployment,
+	})
+	if err != nil {
+		l.logger().Warnf("Unable to get %s deployment : %s", internal.TVKControlPlaneDeployment, err.Error())
+		return ""
+	}
+	for idx := range deployments.Items {
+		containers := deployments.Items[idx].Spec.Template.Spec.Containers
+		for cIdx := range containers {
+			if tag := imageTag(containers[cIdx].Image); tag != "" {
+				return tag
+			}
+		}
+	}
+	l.logger().Debugf("%s deployment not found", internal.TVKControlPlaneDeployment)
+	return ""
+}
+
+// imageTag returns tag of container 'image', empty if image has no tag
+func imageTag(image string) string {
+	image = strings.SplitN(image, "@", 2)[0]
+	idx := strings.LastIndex(image, ":")
+	if idx == -1 || strings.Contains(image[idx:], "/") {
+		return ""
+	}
+	return image[idx+1:]
+}
+
+// markdown renders summary as human readable markdown
+func (s *bundleSummary) markdown() string {
+	var b strings.Builder
+
+	b.WriteString("# Log Collector Summary\n\n")
+	fmt.Fprintf(&b, "| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Cluster Version | %s |\n", valueOrUnknown(s.ClusterVersion))
+	fmt.Fprintf(&b, "| OpenShift | %t |\n", s.Openshift)
+	fmt.Fprintf(&b, "| TVK Version | %s |\n", valueOrUnknown(s.TVKVersion))
+	fmt.Fprintf(&b, "| Started At | %s |\n", s.StartTime.Format(time.RFC3339))
+	fmt.Fprintf(&b, "| Duration | %s |\n", s.Duration)
+
+	b.WriteString("\n## Collected Kinds\n\n| Kind | Count |\n|---|---|\n")
+	for _, kind := range s.Kinds {
+		fmt.Fprintf(&b, "| %s | %d |\n", kind.Kind, kind.Count)
+	}
+
+	b.WriteString("\n## Pods\n\n| Namespace | Name | Phase | Restarts | Not Ready Containers |\n|---|---|---|---|---|\n")
+	for idx := range s.Pods {
+		pod := &s.Pods[idx]
+		fmt.Fprintf(&b, "| %s | %s | %s | %d | %s |\n", pod.Namespace, pod.Name, pod.Phase, pod.Restarts,
+			strings.Join(pod.NotReadyContainers, ", "))
+	}
+
+	b.WriteString("\n## Resource Usage\n\nUsage / Request / Limit of containers, usage is reported only when metrics API " +
+		"is available.\n\n| Namespace | Pod | Container | CPU | Memory | OOMKilled |\n|---|---|---|---|---|---|\n")
+	for idx := range s.Pods {
+		pod := &s.Pods[idx]
+		for cIdx := range pod.Containers {
+			container := &pod.Containers[cIdx]
+			fmt.Fprintf(&b, "| %s | %s | %s | %s / %s / %s | %s / %s / %s | %t |\n", pod.Namespace, pod.Name,
+				container.Name, dashIfEmpty(container.CPUUsage), dashIfEmpty(container.CPURequest),
+				dashIfEmpty(container.CPULimit), dashIfEmpty(container.MemoryUsage), dashIfEmpty(container.MemoryRequest),
+				dashIfEmpty(container.MemoryLimit), container.OOMKilled)
+		}
+	}
+
+	writeMarkdownList(&b, "Warnings", s.Warnings)
+	writeMarkdownList(&b, "Errors", s.Errors)
+	return b.String()
+}
+
+func writeMarkdownList(b *strings.Builder, title string, items []string) {
+	fmt.Fprintf(b, "\n## %s\n\n", title)
+	if len(items) == 0 {
+		b.WriteString("None\n")
+		return
+	}
+	for _, item := range items {
+		fmt.Fprintf(b, "- %s\n", strings.ReplaceAll(item, "\n", " "))
+	}
+}
+
+func dashIfEmpty(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "Unknown"
+	}
+	return value
+}
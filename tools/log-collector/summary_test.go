@@ -0,0 +1,88 @@
+package logcollector
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestImageTag(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "trilio/k8s-triliovault-control-plane:2.5.0", want: "2.5.0"},
+		{image: "registry.example.com:5000/trilio/control-plane:2.5.0-rc1", want: "2.5.0-rc1"},
+		{image: "registry.example.com:5000/trilio/control-plane"},
+		{image: "trilio/control-plane:2.5.0@sha256:0123456789abcdef", want: "2.5.0"},
+		{image: "trilio/control-plane@sha256:0123456789abcdef"},
+		{image: "control-plane"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if got := imageTag(tt.image); got != tt.want {
+				t.Errorf("imageTag(%s) = %q, want %q", tt.image, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBundleSummaryMarkdown(t *testing.T) {
+	summary := &bundleSummary{
+		ClusterVersion: "v1.21.1",
+		Kinds:          []kindSummary{{Kind: "Pod", Count: 2}},
+		Pods: []podSummary{{Namespace: "tvk", Name: "web", Phase: "Running", Restarts: 3,
+			NotReadyContainers: []string{"server", "sidecar"}}},
+		Errors: []string{"Unable to list\nnodes"},
+	}
+
+	markdown := summary.markdown()
+	for _, want := range []string{
+		"| Cluster Version | v1.21.1 |",
+		"| TVK Version | Unknown |",
+		"| Pod | 2 |",
+		"| tvk | web | Running | 3 | server, sidecar |",
+		"## Warnings\n\nNone\n",
+		"- Unable to list nodes\n",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("markdown() doesn't contain %q:\n%s", want, markdown)
+		}
+	}
+}
+
+func TestSummaryRecorderStart(t *testing.T) {
+	standardHooks := len(log.StandardLogger().Hooks)
+	var first, second summaryRecorder
+	firstLogger, secondLogger := first.start(), second.start()
+	firstLogger.SetOutput(ioutil.Discard)
+	secondLogger.SetOutput(ioutil.Discard)
+
+	firstLogger.Warn("first warning")
+	secondLogger.Error("second error")
+	firstLogger.Info("first info")
+
+	if !reflect.DeepEqual(first.warnings, []string{"first warning"}) || len(first.errors) != 0 {
+		t.Errorf("first recorder recorded warnings %v, errors %v, want [first warning], []", first.warnings, first.errors)
+	}
+	if !reflect.DeepEqual(second.errors, []string{"second error"}) || len(second.warnings) != 0 {
+		t.Errorf("second recorder recorded warnings %v, errors %v, want [], [second error]", second.warnings,
+			second.errors)
+	}
+	if got := len(log.StandardLogger().Hooks); got != standardHooks {
+		t.Errorf("standard logger has hooks of %d levels, want %d", got, standardHooks)
+	}
+
+	var clusters summaryRecorder
+	clusters.start()
+	clusters.addClusterMessages("prod", &first)
+	clusters.addClusterMessages("stage", &second)
+	if !reflect.DeepEqual(clusters.warnings, []string{"prod : first warning"}) ||
+		!reflect.DeepEqual(clusters.errors, []string{"stage : second error"}) {
+		t.Errorf("clusters recorder recorded warnings %v, errors %v", clusters.warnings, clusters.errors)
+	}
+}
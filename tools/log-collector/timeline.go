@@ -0,0 +1,403 @@
+package logcollector
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+// TimelineSource is the kind of bundle data a timeline entry is reconstructed from
+type TimelineSource string
+
+const (
+	TimelineEvent     TimelineSource = "event"
+	TimelineCondition TimelineSource = "condition"
+	TimelineContainer TimelineSource = "container"
+	TimelineLog       TimelineSource = "log"
+
+	FormatHTML = "html"
+
+	// logTimeSearchLength is the length of log line prefix searched for timestamp
+	logTimeSearchLength = 100
+)
+
+var (
+	// TimelineSources are all sources of timeline entries
+	TimelineSources = []TimelineSource{TimelineEvent, TimelineCondition, TimelineContainer, TimelineLog}
+
+	// timelineConditionKinds are kinds whose status conditions are added to timeline
+	timelineConditionKinds = sets.NewString(internal.BackupKind, ClusterBackupKind, RestoreKind, ClusterRestoreKind,
+		internal.TargetKind)
+
+	// logTimeRegex matches RFC3339 like timestamps of log lines, with optional fraction and zone
+	logTimeRegex = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+	// logEpochRegex matches epoch timestamps of JSON log lines, e.g. '"ts":1623145600.123' of zap
+	logEpochRegex = regexp.MustCompile(`"(ts|time|timestamp)":\s*(\d{10}(\.\d+)?)`)
+)
+
+// TimelineEntry is a timestamped occurrence of bundle data. Warning is true for warning events, failed conditions
+// and containers terminated with error.
+type TimelineEntry struct {
+	Time    time.Time      `json:"time"`
+	Source  TimelineSource `json:"source"`
+	Object  string         `json:"object"`
+	Message string         `json:"message"`
+	Warning bool           `json:"warning,omitempty"`
+}
+
+// TimelineFilter selects timeline entries of 'Sources' in time window [Start, End] whose object or message match
+// 'Match'. Zero Start or End leaves window open, empty Sources selects all sources.
+type TimelineFilter struct {
+	Start   time.Time
+	End     time.Time
+	Sources []TimelineSource
+	Match   *regexp.Regexp
+}
+
+// selects returns true if 'source' is selected by filter
+func (f *TimelineFilter) selects(source TimelineSource) bool {
+	if len(f.Sources) == 0 {
+		return true
+	}
+	for _, selected := range f.Sources {
+		if selected == source {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns true if 'entry' is in time window of filter and matches its expression
+func (f *TimelineFilter) matches(entry *TimelineEntry) bool {
+	if entry.Time.IsZero() || (!f.Start.IsZero() && entry.Time.Before(f.Start)) ||
+		(!f.End.IsZero() && entry.Time.After(f.End)) {
+		return false
+	}
+	return f.Match == nil || f.Match.MatchString(entry.Object) || f.Match.MatchString(entry.Message)
+}
+
+// BuildTimeline returns entries of bundle directory, zip or tar.gz at 'bundlePath' selected by 'filter', sorted by
+// time. Entries are reconstructed from events, status conditions of Backup, Restore and Target resources, start and
+// termination of pod containers and log lines having a timestamp.
+func BuildTimeline(bundlePath string, filter *TimelineFilter) ([]TimelineEntry, error) {
+	var entries []TimelineEntry
+	add := func(entry *TimelineEntry) {
+		if filter.matches(entry) {
+			entries = append(entries, *entry)
+		}
+	}
+
+	err := readBundle(bundlePath, func(name string, content []byte) error {
+		if strings.HasSuffix(name, ".log") {
+			if filter.selects(TimelineLog) {
+				logTimelineEntries(name, content, add)
+			}
+			return nil
+		}
+
+		events, err := parseBundleEvents(name, content)
+		if err != nil {
+			log.Warnf("Skipping %s : %s", name, err.Error())
+			return nil
+		}
+		if events != nil {
+			if filter.selects(TimelineEvent) {
+				for _, event := range events {
+					eventTimelineEntries(event, add)
+				}
+			}
+			return nil
+		}
+
+		obj, err := parseBundleObject(name, content)
+		if err != nil {
+			log.Warnf("Skipping %s : %s", name, err.Error())
+			return nil
+		}
+		if obj == nil {
+			return nil
+		}
+		switch {
+		case obj.GetKind() == Pod && filter.selects(TimelineContainer):
+			containerTimelineEntries(obj, add)
+		case timelineConditionKinds.Has(obj.GetKind()) && filter.selects(TimelineCondition):
+			conditionTimelineEntries(obj, add)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Time.Equal(entries[j].Time) {
+			return entries[i].Time.Before(entries[j].Time)
+		}
+		return entries[i].Object < entries[j].Object
+	})
+	return entries, nil
+}
+
+// eventTimelineEntries adds time event was first seen, and last seen if it is repeated
+func eventTimelineEntries(event *unstructured.Unstructured, add func(*TimelineEntry)) {
+	record := newEventRecord(event)
+	message := strings.TrimSpace(fmt.Sprintf("%s %s: %s", record.Type, record.Reason, record.Message))
+	warning := record.Type == eventTypeWarning
+
+	add(&TimelineEntry{Time: record.FirstTimestamp.Time, Source: TimelineEvent, Object: referenceName(&record.InvolvedObject),
+		Message: message, Warning: warning})
+	if record.Count > 1 && record.LastTimestamp.After(record.FirstTimestamp.Time) {
+		add(&TimelineEntry{Time: record.LastTimestamp.Time, Source: TimelineEvent, Object: referenceName(&record.InvolvedObject),
+			Message: fmt.Sprintf("%s (repeated %d times)", message, record.Count), Warning: warning})
+	}
+}
+
+// conditionTimelineEntries adds transitions of status conditions of Trilio resource, 'status.condition' of Trilio
+// resources and standard 'status.conditions'
+func conditionTimelineEntries(obj *unstructured.Unstructured, add func(*TimelineEntry)) {
+	for _, field := range []string{"condition", "conditions"} {
+		conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", field)
+		for _, condition := range conditions {
+			conditionMap, ok := condition.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			condObj := &unstructured.Unstructured{Object: conditionMap}
+			transitionTime := nestedTimeOf(condObj, []string{"timestamp"}, []string{"lastTransitionTime"},
+				[]string{"lastUpdateTime"})
+			phase := nestedStringOf(condObj, []string{"phase"}, []string{"type"})
+			status := nestedStringOf(condObj, []string{"status"})
+			reason := nestedStringOf(condObj, []string{"reason"})
+			message := strings.TrimSpace(fmt.Sprintf("%s %s", phase, status))
+			if reason != "" {
+				message = fmt.Sprintf("%s: %s", message, reason)
+			}
+			if detail := nestedStringOf(condObj, []string{"message"}); detail != "" {
+				message = fmt.Sprintf("%s, %s", message, detail)
+			}
+
+			add(&TimelineEntry{Time: transitionTime, Source: TimelineCondition, Object: objectName(obj), Message: message,
+				Warning: status == statusFailed || strings.Contains(strings.ToLower(reason), "fail")})
+		}
+	}
+}
+
+// containerTimelineEntries adds start of pod and start and termination of current and last state of its containers
+func containerTimelineEntries(obj *unstructured.Unstructured, add func(*TimelineEntry)) {
+	object := objectName(obj)
+	var pod corev1.Pod
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pod); err != nil {
+		log.Warnf("Skipping %s : %s", object, err.Error())
+		return
+	}
+	if pod.Status.StartTime != nil {
+		add(&TimelineEntry{Time: pod.Status.StartTime.Time, Source: TimelineContainer, Object: object,
+			Message: "Pod started"})
+	}
+
+	for _, typeStatuses := range []struct {
+		containerType string
+		statuses      []corev1.ContainerStatus
+	}{
+		{containerType: containerTypeInit, statuses: pod.Status.InitContainerStatuses},
+		{containerType: containerTypeApp, statuses: pod.Status.ContainerStatuses},
+		{containerType: containerTypeEphemeral, statuses: pod.Status.EphemeralContainerStatuses},
+	} {
+		containerStatusTimelineEntries(containerObjectName(object, typeStatuses.containerType), typeStatuses.statuses, add)
+	}
+}
+
+// containerObjectName returns name of container of type 'containerType' of pod 'object', without container name
+func containerObjectName(object, containerType string) string {
+	if containerType == containerTypeApp {
+		return object + " container"
+	}
+	return fmt.Sprintf("%s %s container", object, containerType)
+}
+
+// containerStatusTimelineEntries adds start and termination of current and last state of containers of 'statuses'
+func containerStatusTimelineEntries(object string, statuses []corev1.ContainerStatus, add func(*TimelineEntry)) {
+	for idx := range statuses {
+		containerObject := fmt.Sprintf("%s %s", object, statuses[idx].Name)
+		for _, state := range []corev1.ContainerState{statuses[idx].LastTerminationState, statuses[idx].State} {
+			if state.Running != nil {
+				add(&TimelineEntry{Time: state.Running.StartedAt.Time, Source: TimelineContainer, Object: containerObject,
+					Message: "Container started"})
+			}
+			if terminated := state.Terminated; terminated != nil {
+				add(&TimelineEntry{Time: terminated.StartedAt.Time, Source: TimelineContainer, Object: containerObject,
+					Message: "Container started"})
+				message := fmt.Sprintf("Container terminated with exit code %d", terminated.ExitCode)
+				if terminated.Reason != "" {
+					message = fmt.Sprintf("%s: %s", message, terminated.Reason)
+				}
+				add(&TimelineEntry{Time: terminated.FinishedAt.Time, Source: TimelineContainer, Object: containerObject,
+					Message: message, Warning: terminated.ExitCode != 0})
+			}
+		}
+	}
+}
+
+// logTimelineEntries adds log lines having a timestamp of pod log at 'name',
+// '<kind>/<namespace>/<pod>.<container>[.<init|ephemeral>].<current|previous>.log'. Lines without timestamp are
+// skipped.
+func logTimelineEntries(name string, content []byte, add func(*TimelineEntry)) {
+	namespace := path.Base(path.Dir(name))
+	fileName := strings.TrimSuffix(path.Base(name), ".log")
+	logType := path.Ext(fileName)
+	fileName = strings.TrimSuffix(fileName, logType)
+	containerType := strings.TrimPrefix(path.Ext(fileName), ".")
+	if (containerType == containerTypeInit || containerType == containerTypeEphemeral) &&
+		path.Ext(strings.TrimSuffix(fileName, "."+containerType)) != "" {
+		fileName = strings.TrimSuffix(fileName, "."+containerType)
+	} else {
+		containerType = containerTypeApp
+	}
+	container := strings.TrimPrefix(path.Ext(fileName), ".")
+	pod := fmt.Sprintf("pod %s/%s", namespace, strings.TrimSuffix(fileName, "."+container))
+	object := fmt.Sprintf("%s %s", containerObjectName(pod, containerType), container)
+	if logType == ".previous" {
+		object += " (previous)"
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if lineTime, ok := logLineTime(line); ok {
+			add(&TimelineEntry{Time: lineTime, Source: TimelineLog, Object: object, Message: strings.TrimSpace(line)})
+		}
+	}
+}
+
+// logLineTime returns timestamp found at start of log line, RFC3339 like or epoch of JSON log lines
+func logLineTime(line string) (time.Time, bool) {
+	prefix := line
+	if len(prefix) > logTimeSearchLength {
+		prefix = prefix[:logTimeSearchLength]
+	}
+
+	if match := logTimeRegex.FindStringSubmatch(prefix); match != nil {
+		value := strings.Replace(match[0], " ", "T", 1)
+		switch zone := match[2]; {
+		case zone == "":
+			value += "Z"
+		case len(zone) == 5:
+			value = value[:len(value)-2] + ":" + value[len(value)-2:]
+		}
+		if parsed, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return parsed, true
+		}
+	}
+
+	if match := logEpochRegex.FindStringSubmatch(prefix); match != nil {
+		if epoch, err := strconv.ParseFloat(match[2], 64); err == nil {
+			seconds := int64(epoch)
+			return time.Unix(seconds, int64((epoch-float64(seconds))*float64(time.Second))).UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// PrintTimeline prints timeline entries in text, json, yaml or html format
+func PrintTimeline(w io.Writer, entries []TimelineEntry, format string) error {
+	if format == FormatHTML {
+		return timelineTemplate.Execute(w, struct {
+			Entries []TimelineEntry
+			Sources []TimelineSource
+		}{Entries: entries, Sources: TimelineSources})
+	}
+	if printed, err := printStructured(w, entries, format); printed || err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		_, err := fmt.Fprintln(w, "No timeline entries found")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tSOURCE\tOBJECT\tMESSAGE")
+	for idx := range entries {
+		entry := &entries[idx]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", entry.Time.UTC().Format(time.RFC3339Nano), entry.Source, entry.Object,
+			strings.Join(strings.Fields(entry.Message), " "))
+	}
+	return tw.Flush()
+}
+
+// timelineTemplate renders timeline as a table which can be filtered by source and text in browser
+var timelineTemplate = template.Must(template.New("timeline").Funcs(template.FuncMap{
+	"formatTime": func(t time.Time) string { return t.UTC().Format(time.RFC3339Nano) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>TVK Timeline</title>
+<style>
+body { font-family: sans-serif; font-size: 13px; margin: 16px; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ddd; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { position: sticky; top: 0; background: #f5f5f5; }
+td.time { white-space: nowrap; font-family: monospace; }
+td.message { font-family: monospace; white-space: pre-wrap; word-break: break-all; }
+tr.warning { background: #fdecea; }
+tr.event td.source { color: #1565c0; }
+tr.condition td.source { color: #6a1b9a; }
+tr.container td.source { color: #2e7d32; }
+tr.log td.source { color: #616161; }
+#filters { margin-bottom: 12px; }
+</style>
+</head>
+<body>
+<div id="filters">
+<input id="text" type="search" placeholder="Filter by object or message" size="50">
+{{range .Sources}}<label><input type="checkbox" class="source" value="{{.}}" checked> {{.}}</label>
+{{end}}<label><input type="checkbox" id="warnings"> warnings only</label>
+<span id="count"></span>
+</div>
+<table>
+<thead><tr><th>Time</th><th>Source</th><th>Object</th><th>Message</th></tr></thead>
+<tbody>
+{{range .Entries}}<tr class="{{.Source}}{{if .Warning}} warning{{end}}" data-source="{{.Source}}">
+<td class="time">{{formatTime .Time}}</td><td class="source">{{.Source}}</td><td>{{.Object}}</td><td class="message">{{.Message}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+<script>
+function applyFilters() {
+  var text = document.getElementById("text").value.toLowerCase();
+  var warningsOnly = document.getElementById("warnings").checked;
+  var sources = {};
+  document.querySelectorAll("input.source").forEach(function (box) { sources[box.value] = box.checked; });
+  var shown = 0;
+  document.querySelectorAll("tbody tr").forEach(function (row) {
+    var visible = sources[row.dataset.source] && (!warningsOnly || row.classList.contains("warning")) &&
+      (text === "" || row.textContent.toLowerCase().indexOf(text) >= 0);
+    row.style.display = visible ? "" : "none";
+    if (visible) { shown++; }
+  });
+  document.getElementById("count").textContent = shown + " entries";
+}
+document.querySelectorAll("#filters input").forEach(function (input) { input.addEventListener("input", applyFilters); });
+applyFilters();
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,132 @@
+package logcollector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestLogLineTime(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want time.Time
+		ok   bool
+	}{
+		{name: "rfc3339 utc", line: "2021-06-08T10:15:30Z INFO started",
+			want: time.Date(2021, 6, 8, 10, 15, 30, 0, time.UTC), ok: true},
+		{name: "fraction and offset", line: "I0608 2021-06-08T10:15:30.250+05:30 started",
+			want: time.Date(2021, 6, 8, 4, 45, 30, 250000000, time.UTC), ok: true},
+		{name: "space separated without zone", line: "2021-06-08 10:15:30 level=info",
+			want: time.Date(2021, 6, 8, 10, 15, 30, 0, time.UTC), ok: true},
+		{name: "offset without colon", line: "ts=2021-06-08T10:15:30-0700 msg=done",
+			want: time.Date(2021, 6, 8, 17, 15, 30, 0, time.UTC), ok: true},
+		{name: "epoch of json log", line: `{"level":"info","ts":1623145530.5,"msg":"reconciled"}`,
+			want: time.Date(2021, 6, 8, 9, 45, 30, 500000000, time.UTC), ok: true},
+		{name: "timestamp beyond searched prefix", line: strings.Repeat("x", logTimeSearchLength) + " 2021-06-08T10:15:30Z"},
+		{name: "no timestamp", line: "starting server"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := logLineTime(tt.line)
+			if ok != tt.ok || !got.Equal(tt.want) {
+				t.Errorf("logLineTime(%q) = %v, %t, want %v, %t", tt.line, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestLogTimelineEntries(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		object string
+	}{
+		{name: "current log", file: "Pod/app/web-0.server.current.log", object: "pod app/web-0 container server"},
+		{name: "previous log", file: "Pod/app/web-0.server.previous.log",
+			object: "pod app/web-0 container server (previous)"},
+		{name: "init container log", file: "Pod/app/web-0.setup.init.current.log",
+			object: "pod app/web-0 init container setup"},
+		{name: "container named init", file: "Pod/app/web-0.init.current.log", object: "pod app/web-0 container init"},
+		{name: "ephemeral container log", file: "clusters/prod/Pod/app/web.0.debug.ephemeral.current.log",
+			object: "pod app/web.0 ephemeral container debug"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entries []TimelineEntry
+			logTimelineEntries(tt.file, []byte("2021-06-08T10:15:30Z started\nno timestamp\n"), func(entry *TimelineEntry) {
+				entries = append(entries, *entry)
+			})
+			if len(entries) != 1 {
+				t.Fatalf("logTimelineEntries() added %d entries, want 1", len(entries))
+			}
+			if entries[0].Object != tt.object || entries[0].Source != TimelineLog {
+				t.Errorf("logTimelineEntries() added %s entry of %q, want %s entry of %q", entries[0].Source,
+					entries[0].Object, TimelineLog, tt.object)
+			}
+		})
+	}
+}
+
+func TestBuildTimeline(t *testing.T) {
+	bundle := t.TempDir()
+	writeBundleFiles(t, NewDirSink(bundle), map[string][]byte{
+		"Events/app/pod.web.yaml": []byte("- type: Warning\n  reason: BackOff\n  count: 1\n" +
+			"  lastTimestamp: \"2021-06-08T10:00:00Z\"\n  involvedObject:\n    kind: Pod\n    namespace: app\n" +
+			"    name: web\n"),
+		"Backup/app/backup.yaml": []byte("apiVersion: triliovault.trilio.io/v1\nkind: Backup\nmetadata:\n" +
+			"  name: backup\n  namespace: app\nstatus:\n  condition:\n  - phase: Snapshot\n    status: Failed\n" +
+			"    timestamp: \"2021-06-08T10:02:00Z\"\n"),
+		"Pod/app/web.server.current.log": []byte("2021-06-08T10:01:00Z ERROR connection refused\n"),
+		"Events/app/pod.invalid.yaml":    []byte("- ["),
+		"Pod/app/invalid.yaml":           []byte("kind: ["),
+		"Pod/app/pod.yaml":               []byte("apiVersion: v1\nkind: Pod\nmetadata:\n  name: pod\n  namespace: app\nspec: []\n"),
+	})
+
+	hook := logtest.NewGlobal()
+	defer log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+
+	entries, err := BuildTimeline(bundle, &TimelineFilter{})
+	if err != nil {
+		t.Fatalf("BuildTimeline() error = %v", err)
+	}
+	var got []string
+	for idx := range entries {
+		got = append(got, string(entries[idx].Source)+" "+entries[idx].Object)
+	}
+	want := []string{"event pod app/web", "log pod app/web container server", "condition backup app/backup"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildTimeline() = %v, want %v", got, want)
+	}
+
+	var warnings []string
+	for _, entry := range hook.AllEntries() {
+		if entry.Level == log.WarnLevel {
+			warnings = append(warnings, entry.Message)
+		}
+	}
+	for _, skipped := range []string{"Events/app/pod.invalid.yaml", "Pod/app/invalid.yaml", "pod app/pod"} {
+		found := false
+		for _, warning := range warnings {
+			found = found || strings.HasPrefix(warning, "Skipping "+skipped+" : ")
+		}
+		if !found {
+			t.Errorf("BuildTimeline() didn't warn about skipping %s, warnings %v", skipped, warnings)
+		}
+	}
+
+	entries, err = BuildTimeline(bundle, &TimelineFilter{Sources: []TimelineSource{TimelineLog, TimelineCondition},
+		End: time.Date(2021, 6, 8, 10, 1, 30, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("BuildTimeline() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Source != TimelineLog {
+		t.Errorf("BuildTimeline() with filter = %v, want only log entry", entries)
+	}
+}
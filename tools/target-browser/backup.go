@@ -48,7 +48,24 @@ type BackupList struct {
 }
 
 // GetBackups returns backup list stored on mounted target with available options
+// If 'OrderBy' has multiple comma-separated keys or a key not supported by server, backups are ordered client-side.
 func (auth *AuthInfo) GetBackups(options *BackupListOptions, backupUIDs []string) error {
+	if !isServerSideOrdering(options.OrderBy, backupServerOrderingKeys) {
+		keys, err := parseOrderBy(options.OrderBy, BackupSelector, backupOrderingAliases)
+		if err != nil {
+			return err
+		}
+
+		queryOptions := *options
+		queryOptions.OrderBy = ""
+		response, err := auth.getOrderedResponse(internal.BackupAPIPath, &queryOptions, &queryOptions.CommonListOptions,
+			backupUIDs, BackupSelector, keys)
+		if err != nil {
+			return err
+		}
+		return PrintFormattedResponse(internal.BackupAPIPath, string(response), options.OutputFormat)
+	}
+
 	values, err := query.Values(options)
 	if err != nil {
 		return err
@@ -62,6 +79,21 @@ func (auth *AuthInfo) GetBackups(options *BackupListOptions, backupUIDs []string
 	return PrintFormattedResponse(internal.BackupAPIPath, string(response), options.OutputFormat)
 }
 
+// ListAllBackups returns all backups stored on mounted target matching 'options' across all pages
+func (auth *AuthInfo) ListAllBackups(options *BackupListOptions) ([]Backup, error) {
+	queryOptions := *options
+	results, err := auth.getAllPages(internal.BackupAPIPath, &queryOptions, &queryOptions.CommonListOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []Backup
+	if err = selectColumns(results, BackupSelector, &backups); err != nil {
+		return nil, err
+	}
+	return backups, nil
+}
+
 func (auth *AuthInfo) TriggerAPI(apiPath, queryParam string) ([]byte, error) {
 	tvkURL, err := url.Parse(auth.TvkHost)
 	if err != nil {
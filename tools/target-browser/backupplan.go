@@ -37,7 +37,24 @@ type BackupPlanList struct {
 }
 
 // GetBackupPlans returns backupPlan list stored on mounted target with available options
+// If 'OrderBy' has multiple comma-separated keys or a key not supported by server, backupPlans are ordered client-side.
 func (auth *AuthInfo) GetBackupPlans(options *BackupPlanListOptions, backupPlanUIDs []string) error {
+	if !isServerSideOrdering(options.OrderBy, backupPlanServerOrderingKeys) {
+		keys, err := parseOrderBy(options.OrderBy, BackupPlanSelector, backupPlanOrderingAliases)
+		if err != nil {
+			return err
+		}
+
+		queryOptions := *options
+		queryOptions.OrderBy = ""
+		response, err := auth.getOrderedResponse(internal.BackupPlanAPIPath, &queryOptions, &queryOptions.CommonListOptions,
+			backupPlanUIDs, BackupPlanSelector, keys)
+		if err != nil {
+			return err
+		}
+		return PrintFormattedResponse(internal.BackupPlanAPIPath, string(response), options.OutputFormat)
+	}
+
 	values, err := query.Values(options)
 	if err != nil {
 		return err
@@ -52,6 +69,21 @@ func (auth *AuthInfo) GetBackupPlans(options *BackupPlanListOptions, backupPlanU
 	return PrintFormattedResponse(internal.BackupPlanAPIPath, string(response), options.OutputFormat)
 }
 
+// ListAllBackupPlans returns all backupPlans stored on mounted target matching 'options' across all pages
+func (auth *AuthInfo) ListAllBackupPlans(options *BackupPlanListOptions) ([]BackupPlan, error) {
+	queryOptions := *options
+	results, err := auth.getAllPages(internal.BackupPlanAPIPath, &queryOptions, &queryOptions.CommonListOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var backupPlans []BackupPlan
+	if err = selectColumns(results, BackupPlanSelector, &backupPlans); err != nil {
+		return nil, err
+	}
+	return backupPlans, nil
+}
+
 // normalizeBPlanDataToRowsAndColumns normalizes backupPlan API response and generates metav1.TableRow & metav1.TableColumnDefinition
 // which will be used for printing table formatted output.
 // If 'wideOutput=true', then all defined fields of Backup struct will be printed as output columns
@@ -0,0 +1,839 @@
+package targetbrowser
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-querystring/query"
+	"github.com/mitchellh/go-homedir"
+	log "github.com/sirupsen/logrus"
+
+	// registers pure go 'sqlite' driver for database/sql
+	_ "modernc.org/sqlite"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+const (
+	indexDriverName = "sqlite"
+	indexDir        = ".tvk-target-browser"
+
+	lastBackupSyncKey  = "lastBackupSyncTimestamp"
+	schemaVersionKey   = "schemaVersion"
+	defaultIndexPerm   = 0700
+	indexSchemaVersion = "1"
+
+	// syncOverlap is subtracted from timestamp of last sync when listing objects created since last sync, so that
+	// objects created on target while last sync was running are not missed
+	syncOverlap = 5 * time.Minute
+	// failedStatus and availableStatus are final statuses of backup, other statuses are refreshed by later syncs
+	failedStatus = "Failed"
+)
+
+// indexTables are tables of index created by indexSchema
+var indexTables = []string{"backup_plans", "backups", "metadata", "sync_state"}
+
+var indexSchema = []string{
+	`CREATE TABLE IF NOT EXISTS backup_plans (
+		uid TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		tvk_instance_uid TEXT,
+		creation_timestamp TEXT,
+		data TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS backups (
+		uid TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		backup_plan_uid TEXT,
+		tvk_instance_uid TEXT,
+		status TEXT,
+		creation_timestamp TEXT,
+		expiration_timestamp TEXT,
+		data TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS metadata (
+		backup_uid TEXT PRIMARY KEY,
+		backup_plan_uid TEXT NOT NULL,
+		data TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS sync_state (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS backups_backup_plan_uid ON backups (backup_plan_uid)`,
+}
+
+// Index is a local SQLite catalogue of backupPlans, backups and their metadata synced from mounted target,
+// which can be queried when cluster or target-browser is not reachable.
+type Index struct {
+	db *sql.DB
+}
+
+// SyncResult contains number of objects synced into Index
+type SyncResult struct {
+	BackupPlans, Backups, Metadata int
+	// Removed is number of backupPlans and backups removed from index as they were deleted or expired from target
+	Removed     int
+	Incremental bool
+}
+
+// DefaultIndexPath returns default path of local index for target 'targetName' in 'targetNamespace'
+func DefaultIndexPath(targetNamespace, targetName string) (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, indexDir, fmt.Sprintf("%s-%s.db", targetNamespace, targetName)), nil
+}
+
+// OpenIndex opens local index at 'indexPath'. If 'create' is true, index is created if it doesn't exist,
+// otherwise error is returned.
+func OpenIndex(indexPath string, create bool) (*Index, error) {
+	if _, err := os.Stat(indexPath); err != nil {
+		if !os.IsNotExist(err) || !create {
+			return nil, fmt.Errorf("failed to open index %s - %s", indexPath, err.Error())
+		}
+		if err = os.MkdirAll(filepath.Dir(indexPath), defaultIndexPerm); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := sql.Open(indexDriverName, indexPath)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &Index{db: db}
+	if err = idx.initialize(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialize index %s - %s", indexPath, err.Error())
+	}
+	return idx, nil
+}
+
+// initialize creates tables of index. Index synced with a different schema version is rebuilt, it is populated
+// again by next sync.
+func (idx *Index) initialize() error {
+	if err := idx.createSchema(); err != nil {
+		return err
+	}
+
+	version, err := idx.getSyncState(schemaVersionKey)
+	if err != nil {
+		return err
+	}
+	if version == "" || version == indexSchemaVersion {
+		return nil
+	}
+
+	log.Warnf("index schema version %s differs from %s, rebuilding index, sync index to populate it", version,
+		indexSchemaVersion)
+	for _, table := range indexTables {
+		if _, err = idx.db.Exec("DROP TABLE IF EXISTS " + table); err != nil {
+			return err
+		}
+	}
+	return idx.createSchema()
+}
+
+func (idx *Index) createSchema() error {
+	for _, stmt := range indexSchema {
+		if _, err := idx.db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes underlying database of index
+func (idx *Index) Close() error {
+	return idx.db.Close()
+}
+
+// SyncIndex pulls backupPlans, backups and metadata of backups from mounted target into 'idx'. First sync, or sync with
+// 'full' true, lists all backupPlans and backups and rebuilds index. Later syncs are incremental, see
+// listIncrementalChanges. Metadata is fetched only for backups which are new or whose status changed since last sync.
+func (auth *AuthInfo) SyncIndex(idx *Index, full bool) (*SyncResult, error) {
+	syncStart := time.Now().UTC()
+
+	var (
+		lastSync string
+		changes  *indexChanges
+		err      error
+	)
+	if !full {
+		if lastSync, err = idx.getSyncState(lastBackupSyncKey); err != nil {
+			return nil, err
+		}
+	}
+
+	lastSyncTime, pErr := time.Parse(time.RFC3339, lastSync)
+	switch {
+	case lastSync == "":
+		changes, err = auth.listAllChanges()
+	case pErr != nil:
+		log.Warnf("invalid timestamp of last sync %q, syncing all backupPlans and backups - %s", lastSync, pErr.Error())
+		changes, err = auth.listAllChanges()
+	default:
+		changes, err = auth.listIncrementalChanges(idx, lastSyncTime)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return auth.applyIndexChanges(idx, changes, syncStart.Format(time.RFC3339))
+}
+
+// indexChanges are backupPlans and backups pulled from target to be written to index
+type indexChanges struct {
+	incremental          bool
+	backupPlans, backups []interface{}
+	// removedBackupPlans and removedBackups are UIDs of indexed objects deleted or expired from target
+	removedBackupPlans, removedBackups map[string]bool
+}
+
+// listAllChanges lists all backupPlans and backups of target to rebuild index
+func (auth *AuthInfo) listAllChanges() (*indexChanges, error) {
+	backupPlans, err := auth.listCreated(internal.BackupPlanAPIPath, CommonListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	backups, err := auth.listCreated(internal.BackupAPIPath, CommonListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &indexChanges{backupPlans: backupPlans, backups: backups}, nil
+}
+
+// listIncrementalChanges lists backupPlans and backups created since 'lastSync', minus syncOverlap. Older objects are
+// listed again only if their number on target differs from index, which happens when they were deleted or expired.
+// Otherwise older backups whose status may still change are refreshed by listing backups of their backupPlans.
+// BackupPlans of listed backups are refreshed as their generated fields change with new backups.
+func (auth *AuthInfo) listIncrementalChanges(idx *Index, lastSync time.Time) (*indexChanges, error) {
+	since := lastSync.Add(-syncOverlap).UTC().Format(time.RFC3339)
+	changes := &indexChanges{incremental: true}
+
+	var err error
+	if changes.backupPlans, err = auth.listCreated(internal.BackupPlanAPIPath,
+		CommonListOptions{CreationStartTimestamp: since}); err != nil {
+		return nil, err
+	}
+	if changes.backups, err = auth.listCreated(internal.BackupAPIPath,
+		CommonListOptions{CreationStartTimestamp: since}); err != nil {
+		return nil, err
+	}
+
+	olderBackupPlans, removedBackupPlans, err := auth.listOlderIfChanged(idx, internal.BackupPlanAPIPath,
+		"backup_plans", since)
+	if err != nil {
+		return nil, err
+	}
+	changes.backupPlans = append(changes.backupPlans, olderBackupPlans...)
+	changes.removedBackupPlans = removedBackupPlans
+
+	olderBackups, removedBackups, err := auth.listOlderIfChanged(idx, internal.BackupAPIPath, "backups", since)
+	if err != nil {
+		return nil, err
+	}
+	changes.backups = append(changes.backups, olderBackups...)
+	changes.removedBackups = removedBackups
+	if removedBackups == nil {
+		if err = auth.listPendingBackups(idx, since, changes); err != nil {
+			return nil, err
+		}
+	}
+
+	auth.listBackupPlansOfBackups(changes)
+	return changes, nil
+}
+
+// listCreated returns all objects of 'apiPath' filtered by creation timestamps of 'options'
+func (auth *AuthInfo) listCreated(apiPath string, options CommonListOptions) ([]interface{}, error) {
+	return auth.getAllPages(apiPath, &options, &options)
+}
+
+// listOlderIfChanged lists objects of 'apiPath' created until 'until' if their number on target differs from number
+// of objects of index 'table' created until then, and returns them along with UIDs of indexed objects not listed.
+// Nil UIDs are returned if numbers are equal.
+func (auth *AuthInfo) listOlderIfChanged(idx *Index, apiPath, table, until string) ([]interface{}, map[string]bool,
+	error) {
+	indexed, err := idx.getUIDs("SELECT uid FROM "+table+" WHERE creation_timestamp <= ?", until)
+	if err != nil {
+		return nil, nil, err
+	}
+	total, err := auth.countCreatedUntil(apiPath, until)
+	if err != nil {
+		return nil, nil, err
+	}
+	if total == len(indexed) {
+		return nil, nil, nil
+	}
+
+	objects, err := auth.listCreated(apiPath, CommonListOptions{CreationEndTimestamp: until})
+	if err != nil {
+		return nil, nil, err
+	}
+	removed := make(map[string]bool)
+	for uid := range indexed {
+		removed[uid] = true
+	}
+	for _, object := range objects {
+		obj, _ := object.(map[string]interface{})
+		delete(removed, nestedString(obj, "metadata", "uid"))
+	}
+	return objects, removed, nil
+}
+
+// countCreatedUntil returns number of objects of 'apiPath' on target created until 'until' by fetching a page of
+// single object, -1 is returned if target doesn't return total
+func (auth *AuthInfo) countCreatedUntil(apiPath, until string) (int, error) {
+	values, err := query.Values(CommonListOptions{CreationEndTimestamp: until, Page: 1, PageSize: 1})
+	if err != nil {
+		return 0, err
+	}
+	resp, err := auth.TriggerAPI(apiPath, values.Encode())
+	if err != nil {
+		return 0, err
+	}
+	var response paginatedResponse
+	if err = json.Unmarshal(resp, &response); err != nil {
+		return 0, err
+	}
+	if response.Metadata == nil {
+		return -1, nil
+	}
+	return response.Metadata.Total, nil
+}
+
+// listPendingBackups lists backups of backupPlans having indexed backups created until 'until' whose status is not
+// final into 'changes', indexed backups of those backupPlans not listed are removed
+func (auth *AuthInfo) listPendingBackups(idx *Index, until string, changes *indexChanges) error {
+	pending, err := idx.getColumnByUID(`SELECT uid, backup_plan_uid FROM backups WHERE creation_timestamp <= ?
+		AND COALESCE(status, '') NOT IN (?, ?)`, until, availableStatus, failedStatus)
+	if err != nil {
+		return err
+	}
+
+	changes.removedBackups = make(map[string]bool)
+	listed := make(map[string]bool)
+	for _, backupPlanUID := range pending {
+		if listed[backupPlanUID] {
+			continue
+		}
+		listed[backupPlanUID] = true
+
+		options := BackupListOptions{BackupPlanUID: backupPlanUID}
+		backups, lErr := auth.getAllPages(internal.BackupAPIPath, &options, &options.CommonListOptions)
+		if lErr != nil {
+			return lErr
+		}
+		indexed, iErr := idx.getUIDs(`SELECT uid FROM backups WHERE backup_plan_uid = ?`, backupPlanUID)
+		if iErr != nil {
+			return iErr
+		}
+		for _, backup := range backups {
+			obj, _ := backup.(map[string]interface{})
+			delete(indexed, nestedString(obj, "metadata", "uid"))
+		}
+		for uid := range indexed {
+			changes.removedBackups[uid] = true
+		}
+		changes.backups = append(changes.backups, backups...)
+	}
+	return nil
+}
+
+// listBackupPlansOfBackups gets backupPlans of backups of 'changes' which are not listed into 'changes', backupPlans
+// which can't be retrieved are kept as indexed
+func (auth *AuthInfo) listBackupPlansOfBackups(changes *indexChanges) {
+	listed := make(map[string]bool)
+	for _, backupPlan := range changes.backupPlans {
+		obj, _ := backupPlan.(map[string]interface{})
+		listed[nestedString(obj, "metadata", "uid")] = true
+	}
+
+	for _, backup := range changes.backups {
+		obj, _ := backup.(map[string]interface{})
+		backupPlanUID := getBackupPlanUIDOfBackup(obj)
+		if backupPlanUID == "" || listed[backupPlanUID] || changes.removedBackupPlans[backupPlanUID] {
+			continue
+		}
+		listed[backupPlanUID] = true
+
+		resp, err := auth.TriggerAPI(path.Join(internal.BackupPlanAPIPath, backupPlanUID), "")
+		if err != nil {
+			log.Warnf("failed to refresh backupPlan %s - %s", backupPlanUID, err.Error())
+			continue
+		}
+		var backupPlan interface{}
+		if err = json.Unmarshal(resp, &backupPlan); err != nil {
+			log.Warnf("failed to refresh backupPlan %s - %s", backupPlanUID, err.Error())
+			continue
+		}
+		changes.backupPlans = append(changes.backupPlans, backupPlan)
+	}
+}
+
+// applyIndexChanges writes 'changes' to 'idx' in a transaction, index is rebuilt if 'changes' are not incremental.
+// Metadata is fetched for backups which are new or whose status changed.
+func (auth *AuthInfo) applyIndexChanges(idx *Index, changes *indexChanges, syncStart string) (*SyncResult, error) {
+	result := &SyncResult{Incremental: changes.incremental}
+
+	var (
+		indexedStatus   map[string]string
+		indexedMetadata map[string]bool
+		err             error
+	)
+	if changes.incremental {
+		if indexedStatus, err = idx.getColumnByUID(`SELECT uid, status FROM backups`); err != nil {
+			return nil, err
+		}
+		if indexedMetadata, err = idx.getUIDs(`SELECT backup_uid FROM metadata`); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := idx.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if !changes.incremental {
+		for _, table := range []string{"backup_plans", "backups", "metadata"} {
+			if _, err = tx.Exec("DELETE FROM " + table); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	syncedUIDs := make(map[string]bool)
+	for _, backupPlan := range changes.backupPlans {
+		obj, _ := backupPlan.(map[string]interface{})
+		uid := nestedString(obj, "metadata", "uid")
+		if syncedUIDs[uid] {
+			continue
+		}
+		if err = upsertBackupPlan(tx, backupPlan); err != nil {
+			return nil, err
+		}
+		syncedUIDs[uid] = true
+		result.BackupPlans++
+	}
+
+	for _, backup := range changes.backups {
+		obj, _ := backup.(map[string]interface{})
+		backupUID, backupPlanUID := nestedString(obj, "metadata", "uid"), getBackupPlanUIDOfBackup(obj)
+		if syncedUIDs[backupUID] {
+			continue
+		}
+		if err = upsertBackup(tx, backup); err != nil {
+			return nil, err
+		}
+		syncedUIDs[backupUID] = true
+		result.Backups++
+
+		if changes.incremental && indexedMetadata[backupUID] &&
+			indexedStatus[backupUID] == nestedString(obj, "status", "status") {
+			continue
+		}
+
+		values, qErr := query.Values(MetadataListOptions{BackupUID: backupUID, BackupPlanUID: backupPlanUID})
+		if qErr != nil {
+			err = qErr
+			return nil, err
+		}
+		metadata, mErr := auth.TriggerAPI(internal.MetadataAPIPath, values.Encode())
+		if mErr != nil {
+			// metadata may not be available for failed or in-progress backups, listing is still useful without it
+			log.Warnf("failed to get metadata of backup %s - %s", backupUID, mErr.Error())
+			continue
+		}
+		if _, err = tx.Exec(`INSERT OR REPLACE INTO metadata (backup_uid, backup_plan_uid, data) VALUES (?, ?, ?)`,
+			backupUID, backupPlanUID, string(metadata)); err != nil {
+			return nil, err
+		}
+		result.Metadata++
+	}
+
+	removed := map[string]map[string]bool{"backup_plans": changes.removedBackupPlans, "backups": changes.removedBackups}
+	for _, table := range []string{"backup_plans", "backups"} {
+		for uid := range removed[table] {
+			if syncedUIDs[uid] {
+				continue
+			}
+			res, dErr := tx.Exec("DELETE FROM "+table+" WHERE uid = ?", uid)
+			if dErr != nil {
+				err = dErr
+				return nil, err
+			}
+			deleted, _ := res.RowsAffected()
+			result.Removed += int(deleted)
+		}
+	}
+	if changes.incremental {
+		if _, err = tx.Exec(`DELETE FROM metadata WHERE backup_uid NOT IN (SELECT uid FROM backups)`); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err = tx.Exec(`INSERT OR REPLACE INTO sync_state (key, value) VALUES (?, ?), (?, ?)`,
+		lastBackupSyncKey, syncStart, schemaVersionKey, indexSchemaVersion); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBackups prints backups from index filtered & ordered using 'options', similar to AuthInfo.GetBackups
+func (idx *Index) GetBackups(options *BackupListOptions, backupUIDs []string) error {
+	keys, err := parseOrderBy(getOrderByOrDefault(options.OrderBy), BackupSelector, backupOrderingAliases)
+	if err != nil {
+		return err
+	}
+
+	results, err := idx.queryBackups(options, backupUIDs)
+	if err != nil {
+		return err
+	}
+
+	response, err := getIndexResponse(results, BackupSelector, keys, &options.CommonListOptions, len(backupUIDs) > 0)
+	if err != nil {
+		return err
+	}
+	return PrintFormattedResponse(internal.BackupAPIPath, string(response), options.OutputFormat)
+}
+
+// GetBackupPlans prints backupPlans from index filtered & ordered using 'options', similar to AuthInfo.GetBackupPlans
+func (idx *Index) GetBackupPlans(options *BackupPlanListOptions, backupPlanUIDs []string) error {
+	keys, err := parseOrderBy(getOrderByOrDefault(options.OrderBy), BackupPlanSelector, backupPlanOrderingAliases)
+	if err != nil {
+		return err
+	}
+
+	results, err := idx.queryBackupPlans(options, backupPlanUIDs)
+	if err != nil {
+		return err
+	}
+
+	response, err := getIndexResponse(results, BackupPlanSelector, keys, &options.CommonListOptions, len(backupPlanUIDs) > 0)
+	if err != nil {
+		return err
+	}
+	return PrintFormattedResponse(internal.BackupPlanAPIPath, string(response), options.OutputFormat)
+}
+
+// GetMetadata prints metadata of backup from index, similar to AuthInfo.GetMetadata
+func (idx *Index) GetMetadata(options *MetadataListOptions) error {
+	var data string
+	err := idx.db.QueryRow(`SELECT data FROM metadata WHERE backup_uid = ? AND backup_plan_uid = ?`,
+		options.BackupUID, options.BackupPlanUID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("metadata of backup %s backupPlan %s not found in index, sync index to retrieve it",
+			options.BackupUID, options.BackupPlanUID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if options.OutputFormat == "" || options.OutputFormat == internal.FormatWIDE {
+		options.OutputFormat = internal.FormatYAML
+	}
+	return PrintFormattedResponse(internal.MetadataAPIPath, data, options.OutputFormat)
+}
+
+// ListAllBackups returns all backups from index matching 'options'
+func (idx *Index) ListAllBackups(options *BackupListOptions) ([]Backup, error) {
+	results, err := idx.queryBackups(options, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []Backup
+	if err = selectColumns(results, BackupSelector, &backups); err != nil {
+		return nil, err
+	}
+	return backups, nil
+}
+
+// ListAllBackupPlans returns all backupPlans from index matching 'options'
+func (idx *Index) ListAllBackupPlans(options *BackupPlanListOptions) ([]BackupPlan, error) {
+	results, err := idx.queryBackupPlans(options, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var backupPlans []BackupPlan
+	if err = selectColumns(results, BackupPlanSelector, &backupPlans); err != nil {
+		return nil, err
+	}
+	return backupPlans, nil
+}
+
+// ResolveBackupPlanUID returns UID of backupPlan with given 'name' from index
+func (idx *Index) ResolveBackupPlanUID(name, tvkInstanceUID string) (string, error) {
+	return resolveBackupPlanUID(idx, name, tvkInstanceUID)
+}
+
+// ResolveBackup returns backup with given 'name' from index
+func (idx *Index) ResolveBackup(name, backupPlanUID, tvkInstanceUID string) (*Backup, error) {
+	return resolveBackup(idx, name, backupPlanUID, tvkInstanceUID)
+}
+
+func (idx *Index) queryBackups(options *BackupListOptions, backupUIDs []string) ([]interface{}, error) {
+	q := newIndexQuery("SELECT data FROM backups")
+	q.in("uid", backupUIDs)
+	q.equal("backup_plan_uid", options.BackupPlanUID)
+	q.equal("status", options.BackupStatus)
+	q.equal("tvk_instance_uid", options.TvkInstanceUID)
+	q.equal("kind", getKindForOperationScope(options.OperationScope, internal.BackupKind, internal.ClusterBackupKind))
+	q.between("creation_timestamp", options.CreationStartTimestamp, options.CreationEndTimestamp)
+	q.between("expiration_timestamp", options.ExpirationStartTimestamp, options.ExpirationEndTimestamp)
+	return idx.queryData(q)
+}
+
+func (idx *Index) queryBackupPlans(options *BackupPlanListOptions, backupPlanUIDs []string) ([]interface{}, error) {
+	q := newIndexQuery("SELECT data FROM backup_plans")
+	q.in("uid", backupPlanUIDs)
+	q.equal("tvk_instance_uid", options.TvkInstanceUID)
+	q.equal("kind", getKindForOperationScope(options.OperationScope, internal.BackupPlanKind, internal.ClusterBackupPlanKind))
+	q.between("creation_timestamp", options.CreationStartTimestamp, options.CreationEndTimestamp)
+	return idx.queryData(q)
+}
+
+func (idx *Index) queryData(q *indexQuery) ([]interface{}, error) {
+	rows, err := idx.db.Query(q.String(), q.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []interface{}
+	for rows.Next() {
+		var data string
+		if err = rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var result interface{}
+		if err = json.Unmarshal([]byte(data), &result); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+func (idx *Index) getSyncState(key string) (string, error) {
+	var value string
+	err := idx.db.QueryRow(`SELECT value FROM sync_state WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return value, err
+}
+
+// getUIDs returns set of UIDs selected by query 'q' with 'args'
+func (idx *Index) getUIDs(q string, args ...interface{}) (map[string]bool, error) {
+	rows, err := idx.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	uids := make(map[string]bool)
+	for rows.Next() {
+		var uid string
+		if err = rows.Scan(&uid); err != nil {
+			return nil, err
+		}
+		uids[uid] = true
+	}
+	return uids, rows.Err()
+}
+
+// getColumnByUID returns values of column keyed by UID selected by query 'q' of form SELECT uid, column with 'args'
+func (idx *Index) getColumnByUID(q string, args ...interface{}) (map[string]string, error) {
+	rows, err := idx.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var uid string
+		var value sql.NullString
+		if err = rows.Scan(&uid, &value); err != nil {
+			return nil, err
+		}
+		values[uid] = value.String
+	}
+	return values, rows.Err()
+}
+
+func upsertBackupPlan(tx *sql.Tx, backupPlan interface{}) error {
+	obj, ok := backupPlan.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected backupPlan data type %T", backupPlan)
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT OR REPLACE INTO backup_plans (uid, name, kind, tvk_instance_uid, creation_timestamp, data)
+		VALUES (?, ?, ?, ?, ?, ?)`, nestedString(obj, "metadata", "uid"), nestedString(obj, "metadata", "name"),
+		nestedString(obj, "kind"), nestedString(obj, "generatedField", "tvkInstanceUID"),
+		normalizeTimestamp(nestedString(obj, "metadata", "creationTimestamp")), string(data))
+	return err
+}
+
+func upsertBackup(tx *sql.Tx, backup interface{}) error {
+	obj, ok := backup.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected backup data type %T", backup)
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT OR REPLACE INTO backups (uid, name, kind, backup_plan_uid, tvk_instance_uid, status,
+		creation_timestamp, expiration_timestamp, data) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		nestedString(obj, "metadata", "uid"), nestedString(obj, "metadata", "name"), nestedString(obj, "kind"),
+		getBackupPlanUIDOfBackup(obj), nestedString(obj, "generatedField", "tvkInstanceUID"),
+		nestedString(obj, "status", "status"), normalizeTimestamp(nestedString(obj, "metadata", "creationTimestamp")),
+		normalizeTimestamp(nestedString(obj, "status", "expirationTimestamp")), string(data))
+	return err
+}
+
+// getIndexResponse orders 'results' on 'keys' and paginates them if 'specificUIDs' is false
+func getIndexResponse(results []interface{}, selector []string, keys []orderingKey, options *CommonListOptions,
+	specificUIDs bool) ([]byte, error) {
+
+	var (
+		response paginatedResponse
+		err      error
+	)
+	response.Results, err = sortResults(results, selector, keys)
+	if err != nil {
+		return nil, err
+	}
+	if response.Results == nil {
+		response.Results = []interface{}{}
+	}
+
+	if !specificUIDs {
+		response.Results, response.Metadata = paginate(response.Results, options.Page, options.PageSize)
+	}
+	return json.Marshal(response)
+}
+
+// indexQuery builds SQL query with optional filters, filters with empty values are skipped
+type indexQuery struct {
+	base       string
+	conditions []string
+	args       []interface{}
+}
+
+func newIndexQuery(base string) *indexQuery {
+	return &indexQuery{base: base}
+}
+
+func (q *indexQuery) equal(column, value string) {
+	if value == "" {
+		return
+	}
+	q.conditions = append(q.conditions, column+" = ?")
+	q.args = append(q.args, value)
+}
+
+func (q *indexQuery) in(column string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	q.conditions = append(q.conditions, fmt.Sprintf("%s IN (?%s)", column, strings.Repeat(", ?", len(values)-1)))
+	for _, value := range values {
+		q.args = append(q.args, value)
+	}
+}
+
+func (q *indexQuery) between(column, start, end string) {
+	if start != "" {
+		q.conditions = append(q.conditions, column+" >= ?")
+		q.args = append(q.args, normalizeTimestamp(start))
+	}
+	if end != "" {
+		q.conditions = append(q.conditions, column+" <= ?")
+		q.args = append(q.args, normalizeTimestamp(end))
+	}
+}
+
+func (q *indexQuery) String() string {
+	if len(q.conditions) == 0 {
+		return q.base
+	}
+	return q.base + " WHERE " + strings.Join(q.conditions, " AND ")
+}
+
+// getKindForOperationScope maps operationScope filter to kind of backup or backupPlan
+func getKindForOperationScope(operationScope, singleNamespaceKind, multiNamespaceKind string) string {
+	switch operationScope {
+	case internal.SingleNamespace:
+		return singleNamespaceKind
+	case internal.MultiNamespace:
+		return multiNamespaceKind
+	default:
+		return ""
+	}
+}
+
+func getOrderByOrDefault(orderBy string) string {
+	if orderBy == "" {
+		return "name"
+	}
+	return orderBy
+}
+
+// getBackupPlanUIDOfBackup returns UID of backupPlan or clusterBackupPlan of backup
+func getBackupPlanUIDOfBackup(obj map[string]interface{}) string {
+	if uid := nestedString(obj, "spec", "backupPlan", "uid"); uid != "" {
+		return uid
+	}
+	return nestedString(obj, "spec", "clusterBackupPlan", "uid")
+}
+
+// normalizeTimestamp converts RFC3339 timestamp to UTC so that timestamps stored in index are comparable as strings
+func normalizeTimestamp(timestamp string) string {
+	ts, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return timestamp
+	}
+	return ts.UTC().Format(time.RFC3339)
+}
+
+func nestedString(obj map[string]interface{}, fields ...string) string {
+	var val interface{} = obj
+	for _, field := range fields {
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		val = m[field]
+	}
+	if s, ok := val.(string); ok {
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,281 @@
+package targetbrowser
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+const oldTimestamp = "2021-01-01T00:00:00Z"
+
+// fakeTarget serves backupPlans, backups and metadata of target-browser API filtered by creation timestamps and
+// backupPlan, and paginated
+type fakeTarget struct {
+	backupPlans []interface{}
+	backups     []interface{}
+	// metadataCalls are backup UIDs metadata was requested for
+	metadataCalls []string
+	// listQueries are queries of backupPlan and backup list requests
+	listQueries []url.Values
+}
+
+func (f *fakeTarget) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var response interface{}
+	apiPath, q := strings.TrimPrefix(r.URL.Path, "/"), r.URL.Query()
+	switch {
+	case apiPath == internal.BackupPlanAPIPath:
+		f.listQueries = append(f.listQueries, q)
+		response = listResponse(f.backupPlans, q)
+	case apiPath == internal.BackupAPIPath:
+		f.listQueries = append(f.listQueries, q)
+		response = listResponse(f.backups, q)
+	case strings.HasPrefix(apiPath, internal.BackupPlanAPIPath+"/"):
+		for _, backupPlan := range f.backupPlans {
+			if nestedString(backupPlan.(map[string]interface{}), "metadata", "uid") == path.Base(apiPath) {
+				response = backupPlan
+			}
+		}
+	case apiPath == internal.MetadataAPIPath:
+		uid := q.Get("backupUID")
+		f.metadataCalls = append(f.metadataCalls, uid)
+		response = map[string]interface{}{"backupUID": uid}
+	}
+	if response == nil {
+		http.NotFound(w, r)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// listResponse returns page of 'objects' matching filters of query 'q'
+func listResponse(objects []interface{}, q url.Values) paginatedResponse {
+	var results []interface{}
+	for _, object := range objects {
+		obj := object.(map[string]interface{})
+		created := nestedString(obj, "metadata", "creationTimestamp")
+		if (q.Get("creationStartTimestamp") != "" && created < q.Get("creationStartTimestamp")) ||
+			(q.Get("creationEndTimestamp") != "" && created > q.Get("creationEndTimestamp")) ||
+			(q.Get("backupPlanUID") != "" && getBackupPlanUIDOfBackup(obj) != q.Get("backupPlanUID")) {
+			continue
+		}
+		results = append(results, object)
+	}
+
+	metadata := &ListMetadata{Total: len(results)}
+	page, _ := strconv.Atoi(q.Get("page"))
+	pageSize, _ := strconv.Atoi(q.Get("pageSize"))
+	if page > 0 && pageSize > 0 {
+		start, end := (page-1)*pageSize, page*pageSize
+		if end < len(results) {
+			metadata.Next = page + 1
+		} else {
+			end = len(results)
+		}
+		if start > end {
+			start = end
+		}
+		results = results[start:end]
+	}
+	return paginatedResponse{Metadata: metadata, Results: results}
+}
+
+func testObject(kind, uid, status, created string) interface{} {
+	obj := map[string]interface{}{
+		"kind":     kind,
+		"metadata": map[string]interface{}{"uid": uid, "name": uid + "-name", "creationTimestamp": created},
+		"spec":     map[string]interface{}{"backupPlan": map[string]interface{}{"uid": "bp-1"}},
+	}
+	if status != "" {
+		obj["status"] = map[string]interface{}{"status": status}
+	}
+	return obj
+}
+
+func indexUIDs(t *testing.T, idx *Index, table string) []string {
+	uids, err := idx.getUIDs("SELECT uid FROM " + table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list []string
+	for uid := range uids {
+		list = append(list, uid)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func TestSyncIndex(t *testing.T) {
+	target := &fakeTarget{
+		backupPlans: []interface{}{testObject(internal.BackupPlanKind, "bp-1", "", oldTimestamp),
+			testObject(internal.BackupPlanKind, "bp-2", "", oldTimestamp)},
+		backups: []interface{}{testObject(internal.BackupKind, "b-1", availableStatus, oldTimestamp),
+			testObject(internal.BackupKind, "b-2", "InProgress", oldTimestamp)},
+	}
+	server := httptest.NewServer(target)
+	defer server.Close()
+	auth := &AuthInfo{Client: server.Client(), TvkHost: server.URL}
+
+	idx, err := OpenIndex(filepath.Join(t.TempDir(), "index.db"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	tests := []struct {
+		name          string
+		full          bool
+		update        func()
+		result        SyncResult
+		backupPlans   []string
+		backups       []string
+		metadataCalls []string
+	}{
+		{
+			name:          "first sync is full",
+			result:        SyncResult{BackupPlans: 2, Backups: 2, Metadata: 2},
+			backupPlans:   []string{"bp-1", "bp-2"},
+			backups:       []string{"b-1", "b-2"},
+			metadataCalls: []string{"b-1", "b-2"},
+		},
+		{
+			name: "incremental sync lists new backups, older backupPlans as one was deleted and backups of backupPlan " +
+				"of in-progress backup",
+			update: func() {
+				target.backupPlans = target.backupPlans[:1]
+				target.backups = []interface{}{testObject(internal.BackupKind, "b-1", availableStatus, oldTimestamp),
+					testObject(internal.BackupKind, "b-2", availableStatus, oldTimestamp),
+					testObject(internal.BackupKind, "b-3", "InProgress", time.Now().UTC().Format(time.RFC3339))}
+			},
+			result:        SyncResult{BackupPlans: 1, Backups: 3, Metadata: 2, Removed: 1, Incremental: true},
+			backupPlans:   []string{"bp-1"},
+			backups:       []string{"b-1", "b-2", "b-3"},
+			metadataCalls: []string{"b-2", "b-3"},
+		},
+		{
+			name:        "incremental sync without changes lists backups created since last sync and refreshes their backupPlan",
+			result:      SyncResult{BackupPlans: 1, Backups: 1, Incremental: true},
+			backupPlans: []string{"bp-1"},
+			backups:     []string{"b-1", "b-2", "b-3"},
+		},
+		{
+			name: "incremental sync lists older backups as one was deleted",
+			update: func() {
+				target.backups = target.backups[1:]
+			},
+			result:      SyncResult{BackupPlans: 1, Backups: 2, Removed: 1, Incremental: true},
+			backupPlans: []string{"bp-1"},
+			backups:     []string{"b-2", "b-3"},
+		},
+		{
+			name: "full sync rebuilds index",
+			full: true,
+			update: func() {
+				target.backups = target.backups[1:]
+			},
+			result:        SyncResult{BackupPlans: 1, Backups: 1, Metadata: 1},
+			backupPlans:   []string{"bp-1"},
+			backups:       []string{"b-3"},
+			metadataCalls: []string{"b-3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.update != nil {
+				tt.update()
+			}
+			target.metadataCalls, target.listQueries = nil, nil
+			lastSync, err := idx.getSyncState(lastBackupSyncKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := auth.SyncIndex(idx, tt.full)
+			if err != nil {
+				t.Fatalf("SyncIndex() error = %v", err)
+			}
+			if *result != tt.result {
+				t.Errorf("SyncIndex() = %+v, want %+v", *result, tt.result)
+			}
+			if got := indexUIDs(t, idx, "backup_plans"); !reflect.DeepEqual(got, tt.backupPlans) {
+				t.Errorf("indexed backupPlans = %v, want %v", got, tt.backupPlans)
+			}
+			if got := indexUIDs(t, idx, "backups"); !reflect.DeepEqual(got, tt.backups) {
+				t.Errorf("indexed backups = %v, want %v", got, tt.backups)
+			}
+			metadata, err := idx.getUIDs("SELECT backup_uid FROM metadata")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(metadata) != len(tt.backups) {
+				t.Errorf("indexed metadata of %d backups, want %d", len(metadata), len(tt.backups))
+			}
+			sort.Strings(target.metadataCalls)
+			if !reflect.DeepEqual(target.metadataCalls, tt.metadataCalls) {
+				t.Errorf("metadata fetched for %v, want %v", target.metadataCalls, tt.metadataCalls)
+			}
+
+			if !tt.result.Incremental {
+				return
+			}
+			// incremental sync lists objects created since last sync and never lists all objects
+			lastSyncTime, err := time.Parse(time.RFC3339, lastSync)
+			if err != nil {
+				t.Fatal(err)
+			}
+			since := lastSyncTime.Add(-syncOverlap).Format(time.RFC3339)
+			sinceQueries := 0
+			for _, q := range target.listQueries {
+				if q.Get("creationStartTimestamp") == since {
+					sinceQueries++
+				}
+				if q.Get("creationStartTimestamp") == "" && q.Get("creationEndTimestamp") == "" &&
+					q.Get("backupPlanUID") == "" {
+					t.Errorf("incremental sync listed all objects with query %s", q.Encode())
+				}
+			}
+			if sinceQueries != 2 {
+				t.Errorf("incremental sync listed objects created since %s %d times, want 2 for backupPlans and backups",
+					since, sinceQueries)
+			}
+		})
+	}
+}
+
+func TestOpenIndexSchemaVersion(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "index.db")
+	idx, err := OpenIndex(indexPath, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = idx.db.Exec(`INSERT INTO backups (uid, name, kind, data) VALUES ('b-1', 'b', 'Backup', '{}')`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = idx.db.Exec(`INSERT INTO sync_state (key, value) VALUES (?, '0')`, schemaVersionKey); err != nil {
+		t.Fatal(err)
+	}
+	_ = idx.Close()
+
+	idx, err = OpenIndex(indexPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	if uids := indexUIDs(t, idx, "backups"); len(uids) != 0 {
+		t.Errorf("index of different schema version is not rebuilt, found backups %v", uids)
+	}
+	if version, vErr := idx.getSyncState(schemaVersionKey); vErr != nil || version != "" {
+		t.Errorf("schema version = %q, %v after rebuild, want empty", version, vErr)
+	}
+}
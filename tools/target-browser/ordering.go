@@ -0,0 +1,320 @@
+package targetbrowser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-querystring/query"
+	"github.com/thedevsaddam/gojsonq"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+const (
+	// autoPaginationPageSize is the page size used while fetching all pages of a list API for client-side ordering
+	autoPaginationPageSize = 100
+	orderingKeySeparator   = ","
+	descendingOrderPrefix  = "-"
+	sizeColumn             = "Size"
+)
+
+var (
+	// backupServerOrderingKeys & backupPlanServerOrderingKeys are the 'ordering' values supported by target-browser
+	// server. Any other value or multiple comma-separated values are ordered client-side.
+	backupServerOrderingKeys     = sets.NewString("name", "status", "backupTimestamp", "expirationTimestamp")
+	backupPlanServerOrderingKeys = sets.NewString("name", "backupPlanType", "successfulBackups", "backupTimestamp")
+
+	// backupOrderingAliases & backupPlanOrderingAliases map server ordering keys to displayed columns
+	backupOrderingAliases = map[string]string{
+		"backupTimestamp":     "Start Time",
+		"expirationTimestamp": "Expiration Time",
+	}
+	backupPlanOrderingAliases = map[string]string{
+		"backupPlanType":    "Type",
+		"successfulBackups": "Successful Backup",
+		"backupTimestamp":   "Successful Backup Timestamp",
+	}
+
+	orderingKeyNormalizer = strings.NewReplacer(" ", "", "-", "", "_", "")
+)
+
+// orderingKey is a single displayed column on which list results are ordered client-side
+type orderingKey struct {
+	column     string
+	descending bool
+}
+
+// paginatedResponse is the generic structure of backup & backupPlan list API response
+type paginatedResponse struct {
+	Metadata *ListMetadata `json:"metadata,omitempty"`
+	Results  []interface{} `json:"results"`
+}
+
+// isServerSideOrdering returns true if 'orderBy' is empty or a single key supported by target-browser server
+func isServerSideOrdering(orderBy string, serverKeys sets.String) bool {
+	return orderBy == "" || (!strings.Contains(orderBy, orderingKeySeparator) &&
+		serverKeys.Has(strings.TrimPrefix(orderBy, descendingOrderPrefix)))
+}
+
+// parseOrderBy splits comma-separated 'orderBy' into ordering keys and resolves each key to a displayed column
+// of 'selector'. Keys are matched case-insensitively ignoring spaces, hyphens and underscores, so 'start-time',
+// 'startTime' and 'Start Time' all resolve to same column. 'aliases' maps server ordering keys to displayed columns.
+func parseOrderBy(orderBy string, selector []string, aliases map[string]string) ([]orderingKey, error) {
+	columns := make(map[string]string)
+	for _, sel := range selector {
+		column := getSelectorColumn(sel)
+		columns[normalizeOrderingKey(column)] = column
+	}
+	for key, column := range aliases {
+		columns[normalizeOrderingKey(key)] = column
+	}
+
+	var keys []orderingKey
+	for _, key := range strings.Split(orderBy, orderingKeySeparator) {
+		key = strings.TrimSpace(key)
+		descending := strings.HasPrefix(key, descendingOrderPrefix)
+		key = strings.TrimPrefix(key, descendingOrderPrefix)
+		if key == "" {
+			return nil, fmt.Errorf("invalid ordering key in %q", orderBy)
+		}
+
+		column, ok := columns[normalizeOrderingKey(key)]
+		if !ok {
+			return nil, fmt.Errorf("unknown ordering key %q, ordering is supported on displayed columns", key)
+		}
+		keys = append(keys, orderingKey{column: column, descending: descending})
+	}
+
+	return keys, nil
+}
+
+// getOrderedResponse fetches results of 'apiPath' and orders them client-side on 'keys'. If 'uids' are not given,
+// all pages are fetched first so that ordering is global and then requested page is sliced from ordered results.
+// 'commonOptions' must point to CommonListOptions embedded in 'options'.
+func (auth *AuthInfo) getOrderedResponse(apiPath string, options interface{}, commonOptions *CommonListOptions,
+	uids, selector []string, keys []orderingKey) ([]byte, error) {
+
+	var (
+		response paginatedResponse
+		err      error
+	)
+
+	page, pageSize := commonOptions.Page, commonOptions.PageSize
+	if len(uids) > 0 {
+		response.Results, err = auth.getResultsByUIDs(apiPath, options, uids)
+	} else {
+		response.Results, err = auth.getAllPages(apiPath, options, commonOptions)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response.Results, err = sortResults(response.Results, selector, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(uids) == 0 {
+		response.Results, response.Metadata = paginate(response.Results, page, pageSize)
+	}
+
+	return json.Marshal(response)
+}
+
+// getResultsByUIDs returns results of 'apiPath' for given 'uids'
+func (auth *AuthInfo) getResultsByUIDs(apiPath string, options interface{}, uids []string) ([]interface{}, error) {
+	values, err := query.Values(options)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := auth.TriggerAPIs(values.Encode(), apiPath, uids)
+	if err != nil {
+		return nil, err
+	}
+
+	var response paginatedResponse
+	if err = json.Unmarshal(resp, &response); err != nil {
+		return nil, err
+	}
+	return response.Results, nil
+}
+
+// getAllPages calls 'apiPath' page by page till all results are retrieved
+func (auth *AuthInfo) getAllPages(apiPath string, options interface{}, commonOptions *CommonListOptions) ([]interface{}, error) {
+	var results []interface{}
+	commonOptions.PageSize = autoPaginationPageSize
+	for page := 1; ; page++ {
+		commonOptions.Page = page
+		values, err := query.Values(options)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := auth.TriggerAPI(apiPath, values.Encode())
+		if err != nil {
+			return nil, err
+		}
+
+		var response paginatedResponse
+		if err = json.Unmarshal(resp, &response); err != nil {
+			return nil, err
+		}
+		results = append(results, response.Results...)
+
+		if response.Metadata == nil || response.Metadata.Next == 0 || len(response.Results) == 0 ||
+			len(results) >= response.Metadata.Total {
+			return results, nil
+		}
+	}
+}
+
+// sortResults stable sorts 'results' on displayed column values generated by 'selector'
+func sortResults(results []interface{}, selector []string, keys []orderingKey) ([]interface{}, error) {
+	if len(results) < 2 || len(keys) == 0 {
+		return results, nil
+	}
+
+	var rows []map[string]interface{}
+	if err := selectColumns(results, selector, &rows); err != nil {
+		return nil, err
+	}
+	if len(rows) != len(results) {
+		return nil, fmt.Errorf("failed to extract ordering columns from %d results", len(results))
+	}
+
+	indexes := make([]int, len(results))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	sort.SliceStable(indexes, func(i, j int) bool {
+		for _, key := range keys {
+			c := compareColumnValues(key.column, rows[indexes[i]][key.column], rows[indexes[j]][key.column])
+			if c == 0 {
+				continue
+			}
+			if key.descending {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+
+	sorted := make([]interface{}, 0, len(results))
+	for _, index := range indexes {
+		sorted = append(sorted, results[index])
+	}
+	return sorted, nil
+}
+
+// selectColumns extracts displayed columns generated by 'selector' from each of 'results' and unmarshals them into 'out'
+func selectColumns(results []interface{}, selector []string, out interface{}) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	resultBytes, err := json.Marshal(paginatedResponse{Results: results})
+	if err != nil {
+		return err
+	}
+
+	var rowBytes bytes.Buffer
+	gojsonq.New().FromString(string(resultBytes)).From(internal.Results).Select(selector...).Writer(&rowBytes)
+	return json.Unmarshal(rowBytes.Bytes(), out)
+}
+
+// compareColumnValues compares two column values and returns -1, 0 or 1. Missing values are ordered first,
+// 'Size' column values are compared as quantities and RFC3339 timestamps are compared as time.
+func compareColumnValues(column string, a, b interface{}) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+
+	if x, ok := a.(float64); ok {
+		if y, ok := b.(float64); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	x, y := fmt.Sprint(a), fmt.Sprint(b)
+	if column == sizeColumn {
+		xq, xErr := resource.ParseQuantity(x)
+		yq, yErr := resource.ParseQuantity(y)
+		if xErr == nil && yErr == nil {
+			return xq.Cmp(yq)
+		}
+	}
+
+	xt, xErr := time.Parse(time.RFC3339, x)
+	yt, yErr := time.Parse(time.RFC3339, y)
+	if xErr == nil && yErr == nil {
+		switch {
+		case xt.Before(yt):
+			return -1
+		case xt.After(yt):
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	return strings.Compare(x, y)
+}
+
+// paginate returns 'page' of 'pageSize' from 'results' with pagination metadata
+func paginate(results []interface{}, page, pageSize int) ([]interface{}, *ListMetadata) {
+	metadata := &ListMetadata{Total: len(results)}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		return results, metadata
+	}
+
+	start := (page - 1) * pageSize
+	if start >= len(results) {
+		return []interface{}{}, metadata
+	}
+
+	end := start + pageSize
+	if end < len(results) {
+		metadata.Next = page + 1
+	} else {
+		end = len(results)
+	}
+
+	return results[start:end], metadata
+}
+
+// getSelectorColumn returns displayed column name of a gojsonq selector like 'metadata.name as Name'
+func getSelectorColumn(selector string) string {
+	parts := strings.SplitN(selector, " as ", 2)
+	return strings.TrimSpace(parts[len(parts)-1])
+}
+
+func normalizeOrderingKey(key string) string {
+	return strings.ToLower(orderingKeyNormalizer.Replace(key))
+}
@@ -0,0 +1,146 @@
+package targetbrowser
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseOrderBy(t *testing.T) {
+	tests := []struct {
+		name    string
+		orderBy string
+		want    []orderingKey
+		wantErr bool
+	}{
+		{name: "displayed column", orderBy: "Name", want: []orderingKey{{column: "Name"}}},
+		{name: "normalized keys", orderBy: "start-time, expiration_time,SIZE",
+			want: []orderingKey{{column: "Start Time"}, {column: "Expiration Time"}, {column: "Size"}}},
+		{name: "multiple keys with descending keys", orderBy: "-status,name,-startTime",
+			want: []orderingKey{{column: "Status", descending: true}, {column: "Name"}, {column: "Start Time", descending: true}}},
+		{name: "server ordering key alias", orderBy: "-backupTimestamp,expirationTimestamp",
+			want: []orderingKey{{column: "Start Time", descending: true}, {column: "Expiration Time"}}},
+		{name: "unknown column", orderBy: "name,owner", wantErr: true},
+		{name: "empty key", orderBy: "name,", wantErr: true},
+		{name: "descending prefix only", orderBy: "-", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseOrderBy(tt.orderBy, BackupSelector, backupOrderingAliases)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOrderBy() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseOrderBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortResults(t *testing.T) {
+	var results []interface{}
+	err := json.Unmarshal([]byte(`[
+		{"metadata": {"name": "b-1"}, "status": {"status": "Available", "size": "2Gi", "startTimestamp": "2021-05-02T10:00:00Z"}},
+		{"metadata": {"name": "b-2"}, "status": {"status": "Failed", "size": "512Mi", "startTimestamp": "2021-05-01T10:00:00Z"}},
+		{"metadata": {"name": "b-3"}, "status": {"status": "Available", "size": "900Mi", "startTimestamp": "2021-05-03T10:00:00Z"}},
+		{"metadata": {"name": "b-4"}, "status": {"status": "InProgress"}}
+	]`), &results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		orderBy string
+		want    []string
+	}{
+		{name: "size as quantity with missing first", orderBy: "size", want: []string{"b-4", "b-2", "b-3", "b-1"}},
+		{name: "descending start time", orderBy: "-startTime", want: []string{"b-3", "b-1", "b-2", "b-4"}},
+		{name: "status then descending size", orderBy: "status,-size", want: []string{"b-1", "b-3", "b-2", "b-4"}},
+		{name: "descending status then name", orderBy: "-status,name", want: []string{"b-4", "b-2", "b-1", "b-3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, kErr := parseOrderBy(tt.orderBy, BackupSelector, backupOrderingAliases)
+			if kErr != nil {
+				t.Fatal(kErr)
+			}
+			sorted, sErr := sortResults(results, BackupSelector, keys)
+			if sErr != nil {
+				t.Fatalf("sortResults() error = %v", sErr)
+			}
+			var names []string
+			for _, result := range sorted {
+				names = append(names, result.(map[string]interface{})["metadata"].(map[string]interface{})["name"].(string))
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("sortResults() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareColumnValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		column string
+		a, b   interface{}
+		want   int
+	}{
+		{name: "numbers", column: "Successful Backup", a: float64(2), b: float64(10), want: -1},
+		{name: "equal numbers", column: "Successful Backup", a: float64(3), b: float64(3)},
+		{name: "quantities", column: sizeColumn, a: "1Gi", b: "900Mi", want: 1},
+		{name: "equal quantities", column: sizeColumn, a: "1Gi", b: "1024Mi"},
+		{name: "quantity and string", column: sizeColumn, a: "1Gi", b: "unknown", want: -1},
+		{name: "quantities of other column compared as strings", column: "Name", a: "1Gi", b: "900Mi", want: -1},
+		{name: "times", column: "Start Time", a: "2021-05-01T10:00:00Z", b: "2021-05-01T09:00:00-02:00", want: -1},
+		{name: "equal times in other zone", column: "Start Time", a: "2021-05-01T10:00:00Z", b: "2021-05-01T12:00:00+02:00"},
+		{name: "time and string", column: "Start Time", a: "2021-05-01T10:00:00Z", b: "-", want: 1},
+		{name: "number and string", column: "Name", a: float64(1), b: "a", want: -1},
+		{name: "missing first", column: "Start Time", b: "2021-05-01T10:00:00Z", want: -1},
+		{name: "missing last", column: sizeColumn, a: "1Gi", want: 1},
+		{name: "both missing", column: sizeColumn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareColumnValues(tt.column, tt.a, tt.b); got != tt.want {
+				t.Errorf("compareColumnValues(%v, %v) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	results := []interface{}{"a", "b", "c", "d", "e"}
+
+	tests := []struct {
+		name     string
+		page     int
+		pageSize int
+		want     []interface{}
+		next     int
+	}{
+		{name: "first page", page: 1, pageSize: 2, want: []interface{}{"a", "b"}, next: 2},
+		{name: "middle page", page: 2, pageSize: 2, want: []interface{}{"c", "d"}, next: 3},
+		{name: "last partial page", page: 3, pageSize: 2, want: []interface{}{"e"}},
+		{name: "exact last page", page: 1, pageSize: 5, want: results},
+		{name: "page past the end", page: 4, pageSize: 2, want: []interface{}{}},
+		{name: "page 0 is first page", page: 0, pageSize: 3, want: []interface{}{"a", "b", "c"}, next: 2},
+		{name: "pageSize 0 returns all", page: 2, pageSize: 0, want: results},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, metadata := paginate(results, tt.page, tt.pageSize)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("paginate() = %v, want %v", got, tt.want)
+			}
+			if metadata.Total != len(results) || metadata.Next != tt.next {
+				t.Errorf("paginate() metadata = %+v, want total %d and next %d", metadata, len(results), tt.next)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package targetbrowser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// backupLister lists backups & backupPlans either from target-browser APIs or from local index
+type backupLister interface {
+	ListAllBackups(options *BackupListOptions) ([]Backup, error)
+	ListAllBackupPlans(options *BackupPlanListOptions) ([]BackupPlan, error)
+}
+
+// ResolveBackupPlanUID returns UID of backupPlan with given 'name'. If 'tvkInstanceUID' is provided, only backupPlans
+// of that TVK instance are considered. Error is returned if no backupPlan or more than one backupPlan matches 'name'
+// which can happen when same backupPlan name exists for multiple TVK instances.
+func (auth *AuthInfo) ResolveBackupPlanUID(name, tvkInstanceUID string) (string, error) {
+	return resolveBackupPlanUID(auth, name, tvkInstanceUID)
+}
+
+// ResolveBackup returns backup with given 'name'. If 'backupPlanUID' or 'tvkInstanceUID' is provided, only backups
+// of that backupPlan or TVK instance are considered. Error is returned if no backup or more than one backup matches
+// 'name'.
+func (auth *AuthInfo) ResolveBackup(name, backupPlanUID, tvkInstanceUID string) (*Backup, error) {
+	return resolveBackup(auth, name, backupPlanUID, tvkInstanceUID)
+}
+
+func resolveBackupPlanUID(lister backupLister, name, tvkInstanceUID string) (string, error) {
+	backupPlans, err := lister.ListAllBackupPlans(&BackupPlanListOptions{
+		CommonListOptions: CommonListOptions{TvkInstanceUID: tvkInstanceUID},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var matched []BackupPlan
+	for i := range backupPlans {
+		if backupPlans[i].Name == name {
+			matched = append(matched, backupPlans[i])
+		}
+	}
+
+	switch len(matched) {
+	case 0:
+		return "", fmt.Errorf("backupPlan with name %q not found on target", name)
+	case 1:
+		return matched[0].UID, nil
+	}
+
+	var candidates []string
+	for i := range matched {
+		candidates = append(candidates, fmt.Sprintf("%s (TVK Instance: %s)", matched[i].UID, matched[i].TvkInstanceID))
+	}
+	return "", fmt.Errorf("backupPlan name %q is ambiguous, found %d backupPlans [%s]. Use backupPlan UID or TVK"+
+		" instance UID to select one", name, len(matched), strings.Join(candidates, ", "))
+}
+
+func resolveBackup(lister backupLister, name, backupPlanUID, tvkInstanceUID string) (*Backup, error) {
+	backups, err := lister.ListAllBackups(&BackupListOptions{
+		BackupPlanUID:     backupPlanUID,
+		CommonListOptions: CommonListOptions{TvkInstanceUID: tvkInstanceUID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []Backup
+	for i := range backups {
+		if backups[i].Name == name {
+			matched = append(matched, backups[i])
+		}
+	}
+
+	switch len(matched) {
+	case 0:
+		return nil, fmt.Errorf("backup with name %q not found on target", name)
+	case 1:
+		return &matched[0], nil
+	}
+
+	var candidates []string
+	for i := range matched {
+		candidates = append(candidates, fmt.Sprintf("%s (BackupPlan UID: %s, TVK Instance: %s)", matched[i].UID,
+			matched[i].BackupPlanUID, matched[i].TvkInstanceID))
+	}
+	return nil, fmt.Errorf("backup name %q is ambiguous, found %d backups [%s]. Use backup UID, backupPlan or TVK"+
+		" instance UID to select one", name, len(matched), strings.Join(candidates, ", "))
+}
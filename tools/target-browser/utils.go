@@ -52,6 +52,39 @@ func (targetBrowserConfig *Config) validateTarget(ctx context.Context, cl client
 	return target, nil
 }
 
+// GetBrowsableTargetNames returns names of targets present in target-namespace for which browsing is enabled
+func (targetBrowserConfig *Config) GetBrowsableTargetNames(ctx context.Context) ([]string, error) {
+	kubeConfig, err := internal.NewConfigFromCommandline(targetBrowserConfig.KubeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	acc, err := internal.NewAccessor(kubeConfig, targetBrowserConfig.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	targetList := &unstructured.UnstructuredList{}
+	targetList.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   internal.TriliovaultGroup,
+		Version: internal.V1Version,
+		Kind:    internal.TargetKind + "List",
+	})
+	if err = acc.GetRuntimeClient().List(ctx, targetList, client.InNamespace(targetBrowserConfig.TargetNamespace)); err != nil {
+		return nil, err
+	}
+
+	var targetNames []string
+	for i := range targetList.Items {
+		browsingEnabled, _, nErr := unstructured.NestedBool(targetList.Items[i].Object, "status", "browsingEnabled")
+		if nErr == nil && browsingEnabled {
+			targetNames = append(targetNames, targetList.Items[i].GetName())
+		}
+	}
+
+	return targetNames, nil
+}
+
 // getTvkHostAndTargetBrowserAPIPath gets tvkHost name and targetBrowserAPIPath from target-browser's ingress
 func getTvkHostAndTargetBrowserAPIPath(ctx context.Context, cl client.Client, target *unstructured.Unstructured,
 	isIngressNetworkingV1Resource bool) (tvkHost, targetBrowserPath string, err error) {
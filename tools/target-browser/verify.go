@@ -0,0 +1,428 @@
+package targetbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-querystring/query"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/printers"
+	"sigs.k8s.io/yaml"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+const (
+	availableStatus = "Available"
+
+	checkStatus           = "status"
+	checkExpiration       = "expiration"
+	checkMetadata         = "metadata"
+	checkResourceMetadata = "resource-metadata"
+	checkTrilioResources  = "trilio-resources"
+
+	verificationPassed = "PASS"
+	verificationFailed = "FAIL"
+)
+
+var (
+	// trilioResourceKinds are the kinds of trilio-resources verified for each backup
+	trilioResourceKinds = []string{internal.TargetKind, "Policy", "Hook", "Secret", internal.BackupPlanKind,
+		internal.ClusterBackupPlanKind}
+)
+
+// VerifyOptions selects backups to be verified. If 'BackupUIDs' are not given, all backups of 'BackupPlanUID'
+// are verified.
+type VerifyOptions struct {
+	BackupPlanUID  string
+	BackupUIDs     []string
+	TvkInstanceUID string
+}
+
+// VerificationCheck is result of a single check performed on backup
+type VerificationCheck struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Details string `json:"details,omitempty"`
+}
+
+// BackupVerification contains results of all checks performed on a backup
+type BackupVerification struct {
+	BackupName    string              `json:"backupName"`
+	BackupUID     string              `json:"backupUID"`
+	BackupPlanUID string              `json:"backupPlanUID"`
+	Passed        bool                `json:"passed"`
+	Checks        []VerificationCheck `json:"checks"`
+}
+
+// VerificationReport contains verification results of all verified backups
+type VerificationReport struct {
+	Passed  bool                 `json:"passed"`
+	Total   int                  `json:"total"`
+	Failed  int                  `json:"failed"`
+	Backups []BackupVerification `json:"backups"`
+}
+
+// groupVersionKindObjects is the structure of resources listed in backup metadata & trilio-resources
+type groupVersionKindObjects struct {
+	GroupVersionKind struct {
+		Group   string `json:"group"`
+		Version string `json:"version"`
+		Kind    string `json:"kind"`
+	} `json:"groupVersionKind"`
+	Objects []string `json:"objects"`
+}
+
+// VerifyBackups checks that metadata & every resource-metadata entry of backups is retrievable, backup status
+// is Available, expiration is in future and trilio-resources referenced by backupPlan are available on target.
+func (auth *AuthInfo) VerifyBackups(options *VerifyOptions) (*VerificationReport, error) {
+	backups, err := auth.getBackupsToVerify(options)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) == 0 {
+		return nil, fmt.Errorf("no backups found to verify")
+	}
+
+	report := &VerificationReport{Passed: true, Total: len(backups)}
+	for i := range backups {
+		verification := auth.verifyBackup(&backups[i])
+		if !verification.Passed {
+			report.Passed = false
+			report.Failed++
+		}
+		report.Backups = append(report.Backups, verification)
+	}
+
+	return report, nil
+}
+
+func (auth *AuthInfo) getBackupsToVerify(options *VerifyOptions) ([]Backup, error) {
+	if len(options.BackupUIDs) == 0 {
+		return auth.ListAllBackups(&BackupListOptions{
+			BackupPlanUID:     options.BackupPlanUID,
+			CommonListOptions: CommonListOptions{TvkInstanceUID: options.TvkInstanceUID},
+		})
+	}
+
+	results, err := auth.getResultsByUIDs(internal.BackupAPIPath, &BackupListOptions{}, options.BackupUIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []Backup
+	if err = selectColumns(results, BackupSelector, &backups); err != nil {
+		return nil, err
+	}
+	return backups, nil
+}
+
+func (auth *AuthInfo) verifyBackup(backup *Backup) BackupVerification {
+	verification := BackupVerification{
+		BackupName:    backup.Name,
+		BackupUID:     backup.UID,
+		BackupPlanUID: backup.BackupPlanUID,
+		Passed:        true,
+	}
+
+	addCheck := func(name string, err error, details string) {
+		check := VerificationCheck{Name: name, Passed: err == nil, Details: details}
+		if err != nil {
+			check.Details = err.Error()
+			verification.Passed = false
+		}
+		verification.Checks = append(verification.Checks, check)
+	}
+
+	addCheck(checkStatus, verifyBackupStatus(backup), backup.Status)
+	addCheck(checkExpiration, verifyBackupExpiration(backup), backup.ExpirationTime)
+
+	resources, err := auth.getMetadataResources(backup)
+	addCheck(checkMetadata, err, fmt.Sprintf("%d resources of %d kinds", countObjects(resources), len(resources)))
+	if err == nil {
+		details, rErr := auth.verifyResourceMetadata(backup, resources)
+		addCheck(checkResourceMetadata, rErr, details)
+	}
+
+	details, err := auth.verifyTrilioResources(backup)
+	addCheck(checkTrilioResources, err, details)
+
+	return verification
+}
+
+func verifyBackupStatus(backup *Backup) error {
+	if backup.Status != availableStatus {
+		return fmt.Errorf("backup status is %q, expected %q", backup.Status, availableStatus)
+	}
+	return nil
+}
+
+func verifyBackupExpiration(backup *Backup) error {
+	if backup.ExpirationTime == "" {
+		return fmt.Errorf("backup expiration timestamp is not set")
+	}
+	expiration, err := time.Parse(time.RFC3339, backup.ExpirationTime)
+	if err != nil {
+		return fmt.Errorf("invalid backup expiration timestamp %q - %s", backup.ExpirationTime, err.Error())
+	}
+	if !expiration.After(time.Now()) {
+		return fmt.Errorf("backup expired at %s", backup.ExpirationTime)
+	}
+	return nil
+}
+
+// getMetadataResources retrieves metadata of backup and returns all resources listed in it
+func (auth *AuthInfo) getMetadataResources(backup *Backup) ([]groupVersionKindObjects, error) {
+	values, err := query.Values(MetadataListOptions{BackupUID: backup.UID, BackupPlanUID: backup.BackupPlanUID})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := auth.TriggerAPI(internal.MetadataAPIPath, values.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata interface{}
+	if err = json.Unmarshal(resp, &metadata); err != nil {
+		return nil, fmt.Errorf("invalid metadata - %s", err.Error())
+	}
+	return collectGroupVersionKindObjects(metadata), nil
+}
+
+// countObjects returns number of objects of all kinds in 'resources'
+func countObjects(resources []groupVersionKindObjects) int {
+	count := 0
+	for i := range resources {
+		count += len(resources[i].Objects)
+	}
+	return count
+}
+
+// verifyResourceMetadata retrieves resource-metadata of each resource listed in backup metadata
+func (auth *AuthInfo) verifyResourceMetadata(backup *Backup, resources []groupVersionKindObjects) (string, error) {
+	var failed []string
+	for i := range resources {
+		gvk := resources[i].GroupVersionKind
+		for _, object := range resources[i].Objects {
+			values, err := query.Values(ResourceMetadataListOptions{
+				BackupUID:     backup.UID,
+				BackupPlanUID: backup.BackupPlanUID,
+				Group:         gvk.Group,
+				Version:       gvk.Version,
+				Kind:          gvk.Kind,
+				Name:          object,
+			})
+			if err != nil {
+				return "", err
+			}
+			if _, err = auth.TriggerAPI(internal.ResourceMetadataAPIPath, values.Encode()); err != nil {
+				failed = append(failed, fmt.Sprintf("%s/%s", gvk.Kind, object))
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return "", fmt.Errorf("%d of %d resource-metadata entries not retrievable [%s]", len(failed), countObjects(resources),
+			strings.Join(failed, ", "))
+	}
+	return fmt.Sprintf("%d entries", countObjects(resources)), nil
+}
+
+// verifyTrilioResources checks that trilio-resources of backup contain a Target and that Target, Policy,
+// Hook and Secret referenced by backupPlan of backup are available
+func (auth *AuthInfo) verifyTrilioResources(backup *Backup) (string, error) {
+	values, err := query.Values(TrilioResourcesListOptions{
+		BackupPlanUID: backup.BackupPlanUID,
+		Kinds:         trilioResourceKinds,
+	})
+	if err != nil {
+		return "", err
+	}
+	resp, err := auth.TriggerAPI(getTrilioResourcesAPIPath(backup.UID), values.Encode())
+	if err != nil {
+		return "", err
+	}
+
+	var trilioResources interface{}
+	if err = json.Unmarshal(resp, &trilioResources); err != nil {
+		return "", fmt.Errorf("invalid trilio-resources - %s", err.Error())
+	}
+
+	available := make(map[string]sets.String)
+	for _, resource := range collectGroupVersionKindObjects(trilioResources) {
+		if _, ok := available[resource.GroupVersionKind.Kind]; !ok {
+			available[resource.GroupVersionKind.Kind] = sets.NewString()
+		}
+		available[resource.GroupVersionKind.Kind].Insert(resource.Objects...)
+	}
+	for _, obj := range collectObjectsWithSpec(trilioResources) {
+		kind, name := nestedString(obj, "kind"), nestedString(obj, "metadata", "name")
+		if kind == "" || name == "" {
+			continue
+		}
+		if _, ok := available[kind]; !ok {
+			available[kind] = sets.NewString()
+		}
+		available[kind].Insert(name)
+	}
+
+	if available[internal.TargetKind].Len() == 0 {
+		return "", fmt.Errorf("%s not found in trilio-resources", internal.TargetKind)
+	}
+
+	var missing []string
+	for _, obj := range collectObjectsWithSpec(trilioResources) {
+		kind := nestedString(obj, "kind")
+		if kind != internal.BackupPlanKind && kind != internal.ClusterBackupPlanKind {
+			continue
+		}
+		for _, ref := range getBackupPlanReferences(obj) {
+			if !available[ref.kind].Has(ref.name) {
+				missing = append(missing, fmt.Sprintf("%s/%s", ref.kind, ref.name))
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("referenced trilio-resources not found [%s]", strings.Join(missing, ", "))
+	}
+
+	var found []string
+	for _, kind := range trilioResourceKinds {
+		if available[kind].Len() > 0 {
+			found = append(found, fmt.Sprintf("%s: %d", kind, available[kind].Len()))
+		}
+	}
+	return strings.Join(found, ", "), nil
+}
+
+type trilioResourceReference struct {
+	kind, name string
+}
+
+// getBackupPlanReferences returns Target, Policy, Hook and Secret referenced by spec of backupPlan object
+func getBackupPlanReferences(backupPlan map[string]interface{}) []trilioResourceReference {
+	var refs []trilioResourceReference
+	addRef := func(kind, name string) {
+		if name != "" {
+			refs = append(refs, trilioResourceReference{kind: kind, name: name})
+		}
+	}
+
+	addRef(internal.TargetKind, nestedString(backupPlan, "spec", "backupConfig", "target", "name"))
+	addRef("Policy", nestedString(backupPlan, "spec", "backupConfig", "retentionPolicy", "name"))
+	addRef("Policy", nestedString(backupPlan, "spec", "backupConfig", "schedulePolicy", "fullBackupPolicy", "name"))
+	addRef("Policy", nestedString(backupPlan, "spec", "backupConfig", "schedulePolicy", "incrementalBackupPolicy", "name"))
+	addRef("Secret", nestedString(backupPlan, "spec", "encryption", "encryptionSecret", "name"))
+
+	spec, _ := backupPlan["spec"].(map[string]interface{})
+	hookConfig, _ := spec["hookConfig"].(map[string]interface{})
+	hooks, _ := hookConfig["hooks"].([]interface{})
+	for _, hook := range hooks {
+		if hookObj, ok := hook.(map[string]interface{}); ok {
+			addRef("Hook", nestedString(hookObj, "hook", "name"))
+		}
+	}
+	return refs
+}
+
+// collectGroupVersionKindObjects walks 'data' and returns all entries having 'groupVersionKind' and 'objects'
+func collectGroupVersionKindObjects(data interface{}) []groupVersionKindObjects {
+	var resources []groupVersionKindObjects
+	walkJSON(data, func(obj map[string]interface{}) {
+		if _, ok := obj["groupVersionKind"]; !ok {
+			return
+		}
+		if _, ok := obj["objects"]; !ok {
+			return
+		}
+		b, err := json.Marshal(obj)
+		if err != nil {
+			return
+		}
+		var resource groupVersionKindObjects
+		if json.Unmarshal(b, &resource) == nil {
+			resources = append(resources, resource)
+		}
+	})
+	return resources
+}
+
+// collectObjectsWithSpec walks 'data' and returns all kubernetes objects having 'kind', 'metadata' and 'spec'
+func collectObjectsWithSpec(data interface{}) []map[string]interface{} {
+	var objects []map[string]interface{}
+	walkJSON(data, func(obj map[string]interface{}) {
+		_, hasMetadata := obj["metadata"].(map[string]interface{})
+		_, hasSpec := obj["spec"].(map[string]interface{})
+		if hasMetadata && hasSpec && nestedString(obj, "kind") != "" {
+			objects = append(objects, obj)
+		}
+	})
+	return objects
+}
+
+func walkJSON(data interface{}, visit func(map[string]interface{})) {
+	switch val := data.(type) {
+	case map[string]interface{}:
+		visit(val)
+		for _, v := range val {
+			walkJSON(v, visit)
+		}
+	case []interface{}:
+		for _, v := range val {
+			walkJSON(v, visit)
+		}
+	}
+}
+
+// PrintVerificationReport prints 'report' in 'outputFormat'. Table with a row per check is printed by default.
+func PrintVerificationReport(report *VerificationReport, outputFormat string) error {
+	switch outputFormat {
+	case internal.FormatJSON:
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	case internal.FormatYAML:
+		data, err := yaml.Marshal(report)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "BACKUP", Type: "string"},
+			{Name: "UID", Type: "string"},
+			{Name: "CHECK", Type: "string"},
+			{Name: "RESULT", Type: "string"},
+			{Name: "DETAILS", Type: "string"},
+		},
+	}
+	for i := range report.Backups {
+		backup := report.Backups[i]
+		for _, check := range backup.Checks {
+			result := verificationPassed
+			if !check.Passed {
+				result = verificationFailed
+			}
+			table.Rows = append(table.Rows, metav1.TableRow{
+				Cells: []interface{}{backup.BackupName, backup.BackupUID, check.Name, result, check.Details},
+			})
+		}
+	}
+
+	printer := printers.NewTablePrinter(printers.PrintOptions{})
+	if err := printer.PrintObj(table, os.Stdout); err != nil {
+		return err
+	}
+	fmt.Printf("\n%d of %d backups passed verification\n", report.Total-report.Failed, report.Total)
+	return nil
+}
@@ -0,0 +1,227 @@
+package targetbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trilioData/tvk-plugins/internal"
+)
+
+func TestCountObjects(t *testing.T) {
+	kind := func(objects ...string) groupVersionKindObjects {
+		return groupVersionKindObjects{Objects: objects}
+	}
+
+	tests := []struct {
+		name      string
+		resources []groupVersionKindObjects
+		want      int
+	}{
+		{name: "no resources", want: 0},
+		{name: "kind without objects", resources: []groupVersionKindObjects{kind()}, want: 0},
+		{name: "objects of multiple kinds", resources: []groupVersionKindObjects{kind("cm-1", "cm-2"), kind("pod-1")},
+			want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countObjects(tt.resources); got != tt.want {
+				t.Errorf("countObjects() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyBackupStatus(t *testing.T) {
+	tests := []struct {
+		status  string
+		wantErr bool
+	}{
+		{status: availableStatus},
+		{status: "Failed", wantErr: true},
+		{status: "InProgress", wantErr: true},
+		{status: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if err := verifyBackupStatus(&Backup{Status: tt.status}); (err != nil) != tt.wantErr {
+				t.Errorf("verifyBackupStatus() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyBackupExpiration(t *testing.T) {
+	tests := []struct {
+		name       string
+		expiration string
+		wantErr    bool
+	}{
+		{name: "expiration in future", expiration: time.Now().Add(time.Hour).Format(time.RFC3339)},
+		{name: "expired", expiration: time.Now().Add(-time.Hour).Format(time.RFC3339), wantErr: true},
+		{name: "expiration not set", wantErr: true},
+		{name: "invalid expiration", expiration: "tomorrow", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verifyBackupExpiration(&Backup{ExpirationTime: tt.expiration}); (err != nil) != tt.wantErr {
+				t.Errorf("verifyBackupExpiration() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetBackupPlanReferences(t *testing.T) {
+	tests := []struct {
+		name       string
+		backupPlan string
+		want       []trilioResourceReference
+	}{
+		{
+			name: "all references",
+			backupPlan: `{"kind": "BackupPlan", "spec": {
+				"backupConfig": {"target": {"name": "target"}, "retentionPolicy": {"name": "retention"},
+					"schedulePolicy": {"fullBackupPolicy": {"name": "daily"}, "incrementalBackupPolicy": {"name": "hourly"}}},
+				"encryption": {"encryptionSecret": {"name": "encryption"}},
+				"hookConfig": {"hooks": [{"hook": {"name": "pre"}}, {"hook": {"name": "post"}}]}}}`,
+			want: []trilioResourceReference{{internal.TargetKind, "target"}, {"Policy", "retention"}, {"Policy", "daily"},
+				{"Policy", "hourly"}, {"Secret", "encryption"}, {"Hook", "pre"}, {"Hook", "post"}},
+		},
+		{
+			name:       "target only",
+			backupPlan: `{"kind": "ClusterBackupPlan", "spec": {"backupConfig": {"target": {"name": "target"}}}}`,
+			want:       []trilioResourceReference{{internal.TargetKind, "target"}},
+		},
+		{name: "no spec", backupPlan: `{"kind": "BackupPlan"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var backupPlan map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.backupPlan), &backupPlan); err != nil {
+				t.Fatal(err)
+			}
+			if got := getBackupPlanReferences(backupPlan); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getBackupPlanReferences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// verifyTarget serves backups, metadata, resource-metadata and trilio-resources of target-browser API from fixtures
+type verifyTarget struct {
+	backups map[string]string
+	// trilioResources are keyed by backup UID, defaultTrilioResources is served for other backups
+	trilioResources map[string]string
+}
+
+const (
+	verifyMetadata = `{"custom": [{"groupVersionKind": {"group": "", "version": "v1", "kind": "ConfigMap"},
+		"objects": ["config", "missing"]}]}`
+	defaultTrilioResources = `{"results": [
+		{"groupVersionKind": {"group": "triliovault.trilio.io", "version": "v1", "kind": "Target"}, "objects": ["target"]},
+		{"groupVersionKind": {"group": "triliovault.trilio.io", "version": "v1", "kind": "Policy"}, "objects": ["retention"]},
+		{"backupPlan": {"kind": "BackupPlan", "metadata": {"name": "bp"},
+			"spec": {"backupConfig": {"target": {"name": "target"}, "retentionPolicy": {"name": "retention"}}}}}]}`
+)
+
+func (v *verifyTarget) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	apiPath, q := strings.TrimPrefix(r.URL.Path, "/"), r.URL.Query()
+	parts := strings.Split(apiPath, "/")
+	switch {
+	case apiPath == internal.MetadataAPIPath:
+		_, _ = w.Write([]byte(verifyMetadata))
+	case apiPath == internal.ResourceMetadataAPIPath && q.Get("name") != "missing":
+		_, _ = w.Write([]byte(`{}`))
+	case len(parts) == 3 && parts[0] == internal.BackupAPIPath && parts[2] == internal.TrilioResourcesAPIPath:
+		resources, ok := v.trilioResources[parts[1]]
+		if !ok {
+			resources = defaultTrilioResources
+		}
+		_, _ = w.Write([]byte(resources))
+	case len(parts) == 2 && parts[0] == internal.BackupAPIPath && v.backups[parts[1]] != "":
+		_, _ = w.Write([]byte(v.backups[parts[1]]))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestVerifyBackups(t *testing.T) {
+	backup := func(uid, status string, expiration time.Time) string {
+		return fmt.Sprintf(`{"kind": "Backup", "metadata": {"name": "%s-name", "uid": "%s"},
+			"spec": {"backupPlan": {"uid": "bp-1"}},
+			"status": {"status": "%s", "expirationTimestamp": "%s"}}`, uid, uid, status, expiration.Format(time.RFC3339))
+	}
+	future, past := time.Now().Add(time.Hour), time.Now().Add(-time.Hour)
+	target := &verifyTarget{
+		backups: map[string]string{
+			"available":         backup("available", availableStatus, future),
+			"failed":            backup("failed", "Failed", future),
+			"expired":           backup("expired", availableStatus, past),
+			"missing-reference": backup("missing-reference", availableStatus, future),
+			"missing-target":    backup("missing-target", availableStatus, future),
+		},
+		trilioResources: map[string]string{
+			"missing-reference": strings.Replace(defaultTrilioResources, `"retentionPolicy": {"name": "retention"}`,
+				`"retentionPolicy": {"name": "retention"}, "schedulePolicy": {"fullBackupPolicy": {"name": "daily"}}`, 1),
+			"missing-target": strings.Replace(defaultTrilioResources, `"kind": "Target"}, "objects": ["target"]`,
+				`"kind": "Target"}, "objects": []`, 1),
+		},
+	}
+	server := httptest.NewServer(target)
+	defer server.Close()
+	auth := &AuthInfo{Client: server.Client(), TvkHost: server.URL}
+
+	tests := []struct {
+		uid string
+		// failedChecks are checks which fail, all other checks pass
+		failedChecks []string
+		details      string
+	}{
+		{uid: "available", failedChecks: []string{checkResourceMetadata},
+			details: "1 of 2 resource-metadata entries not retrievable [ConfigMap/missing]"},
+		{uid: "failed", failedChecks: []string{checkStatus, checkResourceMetadata}},
+		{uid: "expired", failedChecks: []string{checkExpiration, checkResourceMetadata}},
+		{uid: "missing-reference", failedChecks: []string{checkResourceMetadata, checkTrilioResources},
+			details: "referenced trilio-resources not found [Policy/daily]"},
+		{uid: "missing-target", failedChecks: []string{checkResourceMetadata, checkTrilioResources},
+			details: "Target not found in trilio-resources"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uid, func(t *testing.T) {
+			report, err := auth.VerifyBackups(&VerifyOptions{BackupUIDs: []string{tt.uid}})
+			if err != nil {
+				t.Fatalf("VerifyBackups() error = %v", err)
+			}
+			if report.Passed || report.Total != 1 || report.Failed != 1 || len(report.Backups) != 1 {
+				t.Fatalf("VerifyBackups() = %+v, want single failed backup", report)
+			}
+
+			verification := report.Backups[0]
+			var failed, details []string
+			for _, check := range verification.Checks {
+				if !check.Passed {
+					failed = append(failed, check.Name)
+					details = append(details, check.Details)
+				}
+			}
+			if verification.BackupUID != tt.uid || len(verification.Checks) != 5 ||
+				!reflect.DeepEqual(failed, tt.failedChecks) {
+				t.Errorf("backup %s failed checks %v of %d checks, want %v of 5 checks", verification.BackupUID, failed,
+					len(verification.Checks), tt.failedChecks)
+			}
+			if tt.details != "" && details[len(details)-1] != tt.details {
+				t.Errorf("details of check %s = %q, want %q", failed[len(failed)-1], details[len(details)-1], tt.details)
+			}
+		})
+	}
+}